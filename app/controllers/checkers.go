@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/checker"
+	"codestandoff/backend/internal/database"
+)
+
+// QuestionChecker returns how a question's outputs are checked
+func (c *pcdGraphQLControllerImpl) QuestionChecker(ctx context.Context, question *model.Question) (*model.Checker, error) {
+	questionID, err := strconv.Atoi(question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	data, err := database.GetQuestionChecker(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checker: %w", err)
+	}
+	spec, err := checker.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return checkerToModel(spec), nil
+}
+
+// SetChecker sets how a question's outputs are checked. Checker programs
+// must be written in an enabled language.
+func (c *pcdGraphQLControllerImpl) SetChecker(ctx context.Context, questionID string, input model.CheckerInput) (*model.Checker, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	spec := &checker.Spec{Kind: checker.Kind(strings.ToLower(string(input.Kind)))}
+	if input.Tolerance != nil {
+		spec.Tolerance = *input.Tolerance
+	}
+	if input.Language != nil {
+		spec.Language = strings.ToLower(strings.TrimSpace(*input.Language))
+	}
+	if input.Source != nil {
+		spec.Source = *input.Source
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if spec.Kind == checker.Program {
+		if l, ok := c.deps.Languages.Language(spec.Language); !ok || !l.Enabled {
+			return nil, fmt.Errorf("unsupported language %q", spec.Language)
+		}
+	}
+
+	// Exact comparison is the default and is stored as no checker
+	var data []byte
+	if spec.Kind != checker.Exact {
+		data, err = json.Marshal(spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := database.SetQuestionChecker(c.deps.DB, qid, data); err != nil {
+		return nil, fmt.Errorf("failed to set checker: %w", err)
+	}
+
+	return checkerToModel(spec), nil
+}
+
+func checkerToModel(s *checker.Spec) *model.Checker {
+	m := &model.Checker{Kind: model.CheckerKind(strings.ToUpper(string(s.Kind)))}
+	switch s.Kind {
+	case checker.Float:
+		tolerance := s.Tolerance
+		if tolerance == 0 {
+			tolerance = checker.DefaultTolerance
+		}
+		m.Tolerance = &tolerance
+	case checker.Program:
+		m.Language = &s.Language
+	}
+	return m
+}
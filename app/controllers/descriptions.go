@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/markdown"
+)
+
+// QuestionDescriptionHTML returns the description of a question rendered to sanitized HTML
+func (c *pcdGraphQLControllerImpl) QuestionDescriptionHTML(ctx context.Context, question *model.Question) (string, error) {
+	return c.currentDescriptionHTML(question.ID, question.Description)
+}
+
+// ProblemDescriptionHTML returns the description of a problem rendered to sanitized HTML
+func (c *pcdGraphQLControllerImpl) ProblemDescriptionHTML(ctx context.Context, problem *model.Problem) (string, error) {
+	return c.currentDescriptionHTML(problem.ID, problem.Description)
+}
+
+// ProblemRevisionDescriptionHTML returns the description of a revision rendered to sanitized HTML
+func (c *pcdGraphQLControllerImpl) ProblemRevisionDescriptionHTML(ctx context.Context, revision *model.ProblemRevision) (string, error) {
+	revisionID, err := strconv.ParseInt(revision.ID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid revision ID: %w", err)
+	}
+
+	d, err := database.GetRenderedDescription(c.deps.DB, revisionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return markdown.Render(revision.Description), nil
+		}
+		return "", fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return c.renderedDescription(d)
+}
+
+// currentDescriptionHTML renders the description of a question's current
+// revision. A question without revisions has its description rendered as is.
+func (c *pcdGraphQLControllerImpl) currentDescriptionHTML(questionID, description string) (string, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return "", fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	d, err := database.GetCurrentRenderedDescription(c.deps.DB, qid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return markdown.Render(description), nil
+		}
+		return "", fmt.Errorf("failed to get current revision: %w", err)
+	}
+
+	return c.renderedDescription(d)
+}
+
+// renderedDescription returns the cached HTML of a revision's description,
+// rendering and caching it first if it is missing or from an older renderer
+func (c *pcdGraphQLControllerImpl) renderedDescription(d *database.RenderedDescription) (string, error) {
+	if d.HTML.Valid && d.HTMLVersion.Valid && d.HTMLVersion.Int64 == markdown.Version {
+		return d.HTML.String, nil
+	}
+
+	html := markdown.Render(d.Description)
+	if err := database.SetDescriptionHTML(c.deps.DB, d.RevisionID, html, markdown.Version); err != nil {
+		return "", fmt.Errorf("failed to cache rendered description: %w", err)
+	}
+	return html, nil
+}
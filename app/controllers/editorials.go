@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+)
+
+// Upper bounds for author-supplied editorial content
+const (
+	maxEditorialBytes         = 256 * 1024
+	maxComplexityNoteLength   = 200
+	maxReferenceSolutionBytes = 64 * 1024
+)
+
+// QuestionEditorial returns the editorial of a question as the current user
+// sees it. It stays locked until the user solves the question or gives up on
+// it; the question's author, reviewers and admins can always read it.
+func (c *pcdGraphQLControllerImpl) QuestionEditorial(ctx context.Context, question *model.Question) (*model.QuestionEditorial, error) {
+	questionID, err := strconv.Atoi(question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	result := &model.QuestionEditorial{Locked: true}
+
+	editorial, err := database.GetEditorial(c.deps.DB, questionID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get editorial: %w", err)
+	}
+	result.Available = editorial != nil
+
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return result, nil
+	}
+
+	unlock, err := database.GetEditorialUnlock(c.deps.DB, userID, questionID)
+	switch {
+	case err == nil:
+		applyEditorialUnlock(result, unlock)
+	case err == sql.ErrNoRows:
+		isParticipant, err := c.isQuestionParticipant(userID, questionID)
+		if err != nil {
+			return nil, err
+		}
+		result.Locked = !isParticipant
+	default:
+		return nil, fmt.Errorf("failed to get editorial unlock: %w", err)
+	}
+
+	if !result.Locked && editorial != nil {
+		result.Editorial, err = c.dbEditorialToModel(editorial)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GiveUpQuestion records that the current user gave up on a question and
+// unlocks its editorial for them
+func (c *pcdGraphQLControllerImpl) GiveUpQuestion(ctx context.Context, questionID string) (*model.QuestionEditorial, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	if _, err := database.GiveUpQuestion(c.deps.DB, userID, qid); err != nil {
+		return nil, fmt.Errorf("failed to give up: %w", err)
+	}
+
+	return c.QuestionEditorial(ctx, &model.Question{ID: questionID})
+}
+
+// SetEditorial creates or replaces the editorial of a question
+func (c *pcdGraphQLControllerImpl) SetEditorial(ctx context.Context, questionID string, input model.EditorialInput) (*model.Editorial, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, errors.New("editorial body cannot be empty")
+	}
+	if len(body) > maxEditorialBytes {
+		return nil, fmt.Errorf("editorial must be at most %d bytes", maxEditorialBytes)
+	}
+	timeComplexity, err := complexityNote("time complexity", input.TimeComplexity)
+	if err != nil {
+		return nil, err
+	}
+	spaceComplexity, err := complexityNote("space complexity", input.SpaceComplexity)
+	if err != nil {
+		return nil, err
+	}
+
+	editorial, err := database.UpsertEditorial(c.deps.DB, qid, body, timeComplexity, spaceComplexity, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set editorial: %w", err)
+	}
+
+	return c.dbEditorialToModel(editorial)
+}
+
+// SetReferenceSolution sets the reference solution of a question for one language
+func (c *pcdGraphQLControllerImpl) SetReferenceSolution(ctx context.Context, questionID, language, code string) (*model.ReferenceSolution, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" || len(language) > maxLanguageLength {
+		return nil, errors.New("invalid language")
+	}
+	if strings.TrimSpace(code) == "" {
+		return nil, errors.New("reference solution cannot be empty")
+	}
+	if len(code) > maxReferenceSolutionBytes {
+		return nil, fmt.Errorf("reference solution must be at most %d bytes", maxReferenceSolutionBytes)
+	}
+
+	solution, err := database.UpsertReferenceSolution(c.deps.DB, qid, language, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set reference solution: %w", err)
+	}
+
+	return dbReferenceSolutionToModel(solution), nil
+}
+
+func (c *pcdGraphQLControllerImpl) dbEditorialToModel(e *database.Editorial) (*model.Editorial, error) {
+	solutions, err := database.GetReferenceSolutions(c.deps.DB, e.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reference solutions: %w", err)
+	}
+
+	editorial := &model.Editorial{
+		Body:      e.Body,
+		Solutions: make([]*model.ReferenceSolution, len(solutions)),
+		UpdatedAt: e.UpdatedAt.Format(time.RFC3339),
+	}
+	if e.TimeComplexity.Valid {
+		editorial.TimeComplexity = &e.TimeComplexity.String
+	}
+	if e.SpaceComplexity.Valid {
+		editorial.SpaceComplexity = &e.SpaceComplexity.String
+	}
+	for i, s := range solutions {
+		editorial.Solutions[i] = dbReferenceSolutionToModel(s)
+	}
+
+	return editorial, nil
+}
+
+func dbReferenceSolutionToModel(s *database.ReferenceSolution) *model.ReferenceSolution {
+	return &model.ReferenceSolution{
+		Language:  s.Language,
+		Code:      s.Code,
+		UpdatedAt: s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func applyEditorialUnlock(result *model.QuestionEditorial, unlock *database.EditorialUnlock) {
+	reason := model.EditorialUnlockReasonSolved
+	if unlock.Reason == database.UnlockGaveUp {
+		reason = model.EditorialUnlockReasonGaveUp
+	}
+	unlockedAt := unlock.UnlockedAt.Format(time.RFC3339)
+
+	result.Locked = false
+	result.UnlockedBy = &reason
+	result.UnlockedAt = &unlockedAt
+}
+
+// complexityNote trims an optional complexity note, treating blank as unset
+func complexityNote(field string, note *string) (*string, error) {
+	if note == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if len(trimmed) > maxComplexityNoteLength {
+		return nil, fmt.Errorf("%s must be at most %d characters", field, maxComplexityNoteLength)
+	}
+	return &trimmed, nil
+}
@@ -7,21 +7,63 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"codestandoff/backend/graph/model"
 	query "codestandoff/backend/graph/query/reports"
 	"codestandoff/backend/internal/auth"
 	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/grader"
+	"codestandoff/backend/internal/judge"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 // PCDGraphQLController is the main controller interface for GraphQL operations
 type PCDGraphQLController interface {
 	// Training
 	GetQuestions(ctx context.Context, input model.GetQuestionsRequest) (*model.GetQuestionsResponse, error)
+	QuestionsConnection(ctx context.Context, filter *model.GetQuestionsRequest, first *int, after *string, last *int, before *string) (*model.QuestionConnection, error)
+	RandomQuestion(ctx context.Context, filter *model.GetQuestionsRequest, excludeSolved *bool) (*model.Question, error)
+	SimilarQuestions(ctx context.Context, id string, limit *int) ([]*model.Question, error)
+	QuestionBySlug(ctx context.Context, slug string) (*model.Question, error)
+	QuestionExamples(ctx context.Context, question *model.Question) ([]*model.QuestionExample, error)
+	QuestionStarterCode(ctx context.Context, question *model.Question) ([]*model.StarterCode, error)
+	QuestionProblem(ctx context.Context, question *model.Question) (*model.Problem, error)
+	SetStarterCode(ctx context.Context, questionID, language, code string) (*model.StarterCode, error)
+
+	// Statement rendering
+	QuestionDescriptionHTML(ctx context.Context, question *model.Question) (string, error)
+	ProblemDescriptionHTML(ctx context.Context, problem *model.Problem) (string, error)
+	ProblemRevisionDescriptionHTML(ctx context.Context, revision *model.ProblemRevision) (string, error)
+
+	// Function signatures
+	QuestionSignature(ctx context.Context, question *model.Question) (*model.FunctionSignature, error)
+	SetFunctionSignature(ctx context.Context, questionID string, input model.FunctionSignatureInput) ([]*model.StarterCode, error)
+
+	// Checkers
+	QuestionChecker(ctx context.Context, question *model.Question) (*model.Checker, error)
+	SetChecker(ctx context.Context, questionID string, input model.CheckerInput) (*model.Checker, error)
+
+	// Editorials
+	QuestionEditorial(ctx context.Context, question *model.Question) (*model.QuestionEditorial, error)
+	GiveUpQuestion(ctx context.Context, questionID string) (*model.QuestionEditorial, error)
+	SetEditorial(ctx context.Context, questionID string, input model.EditorialInput) (*model.Editorial, error)
+	SetReferenceSolution(ctx context.Context, questionID, language, code string) (*model.ReferenceSolution, error)
+
+	// Problem lists
+	ProblemLists(ctx context.Context) ([]*model.ProblemList, error)
+	FollowedProblemLists(ctx context.Context) ([]*model.ProblemList, error)
+	ProblemList(ctx context.Context, slug string) (*model.ProblemList, error)
+	CreateProblemList(ctx context.Context, input model.CreateProblemListInput) (*model.ProblemList, error)
+	UpdateProblemList(ctx context.Context, id string, input model.UpdateProblemListInput) (*model.ProblemList, error)
+	DeleteProblemList(ctx context.Context, id string) (bool, error)
+	FollowProblemList(ctx context.Context, id string) (*model.ProblemList, error)
+	UnfollowProblemList(ctx context.Context, id string) (*model.ProblemList, error)
+	ProblemListSections(ctx context.Context, list *model.ProblemList) ([]*model.ProblemListSection, error)
+	ProblemListProgress(ctx context.Context, list *model.ProblemList) (*model.ProblemListProgress, error)
 
 	// Auth
 	Me(ctx context.Context) (*model.User, error)
@@ -31,18 +73,75 @@ type PCDGraphQLController interface {
 	Login(ctx context.Context, email, password string) (*model.AuthPayload, error)
 	Logout(ctx context.Context) (bool, error)
 
-	// Problems & Matches (placeholders)
+	// Revisions
+	ProblemRevisions(ctx context.Context, questionID string) ([]*model.ProblemRevision, error)
+	ProblemRevision(ctx context.Context, id string) (*model.ProblemRevision, error)
+
+	// Topics
+	Topics(ctx context.Context) ([]*model.Topic, error)
+	CreateTopic(ctx context.Context, input model.CreateTopicInput) (*model.Topic, error)
+	AddTopicAlias(ctx context.Context, topic, alias string) (*model.Topic, error)
+
+	// Test cases
+	TestCases(ctx context.Context, questionID string) ([]*model.TestCase, error)
+	AddTestCase(ctx context.Context, questionID string, input model.TestCaseInput) (*model.TestCase, error)
+	UpdateTestCase(ctx context.Context, id string, input model.UpdateTestCaseInput) (*model.TestCase, error)
+	ReorderTestCases(ctx context.Context, questionID string, testCaseIDs []string) ([]*model.TestCase, error)
+	DeleteTestCase(ctx context.Context, id string) (bool, error)
+
+	// Problems
 	Problems(ctx context.Context) ([]*model.Problem, error)
 	Problem(ctx context.Context, id string) (*model.Problem, error)
+	CreateProblem(ctx context.Context, title, description, difficulty string, topics []string, timeLimitMs, memoryLimitMb *int) (*model.Problem, error)
+	UpdateProblem(ctx context.Context, id string, input model.UpdateProblemInput) (*model.Problem, error)
+	DeleteProblem(ctx context.Context, id string) (bool, error)
+
+	// Matches
 	Matches(ctx context.Context) ([]*model.Match, error)
 	Match(ctx context.Context, id string) (*model.Match, error)
-	CreateProblem(ctx context.Context, title, description, difficulty string) (*model.Problem, error)
 	CreateMatch(ctx context.Context, problemID string) (*model.Match, error)
+
+	// Submissions
+	SubmitCode(ctx context.Context, questionID string, language string, source string, matchID *string) (*model.Submission, error)
+	RunCode(ctx context.Context, questionID string, language string, source string, input *string, matchID *string) (*model.CodeRun, error)
+	Submissions(ctx context.Context, questionID string, limit *int, offset *int) ([]*model.Submission, error)
+	Submission(ctx context.Context, id string) (*model.Submission, error)
+	SubmissionTestResults(ctx context.Context, submission *model.Submission) ([]*model.TestResult, error)
+	JudgeQueue(ctx context.Context) (*model.JudgeQueue, error)
+	Languages(ctx context.Context) ([]*model.Language, error)
+
+	// Problem review workflow
+	MyProblems(ctx context.Context) ([]*model.Problem, error)
+	ReviewQueue(ctx context.Context) ([]*model.Problem, error)
+	SubmitProblemForReview(ctx context.Context, id string) (*model.Problem, error)
+	ReturnProblemToDraft(ctx context.Context, id string) (*model.Problem, error)
+	ArchiveProblem(ctx context.Context, id string) (*model.Problem, error)
+	AssignReviewer(ctx context.Context, problemID, userID string) (*model.Problem, error)
+	ReviewProblem(ctx context.Context, problemID string, decision model.ReviewDecision, comment *string) (*model.Problem, error)
+	AddReviewComment(ctx context.Context, problemID, body string) (*model.ReviewComment, error)
+	ProblemReviewers(ctx context.Context, problem *model.Problem) ([]*model.ProblemReviewer, error)
+	ProblemReviewComments(ctx context.Context, problem *model.Problem) ([]*model.ReviewComment, error)
+	SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error)
 }
 
+// Problem defaults and bounds
+const (
+	defaultTimeLimitMs   = 2000
+	defaultMemoryLimitMb = 256
+	maxTimeLimitMs       = 10000
+	maxMemoryLimitMb     = 1024
+)
+
+// Allowed question difficulties
+var difficulties = []string{"Easy", "Medium", "Hard"}
+
 // PCDGraphQLControllerDeps contains dependencies for the controller
 type PCDGraphQLControllerDeps struct {
 	DB *sql.DB
+	// Languages are the languages submissions are judged in
+	Languages *judge.Registry
+	// Runner runs code for runCode; nil if this server cannot run code
+	Runner *grader.Runner
 }
 
 type pcdGraphQLControllerImpl struct {
@@ -101,14 +200,21 @@ func (c *pcdGraphQLControllerImpl) GetQuestions(ctx context.Context, input model
 		offset = 0
 	}
 
-	// Convert topics
-	var topics []string
-	if input.Topics != nil {
-		topics = input.Topics
+	// Resolve topics through the topic catalog
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+	filter, err := questionFilterFromInput(catalog, &input)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get query string and arguments from query builder
-	queryStr, args := query.GetQuestionsQueryWithArgs(offset, limit+1, input.Search, input.Difficulty, topics, input.SortBy, input.SortOrder) // +1 to check hasMore
+	queryStr, args, err := query.GetQuestionsQueryWithArgs(offset, limit+1, filter, input.SortBy, input.SortOrder) // +1 to check hasMore
+	if err != nil {
+		return nil, err
+	}
 
 	log.Printf("[GetQuestions] Executing query: %s", queryStr)
 	log.Printf("[GetQuestions] Query args: %v", args)
@@ -123,32 +229,26 @@ func (c *pcdGraphQLControllerImpl) GetQuestions(ctx context.Context, input model
 
 	// Scan results
 	var questions []*model.Question
+	var highlights []*model.SearchHighlight
 	for rows.Next() {
 		var q database.Question
-		err := rows.Scan(
-			&q.ID,
-			&q.Title,
-			&q.Slug,
-			&q.Description,
-			&q.Difficulty,
-			pq.Array(&q.Topics),
-			&q.TestCaseCount,
-		)
+		var rank float64
+		var titleHighlight, snippet sql.NullString
+		err := rows.Scan(questionScanDest(&q, &rank, &titleHighlight, &snippet)...)
 		if err != nil {
 			log.Printf("[GetQuestions] Row scan error: %v", err)
 			return nil, fmt.Errorf("failed to scan question: %w", err)
 		}
 
-		question := &model.Question{
-			ID:            fmt.Sprintf("%d", q.ID),
-			Title:         q.Title,
-			Slug:          q.Slug,
-			Description:   q.Description,
-			Difficulty:    q.Difficulty,
-			Topics:        q.Topics,
-			TestCaseCount: q.TestCaseCount,
+		questions = append(questions, dbQuestionToModel(&q))
+		if titleHighlight.Valid {
+			highlights = append(highlights, &model.SearchHighlight{
+				QuestionID: strconv.Itoa(q.ID),
+				Title:      query.FormatHighlight(titleHighlight.String),
+				Snippet:    query.FormatHighlight(snippet.String),
+				Rank:       rank,
+			})
 		}
-		questions = append(questions, question)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -160,37 +260,39 @@ func (c *pcdGraphQLControllerImpl) GetQuestions(ctx context.Context, input model
 	hasMore := len(questions) > limit
 	if hasMore {
 		questions = questions[:limit] // Remove the extra item
+		if len(highlights) > limit {
+			highlights = highlights[:limit]
+		}
 	}
 
-	log.Printf("[GetQuestions] Returning %d questions, hasMore=%v", len(questions), hasMore)
+	totalCount, err := c.countQuestions(filter)
+	if err != nil {
+		log.Printf("[GetQuestions] Count error: %v", err)
+		return nil, err
+	}
+
+	facets, err := c.questionFacets(catalog, filter)
+	if err != nil {
+		log.Printf("[GetQuestions] Facets error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetQuestions] Returning %d of %d questions, hasMore=%v", len(questions), totalCount, hasMore)
 
 	return &model.GetQuestionsResponse{
 		Questions:  questions,
-		TotalCount: len(questions),
+		TotalCount: totalCount,
 		HasMore:    hasMore,
+		Highlights: highlights,
+		Facets:     facets,
 	}, nil
 }
 
 // Me returns the current authenticated user
 func (c *pcdGraphQLControllerImpl) Me(ctx context.Context) (*model.User, error) {
-	r := GetRequest(ctx)
-	if r == nil {
-		return nil, errors.New("not authenticated")
-	}
-
-	cookie, err := r.Cookie("auth_token")
+	userID, err := c.currentUserID(ctx)
 	if err != nil {
-		return nil, errors.New("not authenticated")
-	}
-
-	claims, err := auth.ValidateJWT(cookie.Value)
-	if err != nil {
-		return nil, errors.New("invalid or expired token")
-	}
-
-	userID, err := uuid.Parse(claims.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid user ID in token: %w", err)
+		return nil, err
 	}
 
 	dbUser, err := database.GetUserByID(c.deps.DB, userID)
@@ -331,47 +433,299 @@ func (c *pcdGraphQLControllerImpl) Logout(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-// Problems returns all problems (placeholder)
+// Problems returns all problems
 func (c *pcdGraphQLControllerImpl) Problems(ctx context.Context) ([]*model.Problem, error) {
-	return []*model.Problem{}, nil
+	dbProblems, err := database.GetAllProblems(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problems: %w", err)
+	}
+
+	problems := make([]*model.Problem, len(dbProblems))
+	for i, p := range dbProblems {
+		problems[i] = dbProblemToModel(p)
+	}
+
+	return problems, nil
 }
 
-// Problem returns a problem by ID (placeholder)
+// Problem returns a problem by ID. Unpublished problems are only visible to
+// their author, reviewers and admins.
 func (c *pcdGraphQLControllerImpl) Problem(ctx context.Context, id string) (*model.Problem, error) {
-	return nil, nil
+	questionID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid problem ID: %w", err)
+	}
+
+	dbProblem, err := database.GetProblemByID(c.deps.DB, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	visible, err := c.canViewQuestion(ctx, questionID, dbProblem.Status)
+	if err != nil || !visible {
+		return nil, err
+	}
+
+	return dbProblemToModel(dbProblem), nil
 }
 
-// Matches returns all matches (placeholder)
+// Matches returns all matches, newest first
 func (c *pcdGraphQLControllerImpl) Matches(ctx context.Context) ([]*model.Match, error) {
-	return []*model.Match{}, nil
+	dbMatches, err := database.GetAllMatches(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matches: %w", err)
+	}
+
+	matches := make([]*model.Match, 0, len(dbMatches))
+	for _, m := range dbMatches {
+		match, err := c.dbMatchToModel(m)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, nil
 }
 
-// Match returns a match by ID (placeholder)
+// Match returns a match by ID
 func (c *pcdGraphQLControllerImpl) Match(ctx context.Context, id string) (*model.Match, error) {
-	return nil, nil
+	matchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match ID: %w", err)
+	}
+
+	m, err := database.GetMatchByID(c.deps.DB, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get match: %w", err)
+	}
+
+	return c.dbMatchToModel(m)
 }
 
-// CreateProblem creates a new problem (placeholder)
-func (c *pcdGraphQLControllerImpl) CreateProblem(ctx context.Context, title, description, difficulty string) (*model.Problem, error) {
-	return &model.Problem{
-		ID:          "new-problem-id",
-		Title:       title,
-		Description: description,
-		Difficulty:  difficulty,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-	}, nil
+// CreateProblem creates a draft problem authored by the current user, who
+// needs the author, reviewer or admin role
+func (c *pcdGraphQLControllerImpl) CreateProblem(ctx context.Context, title, description, difficulty string, topics []string, timeLimitMs, memoryLimitMb *int) (*model.Problem, error) {
+	userID, err := c.requireRole(ctx, database.RoleAuthor, database.RoleReviewer, database.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err = c.canonicalTopics(topics)
+	if err != nil {
+		return nil, err
+	}
+
+	params := database.CreateProblemParams{
+		Title:         strings.TrimSpace(title),
+		Description:   description,
+		Difficulty:    difficulty,
+		Topics:        topics,
+		TimeLimitMs:   defaultTimeLimitMs,
+		MemoryLimitMb: defaultMemoryLimitMb,
+		AuthorID:      userID,
+	}
+	if timeLimitMs != nil {
+		params.TimeLimitMs = *timeLimitMs
+	}
+	if memoryLimitMb != nil {
+		params.MemoryLimitMb = *memoryLimitMb
+	}
+
+	if params.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if err := validateDifficulty(params.Difficulty); err != nil {
+		return nil, err
+	}
+	if err := validateLimits(&params.TimeLimitMs, &params.MemoryLimitMb); err != nil {
+		return nil, err
+	}
+
+	dbProblem, err := database.CreateProblem(c.deps.DB, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create problem: %w", err)
+	}
+
+	return dbProblemToModel(dbProblem), nil
 }
 
-// CreateMatch creates a new match (placeholder)
+// UpdateProblem updates a problem; only its author may do so
+func (c *pcdGraphQLControllerImpl) UpdateProblem(ctx context.Context, id string, input model.UpdateProblemInput) (*model.Problem, error) {
+	questionID, err := c.requireProblemAuthor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		return nil, errors.New("title cannot be empty")
+	}
+	if input.Difficulty != nil {
+		if err := validateDifficulty(*input.Difficulty); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateLimits(input.TimeLimitMs, input.MemoryLimitMb); err != nil {
+		return nil, err
+	}
+	if input.Topics != nil {
+		input.Topics, err = c.canonicalTopics(input.Topics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dbProblem, err := database.UpdateProblem(c.deps.DB, questionID, database.UpdateProblemParams{
+		Title:         input.Title,
+		Description:   input.Description,
+		Difficulty:    input.Difficulty,
+		Topics:        input.Topics,
+		Constraints:   input.Constraints,
+		Hints:         input.Hints,
+		TimeLimitMs:   input.TimeLimitMs,
+		MemoryLimitMb: input.MemoryLimitMb,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("problem not found")
+		}
+		return nil, fmt.Errorf("failed to update problem: %w", err)
+	}
+
+	return dbProblemToModel(dbProblem), nil
+}
+
+// DeleteProblem removes a problem from the 1v1 pool; the question stays available for training
+func (c *pcdGraphQLControllerImpl) DeleteProblem(ctx context.Context, id string) (bool, error) {
+	questionID, err := c.requireProblemAuthor(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := database.DeleteProblem(c.deps.DB, questionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete problem: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CreateMatch opens a match on a problem for the current user. The match is
+// pinned to the problem's current revision, so later edits do not affect it.
 func (c *pcdGraphQLControllerImpl) CreateMatch(ctx context.Context, problemID string) (*model.Match, error) {
-	return &model.Match{
-		ID:        "new-match-id",
-		Status:    "waiting",
-		CreatedAt: time.Now().Format(time.RFC3339),
-	}, nil
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	questionID, err := strconv.Atoi(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid problem ID: %w", err)
+	}
+	p, err := database.GetProblemByID(c.deps.DB, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("problem not found")
+		}
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+	if p.Status != database.StatusPublished {
+		return nil, errors.New("only published problems can be played")
+	}
+
+	m, err := database.CreateMatch(c.deps.DB, userID, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create match: %w", err)
+	}
+
+	return c.dbMatchToModel(m)
 }
 
 // Helper functions
+func (c *pcdGraphQLControllerImpl) currentUserID(ctx context.Context) (uuid.UUID, error) {
+	r := GetRequest(ctx)
+	if r == nil {
+		return uuid.Nil, errors.New("not authenticated")
+	}
+
+	cookie, err := r.Cookie("auth_token")
+	if err != nil {
+		return uuid.Nil, errors.New("not authenticated")
+	}
+
+	claims, err := auth.ValidateJWT(cookie.Value)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid or expired token")
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// requireProblemAuthor parses a problem ID and checks that the current user authored it
+func (c *pcdGraphQLControllerImpl) requireProblemAuthor(ctx context.Context, id string) (int, error) {
+	questionID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("invalid problem ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, questionID); err != nil {
+		return 0, err
+	}
+
+	return questionID, nil
+}
+
+// requireQuestionAuthor checks that the current user authored the question
+// and that it is a draft; anything else has been or is being reviewed
+func (c *pcdGraphQLControllerImpl) requireQuestionAuthor(ctx context.Context, questionID int) error {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	isAuthor, err := c.isQuestionAuthor(userID, questionID)
+	if err != nil {
+		return err
+	}
+	if !isAuthor {
+		return errors.New("only the question author can modify it")
+	}
+
+	status, err := database.GetQuestionStatus(c.deps.DB, questionID)
+	if err != nil {
+		return fmt.Errorf("failed to get question: %w", err)
+	}
+	if status != database.StatusDraft {
+		return fmt.Errorf("the question is %s; return it to draft to edit it", statusLabel(status))
+	}
+
+	return nil
+}
+
+// isQuestionAuthor reports whether userID authored the question
+func (c *pcdGraphQLControllerImpl) isQuestionAuthor(userID uuid.UUID, questionID int) (bool, error) {
+	authorID, err := database.GetQuestionAuthorID(c.deps.DB, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors.New("question not found")
+		}
+		return false, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	return authorID.Valid && authorID.UUID == userID, nil
+}
+
 func (c *pcdGraphQLControllerImpl) setAuthCookie(ctx context.Context, token string, expiresAt time.Time) {
 	w := GetResponseWriter(ctx)
 	if w != nil {
@@ -400,6 +754,76 @@ func (c *pcdGraphQLControllerImpl) clearAuthCookie(ctx context.Context) {
 	}
 }
 
+func validateDifficulty(difficulty string) error {
+	for _, d := range difficulties {
+		if d == difficulty {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid difficulty %q: must be one of %s", difficulty, strings.Join(difficulties, ", "))
+}
+
+func validateLimits(timeLimitMs, memoryLimitMb *int) error {
+	if timeLimitMs != nil && (*timeLimitMs <= 0 || *timeLimitMs > maxTimeLimitMs) {
+		return fmt.Errorf("timeLimitMs must be between 1 and %d", maxTimeLimitMs)
+	}
+	if memoryLimitMb != nil && (*memoryLimitMb <= 0 || *memoryLimitMb > maxMemoryLimitMb) {
+		return fmt.Errorf("memoryLimitMb must be between 1 and %d", maxMemoryLimitMb)
+	}
+	return nil
+}
+
+func dbProblemToModel(p *database.Problem) *model.Problem {
+	return &model.Problem{
+		ID:            strconv.Itoa(p.QuestionID),
+		Title:         p.Title,
+		Slug:          p.Slug,
+		Description:   p.Description,
+		Difficulty:    p.Difficulty,
+		Topics:        p.Topics,
+		TimeLimitMs:   p.TimeLimitMs,
+		MemoryLimitMb: p.MemoryLimitMb,
+		Status:        model.ProblemStatus(strings.ToUpper(p.Status)),
+		CreatedAt:     p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// dbMatchToModel loads the players and problem of a match
+func (c *pcdGraphQLControllerImpl) dbMatchToModel(m *database.Match) (*model.Match, error) {
+	player1, err := database.GetUserByID(c.deps.DB, m.Player1ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	match := &model.Match{
+		ID:         m.ID.String(),
+		Player1:    dbUserToModel(player1),
+		Status:     m.Status,
+		RevisionID: strconv.FormatInt(m.RevisionID, 10),
+		CreatedAt:  m.CreatedAt.Format(time.RFC3339),
+	}
+
+	if m.Player2ID.Valid {
+		player2, err := database.GetUserByID(c.deps.DB, m.Player2ID.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get player: %w", err)
+		}
+		match.Player2 = dbUserToModel(player2)
+	}
+
+	// The problem may have left the 1v1 pool since the match was created
+	p, err := database.GetProblemByID(c.deps.DB, m.QuestionID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+	if p != nil {
+		match.Problem = dbProblemToModel(p)
+	}
+
+	return match, nil
+}
+
 func dbUserToModel(u *database.User) *model.User {
 	user := &model.User{
 		ID:            u.ID.String(),
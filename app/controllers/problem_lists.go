@@ -0,0 +1,427 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Upper bounds for problem lists
+const (
+	maxListTitleLength       = 200
+	maxListDescriptionLength = 5000
+	maxListSections          = 100
+	maxListQuestions         = 500
+)
+
+// ProblemLists returns the public lists plus the current user's own lists
+func (c *pcdGraphQLControllerImpl) ProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	var viewerID uuid.NullUUID
+	if userID, err := c.currentUserID(ctx); err == nil {
+		viewerID = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+
+	lists, err := database.GetVisibleProblemLists(c.deps.DB, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	return c.dbProblemListsToModel(lists)
+}
+
+// FollowedProblemLists returns the lists the current user follows
+func (c *pcdGraphQLControllerImpl) FollowedProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := database.GetFollowedProblemLists(c.deps.DB, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	// A followed list may since have been made private
+	visible := lists[:0]
+	for _, l := range lists {
+		if l.IsPublic || l.OwnerID == userID {
+			visible = append(visible, l)
+		}
+	}
+
+	return c.dbProblemListsToModel(visible)
+}
+
+// ProblemList returns a list by slug, or nil if it does not exist or is private to someone else
+func (c *pcdGraphQLControllerImpl) ProblemList(ctx context.Context, slug string) (*model.ProblemList, error) {
+	l, err := database.GetProblemListBySlug(c.deps.DB, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	if !c.canViewProblemList(ctx, l) {
+		return nil, nil
+	}
+
+	return c.dbProblemListToModel(l)
+}
+
+// CreateProblemList creates a list owned by the current user
+func (c *pcdGraphQLControllerImpl) CreateProblemList(ctx context.Context, input model.CreateProblemListInput) (*model.ProblemList, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := c.problemListParams(&input.Title, input.Description, input.IsPublic, input.Sections)
+	if err != nil {
+		return nil, err
+	}
+	if params.Sections == nil {
+		params.Sections = []database.ProblemListSectionParams{}
+	}
+
+	l, err := database.CreateProblemList(c.deps.DB, userID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list: %w", err)
+	}
+
+	return c.dbProblemListToModel(l)
+}
+
+// UpdateProblemList updates a list; only its owner or an admin may do so.
+// Passing sections replaces the list's contents.
+func (c *pcdGraphQLControllerImpl) UpdateProblemList(ctx context.Context, id string, input model.UpdateProblemListInput) (*model.ProblemList, error) {
+	listID, err := c.requireProblemListOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := c.problemListParams(input.Title, input.Description, input.IsPublic, input.Sections)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := database.UpdateProblemList(c.deps.DB, listID, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("list not found")
+		}
+		return nil, fmt.Errorf("failed to update list: %w", err)
+	}
+
+	return c.dbProblemListToModel(l)
+}
+
+// DeleteProblemList deletes a list; only its owner or an admin may do so
+func (c *pcdGraphQLControllerImpl) DeleteProblemList(ctx context.Context, id string) (bool, error) {
+	listID, err := c.requireProblemListOwner(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := database.DeleteProblemList(c.deps.DB, listID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete list: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// FollowProblemList makes the current user follow a list to track their progress on it
+func (c *pcdGraphQLControllerImpl) FollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return c.setFollowing(ctx, id, true)
+}
+
+// UnfollowProblemList stops the current user following a list
+func (c *pcdGraphQLControllerImpl) UnfollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return c.setFollowing(ctx, id, false)
+}
+
+func (c *pcdGraphQLControllerImpl) setFollowing(ctx context.Context, id string, follow bool) (*model.ProblemList, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := c.getProblemList(id)
+	if err != nil {
+		return nil, err
+	}
+	if !c.canViewProblemList(ctx, l) {
+		return nil, errors.New("list not found")
+	}
+
+	if follow {
+		err = database.FollowProblemList(c.deps.DB, userID, l.ID)
+	} else {
+		err = database.UnfollowProblemList(c.deps.DB, userID, l.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update follow: %w", err)
+	}
+
+	l, err = database.GetProblemListByID(c.deps.DB, l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	return c.dbProblemListToModel(l)
+}
+
+// ProblemListSections returns the sections of a list with their questions
+func (c *pcdGraphQLControllerImpl) ProblemListSections(ctx context.Context, list *model.ProblemList) ([]*model.ProblemListSection, error) {
+	listID, err := strconv.Atoi(list.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list ID: %w", err)
+	}
+
+	sections, err := database.GetProblemListSections(c.deps.DB, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+
+	result := make([]*model.ProblemListSection, len(sections))
+	for i, s := range sections {
+		result[i] = &model.ProblemListSection{
+			ID:        strconv.Itoa(s.ID),
+			Title:     s.Title,
+			Questions: make([]*model.Question, len(s.Questions)),
+		}
+		if s.Description.Valid {
+			result[i].Description = &s.Description.String
+		}
+		for j, q := range s.Questions {
+			result[i].Questions[j] = dbQuestionToModel(q)
+		}
+	}
+
+	return result, nil
+}
+
+// ProblemListProgress returns the current user's progress on a list, or nil when signed out
+func (c *pcdGraphQLControllerImpl) ProblemListProgress(ctx context.Context, list *model.ProblemList) (*model.ProblemListProgress, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, nil
+	}
+
+	listID, err := strconv.Atoi(list.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list ID: %w", err)
+	}
+
+	sections, err := database.GetProblemListSections(c.deps.DB, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+	var questionIDs []int
+	for _, s := range sections {
+		for _, q := range s.Questions {
+			questionIDs = append(questionIDs, q.ID)
+		}
+	}
+
+	solved, err := database.GetSolvedQuestionIDs(c.deps.DB, userID, questionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get progress: %w", err)
+	}
+
+	progress := &model.ProblemListProgress{
+		TotalCount:        len(questionIDs),
+		SolvedQuestionIds: []string{},
+	}
+	for _, id := range questionIDs {
+		if solved[id] {
+			progress.SolvedCount++
+			progress.SolvedQuestionIds = append(progress.SolvedQuestionIds, strconv.Itoa(id))
+		}
+	}
+
+	follow, err := database.GetProblemListFollow(c.deps.DB, userID, listID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get follow: %w", err)
+	}
+	if follow != nil {
+		followedAt := follow.FollowedAt.Format(time.RFC3339)
+		progress.Following = true
+		progress.FollowedAt = &followedAt
+		if follow.CompletedAt.Valid {
+			completedAt := follow.CompletedAt.Time.Format(time.RFC3339)
+			progress.CompletedAt = &completedAt
+		}
+	}
+
+	return progress, nil
+}
+
+// problemListParams validates list input. Nil sections leave the contents unchanged.
+func (c *pcdGraphQLControllerImpl) problemListParams(title, description *string, isPublic *bool, sections []*model.ProblemListSectionInput) (database.ProblemListParams, error) {
+	params := database.ProblemListParams{IsPublic: isPublic}
+
+	if title != nil {
+		t := strings.TrimSpace(*title)
+		if t == "" || len(t) > maxListTitleLength {
+			return params, fmt.Errorf("title must be between 1 and %d characters", maxListTitleLength)
+		}
+		params.Title = &t
+	}
+	if description != nil {
+		d := strings.TrimSpace(*description)
+		if len(d) > maxListDescriptionLength {
+			return params, fmt.Errorf("description must be at most %d characters", maxListDescriptionLength)
+		}
+		params.Description = &d
+	}
+
+	if sections == nil {
+		return params, nil
+	}
+	if len(sections) > maxListSections {
+		return params, fmt.Errorf("a list can have at most %d sections", maxListSections)
+	}
+
+	params.Sections = make([]database.ProblemListSectionParams, 0, len(sections))
+	seen := map[int]bool{}
+	for _, s := range sections {
+		section := database.ProblemListSectionParams{Title: strings.TrimSpace(s.Title)}
+		if section.Title == "" || len(section.Title) > maxListTitleLength {
+			return params, fmt.Errorf("section title must be between 1 and %d characters", maxListTitleLength)
+		}
+		if s.Description != nil {
+			d := strings.TrimSpace(*s.Description)
+			if len(d) > maxListDescriptionLength {
+				return params, fmt.Errorf("section description must be at most %d characters", maxListDescriptionLength)
+			}
+			section.Description = &d
+		}
+
+		for _, id := range s.QuestionIds {
+			questionID, err := strconv.Atoi(id)
+			if err != nil {
+				return params, fmt.Errorf("invalid question ID %q", id)
+			}
+			if seen[questionID] {
+				return params, fmt.Errorf("question %d appears more than once", questionID)
+			}
+			seen[questionID] = true
+
+			status, err := database.GetQuestionStatus(c.deps.DB, questionID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return params, fmt.Errorf("question %d not found", questionID)
+				}
+				return params, fmt.Errorf("failed to get question: %w", err)
+			}
+			if status != database.StatusPublished {
+				return params, fmt.Errorf("question %d is not published", questionID)
+			}
+			section.QuestionIDs = append(section.QuestionIDs, questionID)
+		}
+		params.Sections = append(params.Sections, section)
+	}
+	if len(seen) > maxListQuestions {
+		return params, fmt.Errorf("a list can have at most %d questions", maxListQuestions)
+	}
+
+	return params, nil
+}
+
+func (c *pcdGraphQLControllerImpl) getProblemList(id string) (*database.ProblemList, error) {
+	listID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list ID: %w", err)
+	}
+
+	l, err := database.GetProblemListByID(c.deps.DB, listID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("list not found")
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	return l, nil
+}
+
+// requireProblemListOwner checks that the current user owns the list or is an admin and returns its ID
+func (c *pcdGraphQLControllerImpl) requireProblemListOwner(ctx context.Context, id string) (int, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	l, err := c.getProblemList(id)
+	if err != nil {
+		return 0, err
+	}
+	if l.OwnerID == userID {
+		return l.ID, nil
+	}
+
+	role, err := database.GetUserRole(c.deps.DB, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role != database.RoleAdmin {
+		return 0, errors.New("only the list owner can modify it")
+	}
+
+	return l.ID, nil
+}
+
+// canViewProblemList reports whether the current user may see a list. Private lists are only visible to their owner.
+func (c *pcdGraphQLControllerImpl) canViewProblemList(ctx context.Context, l *database.ProblemList) bool {
+	if l.IsPublic {
+		return true
+	}
+	userID, err := c.currentUserID(ctx)
+	return err == nil && userID == l.OwnerID
+}
+
+func (c *pcdGraphQLControllerImpl) dbProblemListToModel(l *database.ProblemList) (*model.ProblemList, error) {
+	owner, err := database.GetUserByID(c.deps.DB, l.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list owner: %w", err)
+	}
+
+	list := &model.ProblemList{
+		ID:            strconv.Itoa(l.ID),
+		Slug:          l.Slug,
+		Title:         l.Title,
+		IsPublic:      l.IsPublic,
+		Owner:         dbUserToModel(owner),
+		QuestionCount: l.QuestionCount,
+		FollowerCount: l.FollowerCount,
+		CreatedAt:     l.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     l.UpdatedAt.Format(time.RFC3339),
+	}
+	if l.Description.Valid {
+		list.Description = &l.Description.String
+	}
+
+	return list, nil
+}
+
+func (c *pcdGraphQLControllerImpl) dbProblemListsToModel(lists []*database.ProblemList) ([]*model.ProblemList, error) {
+	result := make([]*model.ProblemList, 0, len(lists))
+	for _, l := range lists {
+		list, err := c.dbProblemListToModel(l)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list)
+	}
+	return result, nil
+}
@@ -0,0 +1,495 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// maxReviewCommentLength bounds the size of a review comment
+const maxReviewCommentLength = 10000
+
+// MyProblems returns the problems authored by the current user, in any state
+func (c *pcdGraphQLControllerImpl) MyProblems(ctx context.Context) ([]*model.Problem, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbProblems, err := database.GetProblemsByAuthor(c.deps.DB, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problems: %w", err)
+	}
+
+	return dbProblemsToModel(dbProblems), nil
+}
+
+// ReviewQueue returns the problems waiting for the current user's review
+func (c *pcdGraphQLControllerImpl) ReviewQueue(ctx context.Context) ([]*model.Problem, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbProblems, err := database.GetProblemsInReviewFor(c.deps.DB, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review queue: %w", err)
+	}
+
+	return dbProblemsToModel(dbProblems), nil
+}
+
+// SubmitProblemForReview moves a draft into review. At least one reviewer must be assigned.
+func (c *pcdGraphQLControllerImpl) SubmitProblemForReview(ctx context.Context, id string) (*model.Problem, error) {
+	questionID, _, err := c.requireProblemOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers, err := database.GetProblemReviewers(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	if len(reviewers) == 0 {
+		return nil, errors.New("assign a reviewer before submitting for review")
+	}
+
+	return c.transitionProblem(questionID, []string{database.StatusDraft}, database.StatusInReview)
+}
+
+// ReturnProblemToDraft takes a problem out of review, or reopens an archived one, so it can be edited
+func (c *pcdGraphQLControllerImpl) ReturnProblemToDraft(ctx context.Context, id string) (*model.Problem, error) {
+	questionID, _, err := c.requireProblemOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transitionProblem(questionID, []string{database.StatusInReview, database.StatusArchived}, database.StatusDraft)
+}
+
+// ArchiveProblem withdraws a published problem from listings and matches
+func (c *pcdGraphQLControllerImpl) ArchiveProblem(ctx context.Context, id string) (*model.Problem, error) {
+	questionID, _, err := c.requireProblemOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transitionProblem(questionID, []string{database.StatusPublished}, database.StatusArchived)
+}
+
+// AssignReviewer assigns a user with the reviewer or admin role to review a problem
+func (c *pcdGraphQLControllerImpl) AssignReviewer(ctx context.Context, problemID, userID string) (*model.Problem, error) {
+	questionID, currentUserID, err := c.requireProblemOwner(ctx, problemID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	role, err := database.GetUserRole(c.deps.DB, reviewerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if role != database.RoleReviewer && role != database.RoleAdmin {
+		return nil, errors.New("reviewers must have the reviewer or admin role")
+	}
+
+	isAuthor, err := c.isQuestionAuthor(reviewerID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if isAuthor {
+		return nil, errors.New("authors cannot review their own problems")
+	}
+
+	if err := database.AssignReviewer(c.deps.DB, questionID, reviewerID, currentUserID); err != nil {
+		return nil, fmt.Errorf("failed to assign reviewer: %w", err)
+	}
+
+	return c.Problem(ctx, problemID)
+}
+
+// ReviewProblem records the current user's decision on a problem they are reviewing.
+// Requesting changes returns the problem to draft; once every reviewer approves it is published.
+func (c *pcdGraphQLControllerImpl) ReviewProblem(ctx context.Context, problemID string, decision model.ReviewDecision, comment *string) (*model.Problem, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	questionID, err := strconv.Atoi(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid problem ID: %w", err)
+	}
+
+	var dbDecision string
+	switch decision {
+	case model.ReviewDecisionApprove:
+		dbDecision = database.DecisionApproved
+	case model.ReviewDecisionRequestChanges:
+		dbDecision = database.DecisionChangesRequested
+		if comment == nil || strings.TrimSpace(*comment) == "" {
+			return nil, errors.New("a comment is required when requesting changes")
+		}
+	default:
+		return nil, fmt.Errorf("invalid decision %q", decision)
+	}
+
+	body := ""
+	if comment != nil && strings.TrimSpace(*comment) != "" {
+		if err := validateReviewComment(*comment); err != nil {
+			return nil, err
+		}
+		body = strings.TrimSpace(*comment)
+	}
+
+	_, err = database.RecordReviewDecision(c.deps.DB, questionID, userID, dbDecision, body)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, errors.New("you are not assigned to review this problem")
+		case database.ErrStatusChanged:
+			return nil, errors.New("problem is not in review")
+		}
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	return c.Problem(ctx, problemID)
+}
+
+// AddReviewComment comments on a problem; only its author, reviewers and admins may do so
+func (c *pcdGraphQLControllerImpl) AddReviewComment(ctx context.Context, problemID, body string) (*model.ReviewComment, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	questionID, err := strconv.Atoi(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid problem ID: %w", err)
+	}
+
+	isParticipant, err := c.isQuestionParticipant(userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, errors.New("only the author and reviewers can comment on a problem")
+	}
+
+	body = strings.TrimSpace(body)
+	if err := validateReviewComment(body); err != nil {
+		return nil, err
+	}
+
+	comment, err := database.AddReviewComment(c.deps.DB, questionID, userID, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return c.dbReviewCommentToModel(comment)
+}
+
+// ProblemReviewers returns the reviewers of a problem. Only its author, reviewers and admins can see them.
+func (c *pcdGraphQLControllerImpl) ProblemReviewers(ctx context.Context, problem *model.Problem) ([]*model.ProblemReviewer, error) {
+	questionID, ok, err := c.problemParticipant(ctx, problem)
+	if err != nil || !ok {
+		return []*model.ProblemReviewer{}, err
+	}
+
+	reviewers, err := database.GetProblemReviewers(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+
+	result := make([]*model.ProblemReviewer, 0, len(reviewers))
+	for _, r := range reviewers {
+		user, err := database.GetUserByID(c.deps.DB, r.ReviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reviewer: %w", err)
+		}
+		reviewer := &model.ProblemReviewer{
+			User:       dbUserToModel(user),
+			AssignedAt: r.AssignedAt.Format(time.RFC3339),
+		}
+		if r.Decision.Valid {
+			decision := model.ReviewDecisionApprove
+			if r.Decision.String == database.DecisionChangesRequested {
+				decision = model.ReviewDecisionRequestChanges
+			}
+			reviewer.Decision = &decision
+		}
+		if r.DecidedAt.Valid {
+			decidedAt := r.DecidedAt.Time.Format(time.RFC3339)
+			reviewer.DecidedAt = &decidedAt
+		}
+		result = append(result, reviewer)
+	}
+
+	return result, nil
+}
+
+// ProblemReviewComments returns the review comments on a problem. Only its author, reviewers and admins can see them.
+func (c *pcdGraphQLControllerImpl) ProblemReviewComments(ctx context.Context, problem *model.Problem) ([]*model.ReviewComment, error) {
+	questionID, ok, err := c.problemParticipant(ctx, problem)
+	if err != nil || !ok {
+		return []*model.ReviewComment{}, err
+	}
+
+	comments, err := database.GetReviewComments(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	result := make([]*model.ReviewComment, 0, len(comments))
+	for _, comment := range comments {
+		m, err := c.dbReviewCommentToModel(comment)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// SetUserRole changes a user's role; only admins may do so
+func (c *pcdGraphQLControllerImpl) SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	if _, err := c.requireRole(ctx, database.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	if !role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", role)
+	}
+
+	if err := database.SetUserRole(c.deps.DB, id, strings.ToLower(string(role))); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to set role: %w", err)
+	}
+
+	user, err := database.GetUserByID(c.deps.DB, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return dbUserToModel(user), nil
+}
+
+// transitionProblem moves a problem to a new state if it is currently in one of from
+func (c *pcdGraphQLControllerImpl) transitionProblem(questionID int, from []string, to string) (*model.Problem, error) {
+	status, err := database.GetQuestionStatus(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	allowed := false
+	for _, s := range from {
+		allowed = allowed || s == status
+	}
+	if !allowed {
+		return nil, fmt.Errorf("cannot move a %s problem to %s", statusLabel(status), statusLabel(to))
+	}
+
+	if err := database.SetQuestionStatus(c.deps.DB, questionID, status, to); err != nil {
+		if err == database.ErrStatusChanged {
+			return nil, errors.New("problem was changed by someone else; try again")
+		}
+		return nil, err
+	}
+
+	p, err := database.GetProblemByID(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	return dbProblemToModel(p), nil
+}
+
+// requireProblemOwner parses a problem ID and checks that the current user
+// authored it or is an admin. It returns the question and user IDs.
+func (c *pcdGraphQLControllerImpl) requireProblemOwner(ctx context.Context, id string) (int, uuid.UUID, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+
+	questionID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("invalid problem ID: %w", err)
+	}
+	if _, err := database.GetProblemByID(c.deps.DB, questionID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, uuid.Nil, errors.New("problem not found")
+		}
+		return 0, uuid.Nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	isAuthor, err := c.isQuestionAuthor(userID, questionID)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	if !isAuthor {
+		role, err := database.GetUserRole(c.deps.DB, userID)
+		if err != nil {
+			return 0, uuid.Nil, fmt.Errorf("failed to get role: %w", err)
+		}
+		if role != database.RoleAdmin {
+			return 0, uuid.Nil, errors.New("only the problem author or an admin can do this")
+		}
+	}
+
+	return questionID, userID, nil
+}
+
+// requireRole checks that the current user has one of the given roles and returns their ID
+func (c *pcdGraphQLControllerImpl) requireRole(ctx context.Context, roles ...string) (uuid.UUID, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	role, err := database.GetUserRole(c.deps.DB, userID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	for _, r := range roles {
+		if r == role {
+			return userID, nil
+		}
+	}
+
+	return uuid.Nil, fmt.Errorf("requires the %s role", strings.Join(roles, " or "))
+}
+
+// isQuestionParticipant reports whether userID authored the question, is
+// assigned to review it or is an admin
+func (c *pcdGraphQLControllerImpl) isQuestionParticipant(userID uuid.UUID, questionID int) (bool, error) {
+	isAuthor, err := c.isQuestionAuthor(userID, questionID)
+	if err != nil || isAuthor {
+		return isAuthor, err
+	}
+
+	isReviewer, err := database.IsProblemReviewer(c.deps.DB, questionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reviewers: %w", err)
+	}
+	if isReviewer {
+		return true, nil
+	}
+
+	role, err := database.GetUserRole(c.deps.DB, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role == database.RoleAdmin, nil
+}
+
+// canViewQuestion reports whether the current user may see a question in the
+// given state. Published questions are public; the rest are only visible to participants.
+func (c *pcdGraphQLControllerImpl) canViewQuestion(ctx context.Context, questionID int, status string) (bool, error) {
+	if status == database.StatusPublished {
+		return true, nil
+	}
+
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return false, nil
+	}
+
+	return c.isQuestionParticipant(userID, questionID)
+}
+
+// requireQuestionVisible returns an error unless the current user may see the question
+func (c *pcdGraphQLControllerImpl) requireQuestionVisible(ctx context.Context, questionID int) error {
+	status, err := database.GetQuestionStatus(c.deps.DB, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("question not found")
+		}
+		return fmt.Errorf("failed to get question: %w", err)
+	}
+
+	visible, err := c.canViewQuestion(ctx, questionID, status)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return errors.New("question not found")
+	}
+
+	return nil
+}
+
+// problemParticipant reports whether the current user participates in a problem's review
+func (c *pcdGraphQLControllerImpl) problemParticipant(ctx context.Context, problem *model.Problem) (int, bool, error) {
+	questionID, err := strconv.Atoi(problem.ID)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid problem ID: %w", err)
+	}
+
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return questionID, false, nil
+	}
+
+	ok, err := c.isQuestionParticipant(userID, questionID)
+	return questionID, ok, err
+}
+
+func (c *pcdGraphQLControllerImpl) dbReviewCommentToModel(comment *database.ReviewComment) (*model.ReviewComment, error) {
+	author, err := database.GetUserByID(c.deps.DB, comment.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment author: %w", err)
+	}
+
+	return &model.ReviewComment{
+		ID:        strconv.FormatInt(comment.ID, 10),
+		Author:    dbUserToModel(author),
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func validateReviewComment(body string) error {
+	if body == "" {
+		return errors.New("comment cannot be empty")
+	}
+	if len(body) > maxReviewCommentLength {
+		return fmt.Errorf("comment must be at most %d characters", maxReviewCommentLength)
+	}
+	return nil
+}
+
+func statusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", "-")
+}
+
+func dbProblemsToModel(dbProblems []*database.Problem) []*model.Problem {
+	problems := make([]*model.Problem, 0, len(dbProblems))
+	for _, p := range dbProblems {
+		problems = append(problems, dbProblemToModel(p))
+	}
+	return problems
+}
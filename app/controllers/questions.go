@@ -0,0 +1,459 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	query "codestandoff/backend/graph/query/reports"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/similar"
+
+	"github.com/lib/pq"
+)
+
+// Upper bounds for author-supplied starter code
+const (
+	maxLanguageLength   = 32
+	maxStarterCodeBytes = 64 * 1024
+)
+
+// defaultSimilarQuestions is how many similar questions are returned when no limit is given
+const defaultSimilarQuestions = 5
+
+// randomPickAttempts bounds how often RandomQuestion re-counts when questions
+// are unpublished between counting and picking
+const randomPickAttempts = 3
+
+// QuestionBySlug returns a single question, or nil if no question has that slug
+func (c *pcdGraphQLControllerImpl) QuestionBySlug(ctx context.Context, slug string) (*model.Question, error) {
+	q, err := database.GetQuestionBySlug(c.deps.DB, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	visible, err := c.canViewQuestion(ctx, q.ID, q.Status)
+	if err != nil || !visible {
+		return nil, err
+	}
+
+	return dbQuestionToModel(q), nil
+}
+
+// QuestionExamples returns the worked examples of a question, taken from its sample test cases
+func (c *pcdGraphQLControllerImpl) QuestionExamples(ctx context.Context, question *model.Question) ([]*model.QuestionExample, error) {
+	questionID, err := strconv.Atoi(question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	samples, err := database.GetTestCases(c.deps.DB, questionID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get examples: %w", err)
+	}
+
+	examples := make([]*model.QuestionExample, len(samples))
+	for i, tc := range samples {
+		examples[i] = &model.QuestionExample{
+			Input:  tc.Input,
+			Output: tc.ExpectedOutput,
+		}
+		if tc.Explanation.Valid {
+			examples[i].Explanation = &tc.Explanation.String
+		}
+	}
+
+	return examples, nil
+}
+
+// QuestionStarterCode returns the starter code of a question for each language
+func (c *pcdGraphQLControllerImpl) QuestionStarterCode(ctx context.Context, question *model.Question) ([]*model.StarterCode, error) {
+	questionID, err := strconv.Atoi(question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	dbStarterCode, err := database.GetStarterCode(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get starter code: %w", err)
+	}
+
+	starterCode := make([]*model.StarterCode, len(dbStarterCode))
+	for i, sc := range dbStarterCode {
+		starterCode[i] = dbStarterCodeToModel(sc)
+	}
+
+	return starterCode, nil
+}
+
+// QuestionProblem returns the 1v1 problem backed by a question, if it is playable in matches
+func (c *pcdGraphQLControllerImpl) QuestionProblem(ctx context.Context, question *model.Question) (*model.Problem, error) {
+	return c.Problem(ctx, question.ID)
+}
+
+// SetStarterCode sets the starter code of a question for one language
+func (c *pcdGraphQLControllerImpl) SetStarterCode(ctx context.Context, questionID, language, code string) (*model.StarterCode, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" || len(language) > maxLanguageLength {
+		return nil, errors.New("invalid language")
+	}
+	if len(code) > maxStarterCodeBytes {
+		return nil, fmt.Errorf("starter code must be at most %d bytes", maxStarterCodeBytes)
+	}
+
+	sc, err := database.UpsertStarterCode(c.deps.DB, qid, language, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set starter code: %w", err)
+	}
+
+	return dbStarterCodeToModel(sc), nil
+}
+
+func dbQuestionToModel(q *database.Question) *model.Question {
+	return &model.Question{
+		ID:             strconv.Itoa(q.ID),
+		Title:          q.Title,
+		Slug:           q.Slug,
+		Description:    q.Description,
+		Difficulty:     q.Difficulty,
+		Topics:         q.Topics,
+		TestCaseCount:  q.TestCaseCount,
+		Constraints:    q.Constraints,
+		Hints:          q.Hints,
+		Rating:         q.Rating,
+		AttemptCount:   q.AttemptCount,
+		SolveCount:     q.SolveCount,
+		AcceptanceRate: acceptanceRate(q),
+		CreatedAt:      q.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      q.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// acceptanceRate is the fraction of users attempting a question who solved it
+func acceptanceRate(q *database.Question) float64 {
+	if q.AttemptCount == 0 {
+		return 0
+	}
+	return float64(q.SolveCount) / float64(q.AttemptCount)
+}
+
+func dbStarterCodeToModel(sc *database.StarterCode) *model.StarterCode {
+	return &model.StarterCode{
+		Language:  sc.Language,
+		Code:      sc.Code,
+		Generated: sc.Generated,
+		UpdatedAt: sc.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// QuestionsConnection returns a Relay connection over questions, paginated with
+// keyset cursors so pages stay stable while questions are added
+func (c *pcdGraphQLControllerImpl) QuestionsConnection(ctx context.Context, filter *model.GetQuestionsRequest, first *int, after *string, last *int, before *string) (*model.QuestionConnection, error) {
+	if first != nil && last != nil {
+		return nil, errors.New("first and last cannot be combined")
+	}
+	if after != nil && before != nil {
+		return nil, errors.New("after and before cannot be combined")
+	}
+
+	backward := last != nil || (before != nil && first == nil)
+	limit := 25
+	if first != nil {
+		limit = *first
+	}
+	if last != nil {
+		limit = *last
+	}
+	if limit <= 0 || limit > 100 {
+		return nil, errors.New("page size must be between 1 and 100")
+	}
+
+	var cursor *query.Cursor
+	cursorArg := after
+	if backward {
+		cursorArg = before
+	}
+	if cursorArg != nil {
+		var err error
+		cursor, err = query.DecodeCursor(*cursorArg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if filter == nil {
+		filter = &model.GetQuestionsRequest{}
+	}
+	sortBy := "id"
+	if filter.SortBy != nil && *filter.SortBy != "" {
+		sortBy = *filter.SortBy
+	}
+
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+	questionFilter, err := questionFilterFromInput(catalog, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	queryStr, args, err := query.GetQuestionsConnectionQueryWithArgs(questionFilter, filter.SortBy, filter.SortOrder, limit+1, cursor, backward) // +1 to check for another page
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.deps.DB.Query(queryStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []*model.QuestionEdge
+	for rows.Next() {
+		var q database.Question
+		var cursorValue string
+		if err := rows.Scan(questionScanDest(&q, &cursorValue)...); err != nil {
+			return nil, fmt.Errorf("failed to scan question: %w", err)
+		}
+		edges = append(edges, &model.QuestionEdge{
+			Cursor: query.EncodeCursor(query.Cursor{SortBy: sortBy, Value: cursorValue, ID: q.ID}),
+			Node:   dbQuestionToModel(&q),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating questions: %w", err)
+	}
+
+	hasMore := len(edges) > limit
+	if hasMore {
+		edges = edges[:limit]
+	}
+
+	pageInfo := &model.PageInfo{}
+	if backward {
+		for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j], edges[i]
+		}
+		pageInfo.HasPreviousPage = hasMore
+		pageInfo.HasNextPage = before != nil
+	} else {
+		pageInfo.HasNextPage = hasMore
+		pageInfo.HasPreviousPage = after != nil
+	}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	totalCount, err := c.countQuestions(questionFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.QuestionConnection{
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// RandomQuestion picks a question uniformly at random among those matching the
+// filter, or returns nil if none match. offset, limit and the sort fields are
+// ignored. With excludeSolved, questions the current user solved are left out.
+func (c *pcdGraphQLControllerImpl) RandomQuestion(ctx context.Context, filter *model.GetQuestionsRequest, excludeSolved *bool) (*model.Question, error) {
+	if filter == nil {
+		filter = &model.GetQuestionsRequest{}
+	}
+
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+	questionFilter, err := questionFilterFromInput(catalog, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if excludeSolved != nil && *excludeSolved {
+		userID, err := c.currentUserID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		questionFilter.ExcludeSolvedBy = userID.String()
+	}
+
+	for attempt := 0; attempt < randomPickAttempts; attempt++ {
+		count, err := c.countQuestions(questionFilter)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, nil
+		}
+
+		queryStr, args := query.GetQuestionAtQueryWithArgs(questionFilter, rand.IntN(count))
+		var q database.Question
+		err = c.deps.DB.QueryRow(queryStr, args...).Scan(questionScanDest(&q)...)
+		if err == sql.ErrNoRows {
+			// The matching set shrank since counting; count again
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to pick question: %w", err)
+		}
+		return dbQuestionToModel(&q), nil
+	}
+
+	return nil, nil
+}
+
+// SimilarQuestions returns the precomputed questions most similar to a question
+func (c *pcdGraphQLControllerImpl) SimilarQuestions(ctx context.Context, id string, limit *int) ([]*model.Question, error) {
+	questionID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	n := defaultSimilarQuestions
+	if limit != nil {
+		n = *limit
+	}
+	if n <= 0 || n > similar.MaxSimilar {
+		return nil, fmt.Errorf("limit must be between 1 and %d", similar.MaxSimilar)
+	}
+
+	questions, err := database.GetSimilarQuestions(c.deps.DB, questionID, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similar questions: %w", err)
+	}
+
+	result := make([]*model.Question, len(questions))
+	for i, q := range questions {
+		result[i] = dbQuestionToModel(q)
+	}
+	return result, nil
+}
+
+// countQuestions returns the number of questions matching the filter, ignoring pagination
+func (c *pcdGraphQLControllerImpl) countQuestions(filter query.QuestionFilter) (int, error) {
+	queryStr, args := query.GetQuestionsCountQueryWithArgs(filter)
+
+	var count int
+	if err := c.deps.DB.QueryRow(queryStr, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count questions: %w", err)
+	}
+
+	return count, nil
+}
+
+// questionFacets returns per-difficulty and per-topic question counts for the
+// filter. Topic counts are keyed by catalog slug and include child topics.
+func (c *pcdGraphQLControllerImpl) questionFacets(catalog *database.TopicCatalog, filter query.QuestionFilter) (*model.QuestionFacets, error) {
+	difficultyQuery, difficultyArgs := query.GetDifficultyFacetsQueryWithArgs(filter)
+	difficulties, err := c.facetCounts(difficultyQuery, difficultyArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count difficulties: %w", err)
+	}
+
+	var terms, slugs []string
+	for _, t := range catalog.Topics {
+		for _, term := range catalog.Terms(t) {
+			for _, ancestor := range catalog.Ancestors(t) {
+				terms = append(terms, term)
+				slugs = append(slugs, ancestor.Slug)
+			}
+		}
+	}
+
+	topicQuery, topicArgs := query.GetTopicFacetsQueryWithArgs(filter, terms, slugs)
+	topics, err := c.facetCounts(topicQuery, topicArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+
+	return &model.QuestionFacets{
+		Difficulties: difficulties,
+		Topics:       topics,
+	}, nil
+}
+
+// facetCounts runs a facet query returning (value, count) rows
+func (c *pcdGraphQLControllerImpl) facetCounts(queryStr string, args []interface{}) ([]*model.FacetCount, error) {
+	rows, err := c.deps.DB.Query(queryStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []*model.FacetCount{}
+	for rows.Next() {
+		f := &model.FacetCount{}
+		if err := rows.Scan(&f.Value, &f.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+
+	return facets, rows.Err()
+}
+
+// questionFilterFromInput extracts the list filters from a GetQuestionsRequest,
+// resolving topics through the catalog
+func questionFilterFromInput(catalog *database.TopicCatalog, input *model.GetQuestionsRequest) (query.QuestionFilter, error) {
+	topicTerms, err := resolveTopicTerms(catalog, input.Topics)
+	if err != nil {
+		return query.QuestionFilter{}, err
+	}
+
+	if input.MinRating != nil && input.MaxRating != nil && *input.MinRating > *input.MaxRating {
+		return query.QuestionFilter{}, errors.New("minRating cannot be greater than maxRating")
+	}
+
+	return query.QuestionFilter{
+		Search:     input.Search,
+		Difficulty: input.Difficulty,
+		TopicTerms: topicTerms,
+		MinRating:  input.MinRating,
+		MaxRating:  input.MaxRating,
+	}, nil
+}
+
+// questionScanDest returns the scan destinations for the query builder's
+// question columns, followed by extra
+func questionScanDest(q *database.Question, extra ...interface{}) []interface{} {
+	return append([]interface{}{
+		&q.ID,
+		&q.Title,
+		&q.Slug,
+		&q.Description,
+		&q.Difficulty,
+		pq.Array(&q.Topics),
+		&q.TestCaseCount,
+		pq.Array(&q.Constraints),
+		pq.Array(&q.Hints),
+		&q.Rating,
+		&q.AttemptCount,
+		&q.SolveCount,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	}, extra...)
+}
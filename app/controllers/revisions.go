@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/checker"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/signature"
+	"codestandoff/backend/internal/textdiff"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change in revision diffs
+const diffContextLines = 3
+
+// ProblemRevisions returns the revision history of a question, newest first,
+// each with its changes from the revision before it
+func (c *pcdGraphQLControllerImpl) ProblemRevisions(ctx context.Context, questionID string) ([]*model.ProblemRevision, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	revisions, err := database.GetProblemRevisions(c.deps.DB, qid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	history := make([]*model.ProblemRevision, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		var previous *database.ProblemRevision
+		if i > 0 {
+			previous = revisions[i-1]
+		}
+		history = append(history, dbRevisionToModel(revisions[i], previous))
+	}
+
+	return history, nil
+}
+
+// ProblemRevision returns a single revision, e.g. the one a match is pinned to
+func (c *pcdGraphQLControllerImpl) ProblemRevision(ctx context.Context, id string) (*model.ProblemRevision, error) {
+	revisionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid revision ID: %w", err)
+	}
+
+	revision, err := database.GetProblemRevisionByID(c.deps.DB, revisionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, revision.QuestionID); err != nil {
+		return nil, err
+	}
+
+	var previous *database.ProblemRevision
+	if revision.Revision > 1 {
+		previous, err = database.GetProblemRevisionByNumber(c.deps.DB, revision.QuestionID, revision.Revision-1)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get previous revision: %w", err)
+		}
+	}
+
+	return dbRevisionToModel(revision, previous), nil
+}
+
+// revisionChanges lists what changed from previous to r. Test case changes are
+// summarized without their contents, since hidden cases must stay hidden.
+func revisionChanges(previous, r *database.ProblemRevision) []*model.RevisionChange {
+	changes := []*model.RevisionChange{}
+	if previous == nil {
+		return changes
+	}
+
+	value := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, &model.RevisionChange{Field: field, Summary: fmt.Sprintf("%q -> %q", from, to)})
+		}
+	}
+	text := func(field, from, to string) {
+		if from == to {
+			return
+		}
+		inserted, deleted := textdiff.Stats(from, to)
+		diff := textdiff.Unified(from, to, diffContextLines)
+		changes = append(changes, &model.RevisionChange{
+			Field:   field,
+			Summary: fmt.Sprintf("+%d -%d lines", inserted, deleted),
+			Diff:    &diff,
+		})
+	}
+
+	value("title", previous.Title, r.Title)
+	value("difficulty", previous.Difficulty, r.Difficulty)
+	text("description", previous.Description, r.Description)
+	text("constraints", joinLines(previous.Constraints), joinLines(r.Constraints))
+	text("hints", joinLines(previous.Hints), joinLines(r.Hints))
+	value("timeLimitMs", nullIntString(previous.TimeLimitMs), nullIntString(r.TimeLimitMs))
+	value("memoryLimitMb", nullIntString(previous.MemoryLimitMb), nullIntString(r.MemoryLimitMb))
+	value("signature", signatureString(previous.Signature), signatureString(r.Signature))
+	value("checker", checkerString(previous.Checker), checkerString(r.Checker))
+
+	for i := 0; i < len(previous.TestCases) || i < len(r.TestCases); i++ {
+		field := fmt.Sprintf("testCases[%d]", i+1)
+		switch {
+		case i >= len(previous.TestCases):
+			changes = append(changes, &model.RevisionChange{Field: field, Summary: "added"})
+		case i >= len(r.TestCases):
+			changes = append(changes, &model.RevisionChange{Field: field, Summary: "removed"})
+		default:
+			if parts := revisionTestCaseChanges(previous.TestCases[i], r.TestCases[i]); len(parts) > 0 {
+				changes = append(changes, &model.RevisionChange{Field: field, Summary: strings.Join(parts, ", ") + " changed"})
+			}
+		}
+	}
+
+	return changes
+}
+
+func revisionTestCaseChanges(a, b database.RevisionTestCase) []string {
+	var parts []string
+	if a.Input != b.Input {
+		parts = append(parts, "input")
+	}
+	if a.ExpectedOutput != b.ExpectedOutput {
+		parts = append(parts, "expected output")
+	}
+	if a.IsSample != b.IsSample {
+		parts = append(parts, "sample flag")
+	}
+	if !reflect.DeepEqual(a.Explanation, b.Explanation) {
+		parts = append(parts, "explanation")
+	}
+	if !reflect.DeepEqual(a.TimeLimitMs, b.TimeLimitMs) || !reflect.DeepEqual(a.MemoryLimitMb, b.MemoryLimitMb) {
+		parts = append(parts, "limits")
+	}
+	return parts
+}
+
+func dbRevisionToModel(r, previous *database.ProblemRevision) *model.ProblemRevision {
+	revision := &model.ProblemRevision{
+		ID:            strconv.FormatInt(r.ID, 10),
+		QuestionID:    strconv.Itoa(r.QuestionID),
+		Revision:      r.Revision,
+		Title:         r.Title,
+		Description:   r.Description,
+		Difficulty:    r.Difficulty,
+		Constraints:   r.Constraints,
+		Hints:         r.Hints,
+		TestCaseCount: len(r.TestCases),
+		Examples:      []*model.QuestionExample{},
+		CreatedAt:     r.CreatedAt.Format(time.RFC3339),
+		Changes:       revisionChanges(previous, r),
+	}
+	if revision.Constraints == nil {
+		revision.Constraints = []string{}
+	}
+	if revision.Hints == nil {
+		revision.Hints = []string{}
+	}
+	if r.TimeLimitMs.Valid {
+		v := int(r.TimeLimitMs.Int64)
+		revision.TimeLimitMs = &v
+	}
+	if r.MemoryLimitMb.Valid {
+		v := int(r.MemoryLimitMb.Int64)
+		revision.MemoryLimitMb = &v
+	}
+	if r.Signature != nil {
+		if spec, err := signature.Parse(r.Signature); err == nil {
+			revision.Signature = specToModel(spec)
+		}
+	}
+	revision.Checker = &model.Checker{Kind: model.CheckerKindExact}
+	if spec, err := checker.Parse(r.Checker); err == nil {
+		revision.Checker = checkerToModel(spec)
+	}
+	for _, tc := range r.TestCases {
+		if tc.IsSample {
+			revision.Examples = append(revision.Examples, &model.QuestionExample{
+				Input:       tc.Input,
+				Output:      tc.ExpectedOutput,
+				Explanation: tc.Explanation,
+			})
+		}
+	}
+	return revision
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func signatureString(data []byte) string {
+	if data == nil {
+		return "none"
+	}
+	spec, err := signature.Parse(data)
+	if err != nil {
+		return string(data)
+	}
+	return spec.String()
+}
+
+func checkerString(data []byte) string {
+	spec, err := checker.Parse(data)
+	if err != nil {
+		return string(data)
+	}
+	return spec.String()
+}
+
+func nullIntString(v sql.NullInt64) string {
+	if !v.Valid {
+		return "none"
+	}
+	return strconv.FormatInt(v.Int64, 10)
+}
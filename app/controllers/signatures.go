@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/signature"
+)
+
+// QuestionSignature returns the function signature of a question, or nil if it has none
+func (c *pcdGraphQLControllerImpl) QuestionSignature(ctx context.Context, question *model.Question) (*model.FunctionSignature, error) {
+	questionID, err := strconv.Atoi(question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	data, err := database.GetQuestionSignature(c.deps.DB, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	spec, err := signature.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return specToModel(spec), nil
+}
+
+// SetFunctionSignature sets the function signature of a question and
+// regenerates its generated starter code for every supported language.
+// Starter code the author wrote by hand is left alone.
+func (c *pcdGraphQLControllerImpl) SetFunctionSignature(ctx context.Context, questionID string, input model.FunctionSignatureInput) ([]*model.StarterCode, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	spec := &signature.Spec{
+		Function: strings.TrimSpace(input.Function),
+		Params:   make([]signature.Param, len(input.Params)),
+		Returns:  strings.TrimSpace(input.Returns),
+	}
+	for i, param := range input.Params {
+		spec.Params[i] = signature.Param{Name: strings.TrimSpace(param.Name), Type: strings.TrimSpace(param.Type)}
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	starterCode := map[string]string{}
+	for _, language := range signature.Languages() {
+		code, err := spec.Starter(language)
+		if err != nil {
+			return nil, err
+		}
+		starterCode[language] = code
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.SetQuestionSignature(c.deps.DB, qid, data, starterCode); err != nil {
+		return nil, fmt.Errorf("failed to set signature: %w", err)
+	}
+
+	return c.QuestionStarterCode(ctx, &model.Question{ID: questionID})
+}
+
+func specToModel(s *signature.Spec) *model.FunctionSignature {
+	sig := &model.FunctionSignature{
+		Function: s.Function,
+		Params:   make([]*model.SignatureParam, len(s.Params)),
+		Returns:  s.Returns,
+	}
+	for i, param := range s.Params {
+		sig.Params[i] = &model.SignatureParam{Name: param.Name, Type: param.Type}
+	}
+	return sig
+}
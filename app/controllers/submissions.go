@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/grader"
+	"codestandoff/backend/internal/judge"
+	"codestandoff/backend/internal/signature"
+
+	"github.com/google/uuid"
+)
+
+// Limits on submissions
+const (
+	maxSourceBytes            = 64 * 1024
+	maxRunInputBytes          = 64 * 1024
+	defaultSubmissionPageSize = 20
+	maxSubmissionPageSize     = 100
+)
+
+// judgeThroughputWindow is how far back judged submissions count towards the judge queue's throughput
+const judgeThroughputWindow = 10 * time.Minute
+
+// SubmitCode queues the current user's code for judging against the question's
+// current revision, or against the match's revision when matchID is set
+func (c *pcdGraphQLControllerImpl) SubmitCode(ctx context.Context, questionID string, language string, source string, matchID *string) (*model.Submission, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	language, err = c.checkCode(language, source)
+	if err != nil {
+		return nil, err
+	}
+	match, err := c.submissionMatch(userID, qid, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := database.CreateSubmission(c.deps.DB, userID, qid, match, language, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit: %w", err)
+	}
+
+	return dbSubmissionToModel(s), nil
+}
+
+// RunCode runs the current user's code on input, or on the sample tests when
+// input is nil, against the revision a submission would be judged against.
+// Nothing is recorded but the time of the run, for rate limiting.
+func (c *pcdGraphQLControllerImpl) RunCode(ctx context.Context, questionID string, language string, source string, input *string, matchID *string) (*model.CodeRun, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.deps.Runner == nil {
+		return nil, errors.New("running code is not available right now")
+	}
+
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	language, err = c.checkCode(language, source)
+	if err != nil {
+		return nil, err
+	}
+	if input != nil && len(*input) > maxRunInputBytes {
+		return nil, fmt.Errorf("input exceeds %d bytes", maxRunInputBytes)
+	}
+	match, err := c.submissionMatch(userID, qid, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.deps.Runner.Config()
+	if err := database.RecordCodeRun(c.deps.DB, userID, config.RateLimit, config.RateWindow); err != nil {
+		return nil, err
+	}
+
+	revision, err := database.PinProblemRevision(c.deps.DB, qid, match)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.deps.Runner.Run(ctx, revision, language, source, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return runResultToModel(result), nil
+}
+
+// Submission returns one of the current user's submissions, or nil if it does not exist
+func (c *pcdGraphQLControllerImpl) Submission(ctx context.Context, id string) (*model.Submission, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	submissionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid submission ID: %w", err)
+	}
+
+	s, err := database.GetSubmissionByID(c.deps.DB, submissionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get submission: %w", err)
+	}
+	// Other users' submissions are reported as missing
+	if s.UserID != userID {
+		return nil, nil
+	}
+
+	return dbSubmissionToModel(s), nil
+}
+
+// SubmissionTestResults returns the results of a submission's test cases.
+// Hidden test cases are redacted to their verdict and timing.
+func (c *pcdGraphQLControllerImpl) SubmissionTestResults(ctx context.Context, submission *model.Submission) ([]*model.TestResult, error) {
+	submissionID, err := uuid.Parse(submission.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid submission ID: %w", err)
+	}
+
+	dbResults, err := database.GetSubmissionTestResults(c.deps.DB, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test results: %w", err)
+	}
+
+	results := make([]*model.TestResult, len(dbResults))
+	for i, t := range dbResults {
+		results[i] = dbTestResultToModel(t)
+	}
+	return results, nil
+}
+
+// Submissions returns the current user's submissions on a question, newest first
+func (c *pcdGraphQLControllerImpl) Submissions(ctx context.Context, questionID string, limit *int, offset *int) ([]*model.Submission, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	n := defaultSubmissionPageSize
+	if limit != nil {
+		n = *limit
+	}
+	if n <= 0 || n > maxSubmissionPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSubmissionPageSize)
+	}
+	skip := 0
+	if offset != nil && *offset > 0 {
+		skip = *offset
+	}
+
+	dbSubmissions, err := database.GetUserSubmissions(c.deps.DB, userID, qid, n, skip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submissions: %w", err)
+	}
+
+	submissions := make([]*model.Submission, len(dbSubmissions))
+	for i, s := range dbSubmissions {
+		submissions[i] = dbSubmissionToModel(s)
+	}
+	return submissions, nil
+}
+
+// JudgeQueue returns the judge queue's depth, and estimates the wait of a
+// submission made now from how many submissions were judged recently
+func (c *pcdGraphQLControllerImpl) JudgeQueue(ctx context.Context) (*model.JudgeQueue, error) {
+	stats, err := database.GetJudgeQueueStats(c.deps.DB, judgeThroughputWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &model.JudgeQueue{Queued: stats.Queued, Running: stats.Running}
+	switch {
+	case stats.Queued == 0:
+		wait := 0
+		queue.EstimatedWaitSeconds = &wait
+	case stats.Finished > 0:
+		perSecond := float64(stats.Finished) / judgeThroughputWindow.Seconds()
+		wait := int(math.Ceil(float64(stats.Queued) / perSecond))
+		queue.EstimatedWaitSeconds = &wait
+	}
+	return queue, nil
+}
+
+// checkCode validates code to submit or run, and returns its language's name
+func (c *pcdGraphQLControllerImpl) checkCode(language, source string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if l, ok := c.deps.Languages.Language(language); !ok || !l.Enabled {
+		return "", fmt.Errorf("unsupported language %q", language)
+	}
+	if strings.TrimSpace(source) == "" {
+		return "", errors.New("source cannot be empty")
+	}
+	if len(source) > maxSourceBytes {
+		return "", fmt.Errorf("source exceeds %d bytes", maxSourceBytes)
+	}
+	return language, nil
+}
+
+// submissionMatch returns the match code is submitted in, if matchID is set,
+// after checking that the user plays in it and that it is on the question
+func (c *pcdGraphQLControllerImpl) submissionMatch(userID uuid.UUID, questionID int, matchID *string) (uuid.NullUUID, error) {
+	if matchID == nil {
+		return uuid.NullUUID{}, nil
+	}
+	m, err := c.requireMatchPlayer(userID, *matchID)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if m.QuestionID != questionID {
+		return uuid.NullUUID{}, errors.New("question is not part of the match")
+	}
+	return uuid.NullUUID{UUID: m.ID, Valid: true}, nil
+}
+
+// requireMatchPlayer returns a match the user plays in
+func (c *pcdGraphQLControllerImpl) requireMatchPlayer(userID uuid.UUID, id string) (*database.Match, error) {
+	matchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match ID: %w", err)
+	}
+
+	m, err := database.GetMatchByID(c.deps.DB, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("match not found")
+		}
+		return nil, fmt.Errorf("failed to get match: %w", err)
+	}
+	if m.Player1ID != userID && (!m.Player2ID.Valid || m.Player2ID.UUID != userID) {
+		return nil, errors.New("you are not playing in this match")
+	}
+
+	return m, nil
+}
+
+// Languages returns the enabled languages of the registry
+func (c *pcdGraphQLControllerImpl) Languages(ctx context.Context) ([]*model.Language, error) {
+	enabled := c.deps.Languages.Enabled()
+	languages := make([]*model.Language, len(enabled))
+	for i, l := range enabled {
+		languages[i] = languageToModel(l)
+	}
+	return languages, nil
+}
+
+func languageToModel(l judge.Language) *model.Language {
+	language := &model.Language{
+		Name:             l.Name,
+		Version:          l.Version,
+		SourceFile:       l.SourceFile,
+		RunCommand:       strings.Join(l.Run, " "),
+		TimeMultiplier:   l.TimeMultiplier,
+		MemoryMultiplier: l.MemoryMultiplier,
+	}
+	if len(l.Compile) > 0 {
+		compile := strings.Join(l.Compile, " ")
+		language.CompileCommand = &compile
+	}
+	for _, name := range signature.Languages() {
+		if name == l.Name {
+			language.SupportsFunctionSignatures = true
+		}
+	}
+	return language
+}
+
+func runResultToModel(r *grader.RunResult) *model.CodeRun {
+	run := &model.CodeRun{Runs: make([]*model.CodeRunResult, len(r.Runs))}
+	if r.CompileError != "" {
+		run.CompileError = &r.CompileError
+	}
+	for i, c := range r.Runs {
+		res := &model.CodeRunResult{
+			Input:          c.Input,
+			ExpectedOutput: c.ExpectedOutput,
+			Status:         runStatusToModel(c.Result.Status),
+			Stdout:         strings.ToValidUTF8(string(c.Result.Stdout), "\uFFFD"),
+			Stderr:         strings.ToValidUTF8(string(c.Result.Stderr), "\uFFFD"),
+			TimeMs:         int(c.Result.Usage.CPUTime.Milliseconds()),
+			WallTimeMs:     int(c.Result.Usage.WallTime.Milliseconds()),
+			MemoryKb:       int(c.Result.Usage.MemoryBytes / 1024),
+		}
+		if c.ExpectedOutput != nil {
+			passed := c.Passed
+			res.Passed = &passed
+		}
+		if c.CheckerMessage != "" {
+			res.CheckerMessage = &c.CheckerMessage
+		}
+		if c.Result.Signal != "" {
+			res.Signal = &c.Result.Signal
+		} else {
+			exitCode := c.Result.ExitCode
+			res.ExitCode = &exitCode
+		}
+		run.Runs[i] = res
+	}
+	return run
+}
+
+func runStatusToModel(status judge.Status) model.RunStatus {
+	switch status {
+	case judge.StatusTimeLimit:
+		return model.RunStatusTimeLimitExceeded
+	case judge.StatusMemoryLimit:
+		return model.RunStatusMemoryLimitExceeded
+	case judge.StatusOutputLimit:
+		return model.RunStatusOutputLimitExceeded
+	case judge.StatusRuntimeError:
+		return model.RunStatusRuntimeError
+	}
+	return model.RunStatusOk
+}
+
+func dbTestResultToModel(t *database.SubmissionTestResult) *model.TestResult {
+	result := &model.TestResult{
+		Test:       t.Position,
+		IsSample:   t.IsSample,
+		Verdict:    model.TestVerdict(strings.ToUpper(t.Status)),
+		TimeMs:     t.TimeMs,
+		WallTimeMs: t.WallTimeMs,
+	}
+	if !t.IsSample {
+		return result
+	}
+	memoryKb := t.MemoryKb
+	result.MemoryKb = &memoryKb
+	if t.Message != "" {
+		message := strings.ToValidUTF8(t.Message, "\uFFFD")
+		result.Message = &message
+	}
+	if t.Diff != "" {
+		diff := strings.ToValidUTF8(t.Diff, "\uFFFD")
+		result.Diff = &diff
+	}
+	return result
+}
+
+func dbSubmissionToModel(s *database.Submission) *model.Submission {
+	submission := &model.Submission{
+		ID:         s.ID.String(),
+		QuestionID: strconv.Itoa(s.QuestionID),
+		RevisionID: strconv.FormatInt(s.RevisionID, 10),
+		Language:   s.Language,
+		Source:     s.Source,
+		Status:     model.SubmissionStatus(strings.ToUpper(s.Status)),
+		CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+	}
+	if s.MatchID.Valid {
+		matchID := s.MatchID.UUID.String()
+		submission.MatchID = &matchID
+	}
+	if s.Message.Valid {
+		submission.Message = &s.Message.String
+	}
+	if s.TestsPassed.Valid {
+		n := int(s.TestsPassed.Int64)
+		submission.TestsPassed = &n
+	}
+	if s.TestsTotal.Valid {
+		n := int(s.TestsTotal.Int64)
+		submission.TestsTotal = &n
+	}
+	if s.TimeMs.Valid {
+		n := int(s.TimeMs.Int64)
+		submission.TimeMs = &n
+	}
+	if s.MemoryKb.Valid {
+		n := int(s.MemoryKb.Int64)
+		submission.MemoryKb = &n
+	}
+	if s.FinishedAt.Valid {
+		finishedAt := s.FinishedAt.Time.Format(time.RFC3339)
+		submission.FinishedAt = &finishedAt
+	}
+	return submission
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+)
+
+// TestCases returns the test cases of a question. The author, reviewers and
+// admins see every case; everyone else only sees the sample cases.
+func (c *pcdGraphQLControllerImpl) TestCases(ctx context.Context, questionID string) ([]*model.TestCase, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+	if err := c.requireQuestionVisible(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	samplesOnly := true
+	if userID, err := c.currentUserID(ctx); err == nil {
+		isParticipant, err := c.isQuestionParticipant(userID, qid)
+		if err != nil {
+			return nil, err
+		}
+		samplesOnly = !isParticipant
+	}
+
+	dbTestCases, err := database.GetTestCases(c.deps.DB, qid, samplesOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test cases: %w", err)
+	}
+
+	return dbTestCasesToModel(dbTestCases), nil
+}
+
+// AddTestCase appends a test case to a question
+func (c *pcdGraphQLControllerImpl) AddTestCase(ctx context.Context, questionID string, input model.TestCaseInput) (*model.TestCase, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+	if err := validateLimits(input.TimeLimitMs, input.MemoryLimitMb); err != nil {
+		return nil, err
+	}
+
+	params := database.TestCaseParams{
+		Input:          input.Input,
+		ExpectedOutput: input.ExpectedOutput,
+		TimeLimitMs:    input.TimeLimitMs,
+		MemoryLimitMb:  input.MemoryLimitMb,
+		Explanation:    input.Explanation,
+	}
+	if input.IsSample != nil {
+		params.IsSample = *input.IsSample
+	}
+
+	tc, err := database.CreateTestCase(c.deps.DB, qid, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add test case: %w", err)
+	}
+
+	return dbTestCaseToModel(tc), nil
+}
+
+// UpdateTestCase edits a test case
+func (c *pcdGraphQLControllerImpl) UpdateTestCase(ctx context.Context, id string, input model.UpdateTestCaseInput) (*model.TestCase, error) {
+	tc, err := c.requireTestCaseAuthor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLimits(input.TimeLimitMs, input.MemoryLimitMb); err != nil {
+		return nil, err
+	}
+
+	updated, err := database.UpdateTestCase(c.deps.DB, tc.ID, database.UpdateTestCaseParams{
+		Input:          input.Input,
+		ExpectedOutput: input.ExpectedOutput,
+		IsSample:       input.IsSample,
+		TimeLimitMs:    input.TimeLimitMs,
+		MemoryLimitMb:  input.MemoryLimitMb,
+		Explanation:    input.Explanation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update test case: %w", err)
+	}
+
+	return dbTestCaseToModel(updated), nil
+}
+
+// ReorderTestCases sets the order of a question's test cases
+func (c *pcdGraphQLControllerImpl) ReorderTestCases(ctx context.Context, questionID string, testCaseIDs []string) ([]*model.TestCase, error) {
+	qid, err := strconv.Atoi(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid question ID: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, qid); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(testCaseIDs))
+	for i, id := range testCaseIDs {
+		ids[i], err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test case ID %q: %w", id, err)
+		}
+	}
+
+	dbTestCases, err := database.ReorderTestCases(c.deps.DB, qid, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder test cases: %w", err)
+	}
+
+	return dbTestCasesToModel(dbTestCases), nil
+}
+
+// DeleteTestCase removes a test case
+func (c *pcdGraphQLControllerImpl) DeleteTestCase(ctx context.Context, id string) (bool, error) {
+	tc, err := c.requireTestCaseAuthor(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := database.DeleteTestCase(c.deps.DB, tc.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete test case: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// requireTestCaseAuthor loads a test case and checks that the current user authored its question
+func (c *pcdGraphQLControllerImpl) requireTestCaseAuthor(ctx context.Context, id string) (*database.TestCase, error) {
+	testCaseID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid test case ID: %w", err)
+	}
+
+	tc, err := database.GetTestCaseByID(c.deps.DB, testCaseID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("test case not found")
+		}
+		return nil, fmt.Errorf("failed to get test case: %w", err)
+	}
+
+	if err := c.requireQuestionAuthor(ctx, tc.QuestionID); err != nil {
+		return nil, err
+	}
+
+	return tc, nil
+}
+
+func dbTestCasesToModel(dbTestCases []*database.TestCase) []*model.TestCase {
+	testCases := make([]*model.TestCase, len(dbTestCases))
+	for i, tc := range dbTestCases {
+		testCases[i] = dbTestCaseToModel(tc)
+	}
+	return testCases
+}
+
+func dbTestCaseToModel(tc *database.TestCase) *model.TestCase {
+	testCase := &model.TestCase{
+		ID:             strconv.FormatInt(tc.ID, 10),
+		QuestionID:     strconv.Itoa(tc.QuestionID),
+		Position:       tc.Position,
+		Input:          tc.Input,
+		ExpectedOutput: tc.ExpectedOutput,
+		IsSample:       tc.IsSample,
+	}
+
+	if tc.TimeLimitMs.Valid {
+		v := int(tc.TimeLimitMs.Int64)
+		testCase.TimeLimitMs = &v
+	}
+	if tc.MemoryLimitMb.Valid {
+		v := int(tc.MemoryLimitMb.Int64)
+		testCase.MemoryLimitMb = &v
+	}
+	if tc.Explanation.Valid {
+		testCase.Explanation = &tc.Explanation.String
+	}
+
+	return testCase
+}
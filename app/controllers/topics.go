@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codestandoff/backend/graph/model"
+	"codestandoff/backend/internal/database"
+)
+
+// Topics returns the topic catalog as a tree of root topics
+func (c *pcdGraphQLControllerImpl) Topics(ctx context.Context) ([]*model.Topic, error) {
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	roots := []*model.Topic{}
+	for _, t := range catalog.Topics {
+		if _, hasParent := parentTopic(catalog, t); !hasParent {
+			roots = append(roots, topicTreeToModel(catalog, t))
+		}
+	}
+
+	return roots, nil
+}
+
+// CreateTopic adds a topic to the catalog; only admins may do so
+func (c *pcdGraphQLControllerImpl) CreateTopic(ctx context.Context, input model.CreateTopicInput) (*model.Topic, error) {
+	if _, err := c.requireRole(ctx, database.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("topic name is required")
+	}
+	slug := database.Slugify(name)
+	if input.Slug != nil {
+		slug = database.Slugify(*input.Slug)
+	}
+
+	aliases := make([]string, len(input.Aliases))
+	seen := map[string]bool{}
+	for i, alias := range input.Aliases {
+		aliases[i] = strings.TrimSpace(alias)
+		if aliases[i] == "" {
+			return nil, errors.New("aliases cannot be blank")
+		}
+		if seen[strings.ToLower(aliases[i])] {
+			return nil, fmt.Errorf("alias %q is given twice", aliases[i])
+		}
+		seen[strings.ToLower(aliases[i])] = true
+	}
+
+	for _, term := range append([]string{slug, name}, aliases...) {
+		if _, exists := catalog.Lookup(term); exists {
+			return nil, fmt.Errorf("topic %q already exists", term)
+		}
+	}
+
+	var parentID *int
+	if input.Parent != nil {
+		parent, ok := catalog.Lookup(*input.Parent)
+		if !ok {
+			return nil, fmt.Errorf("unknown parent topic %q", *input.Parent)
+		}
+		parentID = &parent.ID
+	}
+
+	t, err := database.CreateTopic(c.deps.DB, slug, name, parentID, aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create topic: %w", err)
+	}
+
+	return topicToModel(t, nil), nil
+}
+
+// AddTopicAlias registers another spelling for an existing topic; only admins may do so
+func (c *pcdGraphQLControllerImpl) AddTopicAlias(ctx context.Context, topic, alias string) (*model.Topic, error) {
+	if _, err := c.requireRole(ctx, database.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+
+	t, ok := catalog.Lookup(topic)
+	if !ok {
+		return nil, fmt.Errorf("unknown topic %q", topic)
+	}
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, errors.New("alias is required")
+	}
+	if _, exists := catalog.Lookup(alias); exists {
+		return nil, fmt.Errorf("%q already refers to a topic", alias)
+	}
+
+	if err := database.AddTopicAlias(c.deps.DB, t.ID, alias); err != nil {
+		return nil, fmt.Errorf("failed to add alias: %w", err)
+	}
+	t.Aliases = append(t.Aliases, strings.ToLower(alias))
+
+	return topicTreeToModel(catalog, t), nil
+}
+
+// resolveTopicTerms turns topic filter values into the lower-case spellings to
+// match on questions. Parent topics expand to their children; unknown topics are rejected.
+func resolveTopicTerms(catalog *database.TopicCatalog, topics []string) ([]string, error) {
+	var terms []string
+	seen := map[int]bool{}
+	for _, value := range topics {
+		t, ok := catalog.Lookup(value)
+		if !ok {
+			return nil, fmt.Errorf("unknown topic %q", value)
+		}
+		for _, d := range catalog.Descendants(t) {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				terms = append(terms, catalog.Terms(d)...)
+			}
+		}
+	}
+	return terms, nil
+}
+
+// canonicalTopics maps topic values to their catalog display names so
+// questions store one spelling per topic. Unknown topics are rejected.
+func (c *pcdGraphQLControllerImpl) canonicalTopics(topics []string) ([]string, error) {
+	if len(topics) == 0 {
+		return topics, nil
+	}
+
+	catalog, err := database.LoadTopicCatalog(c.deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topics: %w", err)
+	}
+
+	names := []string{}
+	seen := map[int]bool{}
+	for _, value := range topics {
+		t, ok := catalog.Lookup(value)
+		if !ok {
+			return nil, fmt.Errorf("unknown topic %q", value)
+		}
+		if !seen[t.ID] {
+			seen[t.ID] = true
+			names = append(names, t.Name)
+		}
+	}
+
+	return names, nil
+}
+
+func parentTopic(catalog *database.TopicCatalog, t *database.Topic) (*database.Topic, bool) {
+	if !t.ParentID.Valid {
+		return nil, false
+	}
+	return catalog.ByID(int(t.ParentID.Int64))
+}
+
+func topicTreeToModel(catalog *database.TopicCatalog, t *database.Topic) *model.Topic {
+	var children []*model.Topic
+	for _, child := range catalog.Children(t) {
+		children = append(children, topicTreeToModel(catalog, child))
+	}
+	return topicToModel(t, children)
+}
+
+func topicToModel(t *database.Topic, children []*model.Topic) *model.Topic {
+	topic := &model.Topic{
+		ID:       strconv.Itoa(t.ID),
+		Slug:     t.Slug,
+		Name:     t.Name,
+		Aliases:  t.Aliases,
+		Children: children,
+	}
+	if topic.Aliases == nil {
+		topic.Aliases = []string{}
+	}
+	if topic.Children == nil {
+		topic.Children = []*model.Topic{}
+	}
+	if t.ParentID.Valid {
+		parentID := strconv.FormatInt(t.ParentID.Int64, 10)
+		topic.ParentID = &parentID
+	}
+	return topic
+}
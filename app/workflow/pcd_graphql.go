@@ -12,6 +12,45 @@ import (
 type PCDGraphQLServiceServer interface {
 	// Training
 	GetQuestions(ctx context.Context, input model.GetQuestionsRequest) (*model.GetQuestionsResponse, error)
+	QuestionsConnection(ctx context.Context, filter *model.GetQuestionsRequest, first *int, after *string, last *int, before *string) (*model.QuestionConnection, error)
+	RandomQuestion(ctx context.Context, filter *model.GetQuestionsRequest, excludeSolved *bool) (*model.Question, error)
+	SimilarQuestions(ctx context.Context, id string, limit *int) ([]*model.Question, error)
+	QuestionBySlug(ctx context.Context, slug string) (*model.Question, error)
+	QuestionExamples(ctx context.Context, question *model.Question) ([]*model.QuestionExample, error)
+	QuestionStarterCode(ctx context.Context, question *model.Question) ([]*model.StarterCode, error)
+	QuestionProblem(ctx context.Context, question *model.Question) (*model.Problem, error)
+	SetStarterCode(ctx context.Context, questionID, language, code string) (*model.StarterCode, error)
+
+	// Statement rendering
+	QuestionDescriptionHTML(ctx context.Context, question *model.Question) (string, error)
+	ProblemDescriptionHTML(ctx context.Context, problem *model.Problem) (string, error)
+	ProblemRevisionDescriptionHTML(ctx context.Context, revision *model.ProblemRevision) (string, error)
+
+	// Function signatures
+	QuestionSignature(ctx context.Context, question *model.Question) (*model.FunctionSignature, error)
+	SetFunctionSignature(ctx context.Context, questionID string, input model.FunctionSignatureInput) ([]*model.StarterCode, error)
+
+	// Checkers
+	QuestionChecker(ctx context.Context, question *model.Question) (*model.Checker, error)
+	SetChecker(ctx context.Context, questionID string, input model.CheckerInput) (*model.Checker, error)
+
+	// Editorials
+	QuestionEditorial(ctx context.Context, question *model.Question) (*model.QuestionEditorial, error)
+	GiveUpQuestion(ctx context.Context, questionID string) (*model.QuestionEditorial, error)
+	SetEditorial(ctx context.Context, questionID string, input model.EditorialInput) (*model.Editorial, error)
+	SetReferenceSolution(ctx context.Context, questionID, language, code string) (*model.ReferenceSolution, error)
+
+	// Problem lists
+	ProblemLists(ctx context.Context) ([]*model.ProblemList, error)
+	FollowedProblemLists(ctx context.Context) ([]*model.ProblemList, error)
+	ProblemList(ctx context.Context, slug string) (*model.ProblemList, error)
+	CreateProblemList(ctx context.Context, input model.CreateProblemListInput) (*model.ProblemList, error)
+	UpdateProblemList(ctx context.Context, id string, input model.UpdateProblemListInput) (*model.ProblemList, error)
+	DeleteProblemList(ctx context.Context, id string) (bool, error)
+	FollowProblemList(ctx context.Context, id string) (*model.ProblemList, error)
+	UnfollowProblemList(ctx context.Context, id string) (*model.ProblemList, error)
+	ProblemListSections(ctx context.Context, list *model.ProblemList) ([]*model.ProblemListSection, error)
+	ProblemListProgress(ctx context.Context, list *model.ProblemList) (*model.ProblemListProgress, error)
 
 	// Auth
 	Me(ctx context.Context) (*model.User, error)
@@ -21,13 +60,55 @@ type PCDGraphQLServiceServer interface {
 	Login(ctx context.Context, email, password string) (*model.AuthPayload, error)
 	Logout(ctx context.Context) (bool, error)
 
-	// Problems & Matches (placeholders)
+	// Revisions
+	ProblemRevisions(ctx context.Context, questionID string) ([]*model.ProblemRevision, error)
+	ProblemRevision(ctx context.Context, id string) (*model.ProblemRevision, error)
+
+	// Topics
+	Topics(ctx context.Context) ([]*model.Topic, error)
+	CreateTopic(ctx context.Context, input model.CreateTopicInput) (*model.Topic, error)
+	AddTopicAlias(ctx context.Context, topic, alias string) (*model.Topic, error)
+
+	// Test cases
+	TestCases(ctx context.Context, questionID string) ([]*model.TestCase, error)
+	AddTestCase(ctx context.Context, questionID string, input model.TestCaseInput) (*model.TestCase, error)
+	UpdateTestCase(ctx context.Context, id string, input model.UpdateTestCaseInput) (*model.TestCase, error)
+	ReorderTestCases(ctx context.Context, questionID string, testCaseIDs []string) ([]*model.TestCase, error)
+	DeleteTestCase(ctx context.Context, id string) (bool, error)
+
+	// Problems
 	Problems(ctx context.Context) ([]*model.Problem, error)
 	Problem(ctx context.Context, id string) (*model.Problem, error)
+	CreateProblem(ctx context.Context, title, description, difficulty string, topics []string, timeLimitMs, memoryLimitMb *int) (*model.Problem, error)
+	UpdateProblem(ctx context.Context, id string, input model.UpdateProblemInput) (*model.Problem, error)
+	DeleteProblem(ctx context.Context, id string) (bool, error)
+
+	// Matches
 	Matches(ctx context.Context) ([]*model.Match, error)
 	Match(ctx context.Context, id string) (*model.Match, error)
-	CreateProblem(ctx context.Context, title, description, difficulty string) (*model.Problem, error)
 	CreateMatch(ctx context.Context, problemID string) (*model.Match, error)
+
+	// Submissions
+	SubmitCode(ctx context.Context, questionID string, language string, source string, matchID *string) (*model.Submission, error)
+	RunCode(ctx context.Context, questionID string, language string, source string, input *string, matchID *string) (*model.CodeRun, error)
+	Submissions(ctx context.Context, questionID string, limit *int, offset *int) ([]*model.Submission, error)
+	Submission(ctx context.Context, id string) (*model.Submission, error)
+	SubmissionTestResults(ctx context.Context, submission *model.Submission) ([]*model.TestResult, error)
+	JudgeQueue(ctx context.Context) (*model.JudgeQueue, error)
+	Languages(ctx context.Context) ([]*model.Language, error)
+
+	// Problem review workflow
+	MyProblems(ctx context.Context) ([]*model.Problem, error)
+	ReviewQueue(ctx context.Context) ([]*model.Problem, error)
+	SubmitProblemForReview(ctx context.Context, id string) (*model.Problem, error)
+	ReturnProblemToDraft(ctx context.Context, id string) (*model.Problem, error)
+	ArchiveProblem(ctx context.Context, id string) (*model.Problem, error)
+	AssignReviewer(ctx context.Context, problemID, userID string) (*model.Problem, error)
+	ReviewProblem(ctx context.Context, problemID string, decision model.ReviewDecision, comment *string) (*model.Problem, error)
+	AddReviewComment(ctx context.Context, problemID, body string) (*model.ReviewComment, error)
+	ProblemReviewers(ctx context.Context, problem *model.Problem) ([]*model.ProblemReviewer, error)
+	ProblemReviewComments(ctx context.Context, problem *model.Problem) ([]*model.ReviewComment, error)
+	SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error)
 }
 
 // PCDGraphQLServiceDeps contains dependencies for the workflow
@@ -51,6 +132,86 @@ func (impl *pcdGraphQLServiceImpl) GetQuestions(ctx context.Context, input model
 	return impl.deps.Controller.GetQuestions(ctx, input)
 }
 
+// QuestionsConnection fetches a cursor-paginated page of questions through the controller
+func (impl *pcdGraphQLServiceImpl) QuestionsConnection(ctx context.Context, filter *model.GetQuestionsRequest, first *int, after *string, last *int, before *string) (*model.QuestionConnection, error) {
+	return impl.deps.Controller.QuestionsConnection(ctx, filter, first, after, last, before)
+}
+
+// QuestionBySlug returns a single question by slug
+func (impl *pcdGraphQLServiceImpl) QuestionBySlug(ctx context.Context, slug string) (*model.Question, error) {
+	return impl.deps.Controller.QuestionBySlug(ctx, slug)
+}
+
+// QuestionExamples returns the worked examples of a question
+func (impl *pcdGraphQLServiceImpl) QuestionExamples(ctx context.Context, question *model.Question) ([]*model.QuestionExample, error) {
+	return impl.deps.Controller.QuestionExamples(ctx, question)
+}
+
+// QuestionStarterCode returns the starter code of a question for each language
+func (impl *pcdGraphQLServiceImpl) QuestionStarterCode(ctx context.Context, question *model.Question) ([]*model.StarterCode, error) {
+	return impl.deps.Controller.QuestionStarterCode(ctx, question)
+}
+
+// QuestionProblem returns the 1v1 problem backed by a question
+func (impl *pcdGraphQLServiceImpl) QuestionProblem(ctx context.Context, question *model.Question) (*model.Problem, error) {
+	return impl.deps.Controller.QuestionProblem(ctx, question)
+}
+
+// SetStarterCode sets the starter code of a question for one language
+func (impl *pcdGraphQLServiceImpl) SetStarterCode(ctx context.Context, questionID, language, code string) (*model.StarterCode, error) {
+	return impl.deps.Controller.SetStarterCode(ctx, questionID, language, code)
+}
+
+// ProblemRevisions returns the revision history of a question
+func (impl *pcdGraphQLServiceImpl) ProblemRevisions(ctx context.Context, questionID string) ([]*model.ProblemRevision, error) {
+	return impl.deps.Controller.ProblemRevisions(ctx, questionID)
+}
+
+// ProblemRevision returns a single problem revision
+func (impl *pcdGraphQLServiceImpl) ProblemRevision(ctx context.Context, id string) (*model.ProblemRevision, error) {
+	return impl.deps.Controller.ProblemRevision(ctx, id)
+}
+
+// Topics returns the topic catalog as a tree
+func (impl *pcdGraphQLServiceImpl) Topics(ctx context.Context) ([]*model.Topic, error) {
+	return impl.deps.Controller.Topics(ctx)
+}
+
+// CreateTopic adds a topic to the catalog
+func (impl *pcdGraphQLServiceImpl) CreateTopic(ctx context.Context, input model.CreateTopicInput) (*model.Topic, error) {
+	return impl.deps.Controller.CreateTopic(ctx, input)
+}
+
+// AddTopicAlias registers another spelling for a topic
+func (impl *pcdGraphQLServiceImpl) AddTopicAlias(ctx context.Context, topic, alias string) (*model.Topic, error) {
+	return impl.deps.Controller.AddTopicAlias(ctx, topic, alias)
+}
+
+// TestCases returns the test cases of a question visible to the viewer
+func (impl *pcdGraphQLServiceImpl) TestCases(ctx context.Context, questionID string) ([]*model.TestCase, error) {
+	return impl.deps.Controller.TestCases(ctx, questionID)
+}
+
+// AddTestCase appends a test case to a question
+func (impl *pcdGraphQLServiceImpl) AddTestCase(ctx context.Context, questionID string, input model.TestCaseInput) (*model.TestCase, error) {
+	return impl.deps.Controller.AddTestCase(ctx, questionID, input)
+}
+
+// UpdateTestCase edits a test case
+func (impl *pcdGraphQLServiceImpl) UpdateTestCase(ctx context.Context, id string, input model.UpdateTestCaseInput) (*model.TestCase, error) {
+	return impl.deps.Controller.UpdateTestCase(ctx, id, input)
+}
+
+// ReorderTestCases sets the order of a question's test cases
+func (impl *pcdGraphQLServiceImpl) ReorderTestCases(ctx context.Context, questionID string, testCaseIDs []string) ([]*model.TestCase, error) {
+	return impl.deps.Controller.ReorderTestCases(ctx, questionID, testCaseIDs)
+}
+
+// DeleteTestCase removes a test case
+func (impl *pcdGraphQLServiceImpl) DeleteTestCase(ctx context.Context, id string) (bool, error) {
+	return impl.deps.Controller.DeleteTestCase(ctx, id)
+}
+
 // Me returns the current authenticated user
 func (impl *pcdGraphQLServiceImpl) Me(ctx context.Context) (*model.User, error) {
 	return impl.deps.Controller.Me(ctx)
@@ -102,8 +263,18 @@ func (impl *pcdGraphQLServiceImpl) Match(ctx context.Context, id string) (*model
 }
 
 // CreateProblem creates a new problem
-func (impl *pcdGraphQLServiceImpl) CreateProblem(ctx context.Context, title, description, difficulty string) (*model.Problem, error) {
-	return impl.deps.Controller.CreateProblem(ctx, title, description, difficulty)
+func (impl *pcdGraphQLServiceImpl) CreateProblem(ctx context.Context, title, description, difficulty string, topics []string, timeLimitMs, memoryLimitMb *int) (*model.Problem, error) {
+	return impl.deps.Controller.CreateProblem(ctx, title, description, difficulty, topics, timeLimitMs, memoryLimitMb)
+}
+
+// UpdateProblem updates an existing problem
+func (impl *pcdGraphQLServiceImpl) UpdateProblem(ctx context.Context, id string, input model.UpdateProblemInput) (*model.Problem, error) {
+	return impl.deps.Controller.UpdateProblem(ctx, id, input)
+}
+
+// DeleteProblem removes a problem from the 1v1 pool
+func (impl *pcdGraphQLServiceImpl) DeleteProblem(ctx context.Context, id string) (bool, error) {
+	return impl.deps.Controller.DeleteProblem(ctx, id)
 }
 
 // CreateMatch creates a new match
@@ -111,3 +282,207 @@ func (impl *pcdGraphQLServiceImpl) CreateMatch(ctx context.Context, problemID st
 	return impl.deps.Controller.CreateMatch(ctx, problemID)
 }
 
+// MyProblems returns the problems authored by the current user
+func (impl *pcdGraphQLServiceImpl) MyProblems(ctx context.Context) ([]*model.Problem, error) {
+	return impl.deps.Controller.MyProblems(ctx)
+}
+
+// ReviewQueue returns the problems waiting for the current user's review
+func (impl *pcdGraphQLServiceImpl) ReviewQueue(ctx context.Context) ([]*model.Problem, error) {
+	return impl.deps.Controller.ReviewQueue(ctx)
+}
+
+// SubmitProblemForReview moves a draft problem into review
+func (impl *pcdGraphQLServiceImpl) SubmitProblemForReview(ctx context.Context, id string) (*model.Problem, error) {
+	return impl.deps.Controller.SubmitProblemForReview(ctx, id)
+}
+
+// ReturnProblemToDraft moves a problem back to draft
+func (impl *pcdGraphQLServiceImpl) ReturnProblemToDraft(ctx context.Context, id string) (*model.Problem, error) {
+	return impl.deps.Controller.ReturnProblemToDraft(ctx, id)
+}
+
+// ArchiveProblem archives a published problem
+func (impl *pcdGraphQLServiceImpl) ArchiveProblem(ctx context.Context, id string) (*model.Problem, error) {
+	return impl.deps.Controller.ArchiveProblem(ctx, id)
+}
+
+// AssignReviewer assigns a reviewer to a problem
+func (impl *pcdGraphQLServiceImpl) AssignReviewer(ctx context.Context, problemID, userID string) (*model.Problem, error) {
+	return impl.deps.Controller.AssignReviewer(ctx, problemID, userID)
+}
+
+// ReviewProblem records a review decision
+func (impl *pcdGraphQLServiceImpl) ReviewProblem(ctx context.Context, problemID string, decision model.ReviewDecision, comment *string) (*model.Problem, error) {
+	return impl.deps.Controller.ReviewProblem(ctx, problemID, decision, comment)
+}
+
+// AddReviewComment comments on a problem under review
+func (impl *pcdGraphQLServiceImpl) AddReviewComment(ctx context.Context, problemID, body string) (*model.ReviewComment, error) {
+	return impl.deps.Controller.AddReviewComment(ctx, problemID, body)
+}
+
+// ProblemReviewers returns the reviewers of a problem
+func (impl *pcdGraphQLServiceImpl) ProblemReviewers(ctx context.Context, problem *model.Problem) ([]*model.ProblemReviewer, error) {
+	return impl.deps.Controller.ProblemReviewers(ctx, problem)
+}
+
+// ProblemReviewComments returns the review comments on a problem
+func (impl *pcdGraphQLServiceImpl) ProblemReviewComments(ctx context.Context, problem *model.Problem) ([]*model.ReviewComment, error) {
+	return impl.deps.Controller.ProblemReviewComments(ctx, problem)
+}
+
+// SetUserRole changes a user's role
+func (impl *pcdGraphQLServiceImpl) SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	return impl.deps.Controller.SetUserRole(ctx, userID, role)
+}
+
+// QuestionEditorial returns the editorial of a question as the current user sees it
+func (impl *pcdGraphQLServiceImpl) QuestionEditorial(ctx context.Context, question *model.Question) (*model.QuestionEditorial, error) {
+	return impl.deps.Controller.QuestionEditorial(ctx, question)
+}
+
+// GiveUpQuestion gives up on a question and unlocks its editorial
+func (impl *pcdGraphQLServiceImpl) GiveUpQuestion(ctx context.Context, questionID string) (*model.QuestionEditorial, error) {
+	return impl.deps.Controller.GiveUpQuestion(ctx, questionID)
+}
+
+// SetEditorial sets the editorial of a question
+func (impl *pcdGraphQLServiceImpl) SetEditorial(ctx context.Context, questionID string, input model.EditorialInput) (*model.Editorial, error) {
+	return impl.deps.Controller.SetEditorial(ctx, questionID, input)
+}
+
+// SetReferenceSolution sets the reference solution of a question for one language
+func (impl *pcdGraphQLServiceImpl) SetReferenceSolution(ctx context.Context, questionID, language, code string) (*model.ReferenceSolution, error) {
+	return impl.deps.Controller.SetReferenceSolution(ctx, questionID, language, code)
+}
+
+// ProblemLists returns the visible problem lists
+func (impl *pcdGraphQLServiceImpl) ProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	return impl.deps.Controller.ProblemLists(ctx)
+}
+
+// FollowedProblemLists returns the lists the current user follows
+func (impl *pcdGraphQLServiceImpl) FollowedProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	return impl.deps.Controller.FollowedProblemLists(ctx)
+}
+
+// ProblemList returns a problem list by slug
+func (impl *pcdGraphQLServiceImpl) ProblemList(ctx context.Context, slug string) (*model.ProblemList, error) {
+	return impl.deps.Controller.ProblemList(ctx, slug)
+}
+
+// CreateProblemList creates a problem list
+func (impl *pcdGraphQLServiceImpl) CreateProblemList(ctx context.Context, input model.CreateProblemListInput) (*model.ProblemList, error) {
+	return impl.deps.Controller.CreateProblemList(ctx, input)
+}
+
+// UpdateProblemList updates a problem list
+func (impl *pcdGraphQLServiceImpl) UpdateProblemList(ctx context.Context, id string, input model.UpdateProblemListInput) (*model.ProblemList, error) {
+	return impl.deps.Controller.UpdateProblemList(ctx, id, input)
+}
+
+// DeleteProblemList deletes a problem list
+func (impl *pcdGraphQLServiceImpl) DeleteProblemList(ctx context.Context, id string) (bool, error) {
+	return impl.deps.Controller.DeleteProblemList(ctx, id)
+}
+
+// FollowProblemList follows a problem list
+func (impl *pcdGraphQLServiceImpl) FollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return impl.deps.Controller.FollowProblemList(ctx, id)
+}
+
+// UnfollowProblemList unfollows a problem list
+func (impl *pcdGraphQLServiceImpl) UnfollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return impl.deps.Controller.UnfollowProblemList(ctx, id)
+}
+
+// ProblemListSections returns the sections of a problem list
+func (impl *pcdGraphQLServiceImpl) ProblemListSections(ctx context.Context, list *model.ProblemList) ([]*model.ProblemListSection, error) {
+	return impl.deps.Controller.ProblemListSections(ctx, list)
+}
+
+// ProblemListProgress returns the current user's progress on a problem list
+func (impl *pcdGraphQLServiceImpl) ProblemListProgress(ctx context.Context, list *model.ProblemList) (*model.ProblemListProgress, error) {
+	return impl.deps.Controller.ProblemListProgress(ctx, list)
+}
+
+// QuestionSignature returns the function signature of a question, or nil if it has none
+func (impl *pcdGraphQLServiceImpl) QuestionSignature(ctx context.Context, question *model.Question) (*model.FunctionSignature, error) {
+	return impl.deps.Controller.QuestionSignature(ctx, question)
+}
+
+// SetFunctionSignature sets the function signature of a question and regenerates its starter code
+func (impl *pcdGraphQLServiceImpl) SetFunctionSignature(ctx context.Context, questionID string, input model.FunctionSignatureInput) ([]*model.StarterCode, error) {
+	return impl.deps.Controller.SetFunctionSignature(ctx, questionID, input)
+}
+
+// QuestionChecker returns how a question's outputs are checked
+func (impl *pcdGraphQLServiceImpl) QuestionChecker(ctx context.Context, question *model.Question) (*model.Checker, error) {
+	return impl.deps.Controller.QuestionChecker(ctx, question)
+}
+
+// SetChecker sets how a question's outputs are checked
+func (impl *pcdGraphQLServiceImpl) SetChecker(ctx context.Context, questionID string, input model.CheckerInput) (*model.Checker, error) {
+	return impl.deps.Controller.SetChecker(ctx, questionID, input)
+}
+
+// QuestionDescriptionHTML returns the description of a question rendered to sanitized HTML
+func (impl *pcdGraphQLServiceImpl) QuestionDescriptionHTML(ctx context.Context, question *model.Question) (string, error) {
+	return impl.deps.Controller.QuestionDescriptionHTML(ctx, question)
+}
+
+// ProblemDescriptionHTML returns the description of a problem rendered to sanitized HTML
+func (impl *pcdGraphQLServiceImpl) ProblemDescriptionHTML(ctx context.Context, problem *model.Problem) (string, error) {
+	return impl.deps.Controller.ProblemDescriptionHTML(ctx, problem)
+}
+
+// ProblemRevisionDescriptionHTML returns the description of a revision rendered to sanitized HTML
+func (impl *pcdGraphQLServiceImpl) ProblemRevisionDescriptionHTML(ctx context.Context, revision *model.ProblemRevision) (string, error) {
+	return impl.deps.Controller.ProblemRevisionDescriptionHTML(ctx, revision)
+}
+
+// RandomQuestion picks a random question matching the filter
+func (impl *pcdGraphQLServiceImpl) RandomQuestion(ctx context.Context, filter *model.GetQuestionsRequest, excludeSolved *bool) (*model.Question, error) {
+	return impl.deps.Controller.RandomQuestion(ctx, filter, excludeSolved)
+}
+
+// SimilarQuestions returns the questions most similar to a question
+func (impl *pcdGraphQLServiceImpl) SimilarQuestions(ctx context.Context, id string, limit *int) ([]*model.Question, error) {
+	return impl.deps.Controller.SimilarQuestions(ctx, id, limit)
+}
+
+// SubmitCode queues code for judging
+func (impl *pcdGraphQLServiceImpl) SubmitCode(ctx context.Context, questionID string, language string, source string, matchID *string) (*model.Submission, error) {
+	return impl.deps.Controller.SubmitCode(ctx, questionID, language, source, matchID)
+}
+
+// RunCode runs code without submitting it
+func (impl *pcdGraphQLServiceImpl) RunCode(ctx context.Context, questionID string, language string, source string, input *string, matchID *string) (*model.CodeRun, error) {
+	return impl.deps.Controller.RunCode(ctx, questionID, language, source, input, matchID)
+}
+
+// Submissions returns the current user's submissions on a question
+func (impl *pcdGraphQLServiceImpl) Submissions(ctx context.Context, questionID string, limit *int, offset *int) ([]*model.Submission, error) {
+	return impl.deps.Controller.Submissions(ctx, questionID, limit, offset)
+}
+
+// Submission returns one of the current user's submissions
+func (impl *pcdGraphQLServiceImpl) Submission(ctx context.Context, id string) (*model.Submission, error) {
+	return impl.deps.Controller.Submission(ctx, id)
+}
+
+// SubmissionTestResults returns the per-test results of a submission
+func (impl *pcdGraphQLServiceImpl) SubmissionTestResults(ctx context.Context, submission *model.Submission) ([]*model.TestResult, error) {
+	return impl.deps.Controller.SubmissionTestResults(ctx, submission)
+}
+
+// JudgeQueue returns the judge queue's depth and estimated wait
+func (impl *pcdGraphQLServiceImpl) JudgeQueue(ctx context.Context) (*model.JudgeQueue, error) {
+	return impl.deps.Controller.JudgeQueue(ctx)
+}
+
+// Languages returns the languages new submissions may be written in
+func (impl *pcdGraphQLServiceImpl) Languages(ctx context.Context) ([]*model.Language, error) {
+	return impl.deps.Controller.Languages(ctx)
+}
@@ -0,0 +1,167 @@
+// Command bundle imports and exports problem bundles.
+//
+//	bundle import [-dry-run] <bundle>...
+//	bundle export [-o dir] [-format dir|zip|tar.gz] <slug>...
+//
+// Both directions are keyed by slug and print what they change; running
+// either twice in a row reports no changes the second time. Bundles may be
+// directories, .zip or .tar.gz archives. Database settings come from the same
+// environment variables as the server.
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"codestandoff/backend/internal/bundle"
+	"codestandoff/backend/internal/database"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bundle import [-dry-run] <bundle>...")
+	fmt.Fprintln(os.Stderr, "       bundle export [-o dir] [-format dir|zip|tar.gz] <slug>...")
+	os.Exit(2)
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report changes without writing them")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	failed := false
+	for _, src := range fs.Args() {
+		if err := importBundle(db, src, *dryRun); err != nil {
+			log.Printf("%s: %v", src, err)
+			failed = true
+		}
+	}
+	if failed {
+		return errors.New("some bundles were not imported")
+	}
+	return nil
+}
+
+func importBundle(db *sql.DB, src string, dryRun bool) error {
+	b, err := bundle.Read(src)
+	if err != nil {
+		return err
+	}
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if err := bundle.ResolveTopics(db, b); err != nil {
+		return err
+	}
+
+	current, err := bundle.Load(db, b.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	changes := bundle.Diff(current, b)
+	report(b.Slug, changes)
+	if dryRun || len(changes) == 0 {
+		return nil
+	}
+
+	_, err = bundle.Import(db, b)
+	return err
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("o", ".", "directory to write bundles to; each goes in <dir>/<slug>")
+	format := fs.String("format", "dir", "bundle format: dir, zip or tar.gz")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+
+	suffix := ""
+	switch *format {
+	case "dir":
+	case "zip", "tar.gz":
+		suffix = "." + *format
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	for _, slug := range fs.Args() {
+		b, err := bundle.Load(db, slug)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%s: no such question", slug)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", slug, err)
+		}
+
+		dst := filepath.Join(*out, slug) + suffix
+		var existing *bundle.Bundle
+		if _, err := os.Stat(dst); err == nil {
+			if existing, err = bundle.Read(dst); err != nil {
+				return err
+			}
+		}
+
+		changes := bundle.Diff(existing, b)
+		report(slug, changes)
+		if len(changes) == 0 {
+			continue
+		}
+		if err := bundle.Write(b, dst); err != nil {
+			return fmt.Errorf("%s: %w", slug, err)
+		}
+	}
+	return nil
+}
+
+func report(slug string, changes []bundle.Change) {
+	if len(changes) == 0 {
+		fmt.Printf("%s: up to date\n", slug)
+		return
+	}
+	fmt.Printf("%s:\n", slug)
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+}
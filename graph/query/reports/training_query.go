@@ -1,72 +1,381 @@
 package query
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/lib/pq"
 )
 
-// GetQuestionsQueryWithArgs returns the query string and arguments separately
-func GetQuestionsQueryWithArgs(offset, limit int, search *string, difficulty *string, topics []string, sortBy *string, sortOrder *string) (string, []interface{}) {
-	// Base query
-	query := "SELECT id, title, slug, description, difficulty, topics, test_case_count FROM public.questions"
+// Markers ts_headline wraps around matched terms. They are control characters
+// so they cannot collide with question text; FormatHighlight turns them into
+// <mark> tags once the text has been escaped.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+const questionColumns = "id, title, slug, description, difficulty, topics, test_case_count, constraints, hints, rating, attempt_count, solve_count, created_at, updated_at"
+
+// SortByRelevance orders search results by full-text rank. It is the default
+// sort when a search term is given.
+const SortByRelevance = "relevance"
+
+// difficultyRank orders difficulties Easy, Medium, Hard instead of alphabetically
+const difficultyRank = "CASE difficulty WHEN 'Easy' THEN 1 WHEN 'Medium' THEN 2 WHEN 'Hard' THEN 3 ELSE 4 END"
+
+// sortKey is a column or expression questions can be sorted by
+type sortKey struct {
+	expr string
+	// cast is the SQL type a cursor value is cast back to
+	cast string
+	// desc is the default direction
+	desc bool
+}
+
+// sortKeys maps the accepted sortBy values, other than relevance, to their
+// sort expression. Every sort breaks ties by id so pages stay stable.
+var sortKeys = map[string]sortKey{
+	"id":         {"id", "integer", false},
+	"difficulty": {difficultyRank, "integer", false},
+	"rating":     {"rating", "integer", false},
+	"acceptance": {"acceptance_rate", "double precision", true},
+	"popularity": {"attempt_count", "integer", true},
+	"newest":     {"COALESCE(published_at, created_at)", "timestamptz", true},
+}
+
+// resolveSort validates sortBy and sortOrder and returns the sort key and
+// whether to sort descending. An empty sortBy means defaultKey.
+func resolveSort(sortBy, sortOrder *string, defaultKey string) (string, sortKey, bool, error) {
+	key := defaultKey
+	if sortBy != nil && *sortBy != "" {
+		key = *sortBy
+	}
+
+	var sk sortKey
+	if key != SortByRelevance {
+		var ok bool
+		if sk, ok = sortKeys[key]; !ok {
+			return "", sortKey{}, false, fmt.Errorf("invalid sortBy %q: must be one of %s", key, strings.Join(SortKeys(), ", "))
+		}
+	} else {
+		sk.desc = true
+	}
+
+	desc := sk.desc
+	if sortOrder != nil && *sortOrder != "" {
+		switch strings.ToUpper(*sortOrder) {
+		case "ASC":
+			desc = false
+		case "DESC":
+			desc = true
+		default:
+			return "", sortKey{}, false, fmt.Errorf("invalid sortOrder %q: must be ASC or DESC", *sortOrder)
+		}
+	}
+
+	return key, sk, desc, nil
+}
+
+// SortKeys returns the accepted sortBy values in a stable order
+func SortKeys() []string {
+	keys := make([]string, 0, len(sortKeys)+1)
+	for key := range sortKeys {
+		keys = append(keys, key)
+	}
+	keys = append(keys, SortByRelevance)
+	sort.Strings(keys)
+	return keys
+}
+
+// QuestionFilter holds the filters shared by the question list queries
+type QuestionFilter struct {
+	Search     *string
+	Difficulty *string
+	// TopicTerms are lower-case topic spellings, already resolved and expanded
+	// through the topic catalog. A question matches if any of its topics is one of them.
+	TopicTerms []string
+	// Inclusive bounds on the problem rating
+	MinRating *int
+	MaxRating *int
+	// ExcludeSolvedBy is a user ID; questions that user solved are left out
+	ExcludeSolvedBy string
+}
+
+// Cursor identifies a row in a keyset-paginated question list: the value of
+// the sort column plus the id as a tie-breaker
+type Cursor struct {
+	SortBy string `json:"k"`
+	Value  string `json:"v"`
+	ID     int    `json:"id"`
+}
 
-	// Build WHERE conditions
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+// EncodeCursor returns the opaque string form of a cursor
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	c := &Cursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+// queryArgs collects positional query arguments
+type queryArgs []interface{}
+
+// add appends v and returns its placeholder
+func (a *queryArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// searchTerm returns the trimmed search term, or "" when no search was requested
+func (f QuestionFilter) searchTerm() string {
+	if f.Search == nil {
+		return ""
+	}
+	return strings.TrimSpace(*f.Search)
+}
 
-	// Add search filter if provided (searches by title or id)
-	if search != nil && *search != "" {
-		searchPattern := "%" + *search + "%"
-		conditions = append(conditions, fmt.Sprintf("(title ILIKE $%d OR CAST(id AS TEXT) LIKE $%d)", argIndex, argIndex+1))
-		args = append(args, searchPattern, *search+"%")
-		argIndex += 2
+// conditions builds the WHERE conditions for the filter. tsQuery is the
+// full-text query expression when a search term is set, for ranking and highlights.
+// Only published questions are ever listed.
+func (f QuestionFilter) conditions(args *queryArgs) (conditions []string, tsQuery string) {
+	conditions = append(conditions, "status = 'published'")
+
+	// Add full-text search if provided (matches title, topics and description, or an id prefix)
+	if term := f.searchTerm(); term != "" {
+		tsQuery = fmt.Sprintf("websearch_to_tsquery('english', %s)", args.add(term))
+
+		condition := "search_vector @@ " + tsQuery
+		if _, err := strconv.Atoi(term); err == nil {
+			condition = fmt.Sprintf("(%s OR CAST(id AS TEXT) LIKE %s)", condition, args.add(term+"%"))
+		}
+		conditions = append(conditions, condition)
 	}
 
 	// Add difficulty filter if provided
-	if difficulty != nil && *difficulty != "" {
-		conditions = append(conditions, fmt.Sprintf("difficulty = $%d", argIndex))
-		args = append(args, *difficulty)
-		argIndex++
+	if f.Difficulty != nil && *f.Difficulty != "" {
+		conditions = append(conditions, "difficulty = "+args.add(*f.Difficulty))
 	}
 
 	// Add topics filter if provided
-	if len(topics) > 0 {
-		conditions = append(conditions, fmt.Sprintf("topics && $%d", argIndex))
-		args = append(args, pq.Array(topics))
-		argIndex++
+	if len(f.TopicTerms) > 0 {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(topics) AS qt(topic) WHERE lower(qt.topic) = ANY(%s))", args.add(pq.Array(f.TopicTerms))))
+	}
+
+	// Add rating bounds if provided
+	if f.MinRating != nil {
+		conditions = append(conditions, "rating >= "+args.add(*f.MinRating))
+	}
+	if f.MaxRating != nil {
+		conditions = append(conditions, "rating <= "+args.add(*f.MaxRating))
+	}
+
+	// Leave out questions the user already solved
+	if f.ExcludeSolvedBy != "" {
+		conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM public.question_attempts a WHERE a.question_id = questions.id AND a.user_id = %s AND a.solved_at IS NOT NULL)", args.add(f.ExcludeSolvedBy)))
+	}
+
+	return conditions, tsQuery
+}
+
+// GetQuestionsQueryWithArgs returns the query string and arguments separately.
+// Every row carries the question columns followed by search_rank,
+// title_highlight and snippet; the last three are only populated when search is set.
+// It fails if sortBy or sortOrder is not recognised.
+func GetQuestionsQueryWithArgs(offset, limit int, filter QuestionFilter, sortBy *string, sortOrder *string) (string, []interface{}, error) {
+	var args queryArgs
+
+	conditions, tsQuery := filter.conditions(&args)
+	rankExpr := "0::real"
+	if tsQuery != "" {
+		rankExpr = fmt.Sprintf("ts_rank_cd(search_vector, %s)", tsQuery)
+	}
+
+	// Relevance is the default when searching
+	defaultKey := "id"
+	if tsQuery != "" {
+		defaultKey = SortByRelevance
+	}
+	key, sk, desc, err := resolveSort(sortBy, sortOrder, defaultKey)
+	if err != nil {
+		return "", nil, err
+	}
+	if key == SortByRelevance && tsQuery == "" {
+		// Nothing to rank against
+		key, sk, desc, _ = resolveSort(nil, sortOrder, "id")
 	}
+	sortExpr := sk.expr
+	if key == SortByRelevance {
+		sortExpr = rankExpr
+	}
+
+	// Base query
+	query := fmt.Sprintf("SELECT %s, %s AS search_rank, %s AS sort_value FROM public.questions", questionColumns, rankExpr, sortExpr)
 
 	// Add WHERE clause if conditions exist
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// Add ordering
-	orderBy := "id"
-	orderDir := "ASC"
+	// Add ordering, with id as the tie-breaker
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	orderClause := fmt.Sprintf(" ORDER BY sort_value %s, id %s", dir, dir)
+	query += orderClause
 
-	if sortBy != nil && *sortBy != "" {
-		// Validate sortBy - only allow "id" or "difficulty"
-		if *sortBy == "id" || *sortBy == "difficulty" {
-			orderBy = *sortBy
-		}
+	// Add pagination
+	query += fmt.Sprintf(" LIMIT %s OFFSET %s", args.add(limit), args.add(offset))
+
+	if tsQuery == "" {
+		return fmt.Sprintf("SELECT %s, search_rank, NULL::text AS title_highlight, NULL::text AS snippet FROM (%s) page%s", questionColumns, query, orderClause), args, nil
 	}
 
-	if sortOrder != nil && *sortOrder != "" {
-		// Validate sortOrder - only allow "ASC" or "DESC"
-		if *sortOrder == "ASC" || *sortOrder == "DESC" {
-			orderDir = *sortOrder
+	// Highlight only the rows on this page; ts_headline is too expensive to run over every match
+	titleOptions := args.add(fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", highlightStart, highlightStop))
+	snippetOptions := args.add(fmt.Sprintf(`StartSel=%s, StopSel=%s, MaxFragments=2, MinWords=8, MaxWords=25, FragmentDelimiter=" ... "`, highlightStart, highlightStop))
+
+	return fmt.Sprintf(
+		"SELECT %s, search_rank, ts_headline('english', title, %s, %s) AS title_highlight, ts_headline('english', description, %s, %s) AS snippet FROM (%s) page%s",
+		questionColumns, tsQuery, titleOptions, tsQuery, snippetOptions, query, orderClause,
+	), args, nil
+}
+
+// GetQuestionsConnectionQueryWithArgs builds a keyset-paginated question query.
+// Rows carry the question columns followed by cursor_value, the text form of
+// the sort column. It returns up to limit rows after the cursor, or before it
+// when backward is set; in that case rows come back in reverse order and the
+// caller flips them.
+func GetQuestionsConnectionQueryWithArgs(filter QuestionFilter, sortBy *string, sortOrder *string, limit int, cursor *Cursor, backward bool) (string, []interface{}, error) {
+	key, sk, desc, err := resolveSort(sortBy, sortOrder, "id")
+	if err != nil {
+		return "", nil, err
+	}
+	if key == SortByRelevance {
+		return "", nil, fmt.Errorf("sortBy %q is not supported with cursor pagination", key)
+	}
+	if backward {
+		desc = !desc
+	}
+
+	var args queryArgs
+	conditions, _ := filter.conditions(&args)
+
+	if cursor != nil {
+		if cursor.SortBy != key {
+			return "", nil, errors.New("cursor does not match the requested sort")
+		}
+		op := ">"
+		if desc {
+			op = "<"
 		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s::%s, %s)",
+			sk.expr, op, args.add(cursor.Value), sk.cast, args.add(cursor.ID)))
+	}
+
+	query := fmt.Sprintf("SELECT %s, CAST(%s AS TEXT) AS cursor_value FROM public.questions", questionColumns, sk.expr)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += fmt.Sprintf(" ORDER BY %s %s", orderBy, orderDir)
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", sk.expr, dir, dir, args.add(limit))
 
-	// Add pagination
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	return query, args, nil
+}
+
+// GetQuestionAtQueryWithArgs returns a query for the question at offset among
+// those matching the filter, in id order. Picking a random offset below the
+// count samples uniformly without sorting the whole table by random().
+func GetQuestionAtQueryWithArgs(filter QuestionFilter, offset int) (string, []interface{}) {
+	var args queryArgs
+	conditions, _ := filter.conditions(&args)
+
+	query := fmt.Sprintf("SELECT %s FROM public.questions", questionColumns)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY id LIMIT 1 OFFSET %s", args.add(offset))
+
+	return query, args
+}
+
+// GetQuestionsCountQueryWithArgs returns a query counting every question that matches the filter
+func GetQuestionsCountQueryWithArgs(filter QuestionFilter) (string, []interface{}) {
+	var args queryArgs
+	conditions, _ := filter.conditions(&args)
+
+	query := "SELECT COUNT(*) FROM public.questions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return query, args
+}
+
+// GetDifficultyFacetsQueryWithArgs returns (difficulty, count) rows for the
+// filter. The difficulty filter itself is left out so the counts show what
+// each alternative choice would return.
+func GetDifficultyFacetsQueryWithArgs(filter QuestionFilter) (string, []interface{}) {
+	filter.Difficulty = nil
+	var args queryArgs
+	conditions, _ := filter.conditions(&args)
+
+	query := "SELECT difficulty, COUNT(*) FROM public.questions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY difficulty ORDER BY " + difficultyRank
+
+	return query, args
+}
+
+// GetTopicFacetsQueryWithArgs returns (topic slug, count) rows for the
+// filter, most common first. terms and slugs are parallel arrays mapping each
+// lower-case topic spelling to the catalog topics it counts towards, so
+// aliases are merged and parents include their children. The topics filter
+// itself is left out, as with difficulty.
+func GetTopicFacetsQueryWithArgs(filter QuestionFilter, terms, slugs []string) (string, []interface{}) {
+	filter.TopicTerms = nil
+	var args queryArgs
+	conditions, _ := filter.conditions(&args)
+
+	query := fmt.Sprintf(
+		"SELECT m.slug, COUNT(DISTINCT questions.id) FROM public.questions CROSS JOIN LATERAL unnest(questions.topics) AS t(name) JOIN unnest(%s::text[], %s::text[]) AS m(term, slug) ON m.term = lower(t.name)",
+		args.add(pq.Array(terms)), args.add(pq.Array(slugs)),
+	)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY m.slug ORDER BY COUNT(DISTINCT questions.id) DESC, m.slug"
 
 	return query, args
 }
+
+// FormatHighlight escapes a ts_headline result for HTML and marks the matched terms with <mark> tags
+func FormatHighlight(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
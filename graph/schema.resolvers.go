@@ -25,8 +25,18 @@ func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
 }
 
 // CreateProblem is the resolver for the createProblem field.
-func (r *mutationResolver) CreateProblem(ctx context.Context, title string, description string, difficulty string) (*model.Problem, error) {
-	return r.Workflow.CreateProblem(ctx, title, description, difficulty)
+func (r *mutationResolver) CreateProblem(ctx context.Context, title string, description string, difficulty string, topics []string, timeLimitMs *int, memoryLimitMb *int) (*model.Problem, error) {
+	return r.Workflow.CreateProblem(ctx, title, description, difficulty, topics, timeLimitMs, memoryLimitMb)
+}
+
+// UpdateProblem is the resolver for the updateProblem field.
+func (r *mutationResolver) UpdateProblem(ctx context.Context, id string, input model.UpdateProblemInput) (*model.Problem, error) {
+	return r.Workflow.UpdateProblem(ctx, id, input)
+}
+
+// DeleteProblem is the resolver for the deleteProblem field.
+func (r *mutationResolver) DeleteProblem(ctx context.Context, id string) (bool, error) {
+	return r.Workflow.DeleteProblem(ctx, id)
 }
 
 // CreateMatch is the resolver for the createMatch field.
@@ -34,6 +44,166 @@ func (r *mutationResolver) CreateMatch(ctx context.Context, problemID string) (*
 	return r.Workflow.CreateMatch(ctx, problemID)
 }
 
+// SubmitCode is the resolver for the submitCode field.
+func (r *mutationResolver) SubmitCode(ctx context.Context, questionID string, language string, source string, matchID *string) (*model.Submission, error) {
+	return r.Workflow.SubmitCode(ctx, questionID, language, source, matchID)
+}
+
+// RunCode is the resolver for the runCode field.
+func (r *mutationResolver) RunCode(ctx context.Context, questionID string, language string, source string, input *string, matchID *string) (*model.CodeRun, error) {
+	return r.Workflow.RunCode(ctx, questionID, language, source, input, matchID)
+}
+
+// SubmitProblemForReview is the resolver for the submitProblemForReview field.
+func (r *mutationResolver) SubmitProblemForReview(ctx context.Context, id string) (*model.Problem, error) {
+	return r.Workflow.SubmitProblemForReview(ctx, id)
+}
+
+// ReturnProblemToDraft is the resolver for the returnProblemToDraft field.
+func (r *mutationResolver) ReturnProblemToDraft(ctx context.Context, id string) (*model.Problem, error) {
+	return r.Workflow.ReturnProblemToDraft(ctx, id)
+}
+
+// ArchiveProblem is the resolver for the archiveProblem field.
+func (r *mutationResolver) ArchiveProblem(ctx context.Context, id string) (*model.Problem, error) {
+	return r.Workflow.ArchiveProblem(ctx, id)
+}
+
+// AssignReviewer is the resolver for the assignReviewer field.
+func (r *mutationResolver) AssignReviewer(ctx context.Context, problemID string, userID string) (*model.Problem, error) {
+	return r.Workflow.AssignReviewer(ctx, problemID, userID)
+}
+
+// ReviewProblem is the resolver for the reviewProblem field.
+func (r *mutationResolver) ReviewProblem(ctx context.Context, problemID string, decision model.ReviewDecision, comment *string) (*model.Problem, error) {
+	return r.Workflow.ReviewProblem(ctx, problemID, decision, comment)
+}
+
+// AddReviewComment is the resolver for the addReviewComment field.
+func (r *mutationResolver) AddReviewComment(ctx context.Context, problemID string, body string) (*model.ReviewComment, error) {
+	return r.Workflow.AddReviewComment(ctx, problemID, body)
+}
+
+// SetUserRole is the resolver for the setUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	return r.Workflow.SetUserRole(ctx, userID, role)
+}
+
+// SetStarterCode is the resolver for the setStarterCode field.
+func (r *mutationResolver) SetStarterCode(ctx context.Context, questionID string, language string, code string) (*model.StarterCode, error) {
+	return r.Workflow.SetStarterCode(ctx, questionID, language, code)
+}
+
+// SetFunctionSignature is the resolver for the setFunctionSignature field.
+func (r *mutationResolver) SetFunctionSignature(ctx context.Context, questionID string, input model.FunctionSignatureInput) ([]*model.StarterCode, error) {
+	return r.Workflow.SetFunctionSignature(ctx, questionID, input)
+}
+
+// SetChecker is the resolver for the setChecker field.
+func (r *mutationResolver) SetChecker(ctx context.Context, questionID string, input model.CheckerInput) (*model.Checker, error) {
+	return r.Workflow.SetChecker(ctx, questionID, input)
+}
+
+// SetEditorial is the resolver for the setEditorial field.
+func (r *mutationResolver) SetEditorial(ctx context.Context, questionID string, input model.EditorialInput) (*model.Editorial, error) {
+	return r.Workflow.SetEditorial(ctx, questionID, input)
+}
+
+// SetReferenceSolution is the resolver for the setReferenceSolution field.
+func (r *mutationResolver) SetReferenceSolution(ctx context.Context, questionID string, language string, code string) (*model.ReferenceSolution, error) {
+	return r.Workflow.SetReferenceSolution(ctx, questionID, language, code)
+}
+
+// CreateProblemList is the resolver for the createProblemList field.
+func (r *mutationResolver) CreateProblemList(ctx context.Context, input model.CreateProblemListInput) (*model.ProblemList, error) {
+	return r.Workflow.CreateProblemList(ctx, input)
+}
+
+// UpdateProblemList is the resolver for the updateProblemList field.
+func (r *mutationResolver) UpdateProblemList(ctx context.Context, id string, input model.UpdateProblemListInput) (*model.ProblemList, error) {
+	return r.Workflow.UpdateProblemList(ctx, id, input)
+}
+
+// DeleteProblemList is the resolver for the deleteProblemList field.
+func (r *mutationResolver) DeleteProblemList(ctx context.Context, id string) (bool, error) {
+	return r.Workflow.DeleteProblemList(ctx, id)
+}
+
+// FollowProblemList is the resolver for the followProblemList field.
+func (r *mutationResolver) FollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return r.Workflow.FollowProblemList(ctx, id)
+}
+
+// UnfollowProblemList is the resolver for the unfollowProblemList field.
+func (r *mutationResolver) UnfollowProblemList(ctx context.Context, id string) (*model.ProblemList, error) {
+	return r.Workflow.UnfollowProblemList(ctx, id)
+}
+
+// GiveUpQuestion is the resolver for the giveUpQuestion field.
+func (r *mutationResolver) GiveUpQuestion(ctx context.Context, questionID string) (*model.QuestionEditorial, error) {
+	return r.Workflow.GiveUpQuestion(ctx, questionID)
+}
+
+// CreateTopic is the resolver for the createTopic field.
+func (r *mutationResolver) CreateTopic(ctx context.Context, input model.CreateTopicInput) (*model.Topic, error) {
+	return r.Workflow.CreateTopic(ctx, input)
+}
+
+// AddTopicAlias is the resolver for the addTopicAlias field.
+func (r *mutationResolver) AddTopicAlias(ctx context.Context, topic string, alias string) (*model.Topic, error) {
+	return r.Workflow.AddTopicAlias(ctx, topic, alias)
+}
+
+// AddTestCase is the resolver for the addTestCase field.
+func (r *mutationResolver) AddTestCase(ctx context.Context, questionID string, input model.TestCaseInput) (*model.TestCase, error) {
+	return r.Workflow.AddTestCase(ctx, questionID, input)
+}
+
+// UpdateTestCase is the resolver for the updateTestCase field.
+func (r *mutationResolver) UpdateTestCase(ctx context.Context, id string, input model.UpdateTestCaseInput) (*model.TestCase, error) {
+	return r.Workflow.UpdateTestCase(ctx, id, input)
+}
+
+// ReorderTestCases is the resolver for the reorderTestCases field.
+func (r *mutationResolver) ReorderTestCases(ctx context.Context, questionID string, testCaseIds []string) ([]*model.TestCase, error) {
+	return r.Workflow.ReorderTestCases(ctx, questionID, testCaseIds)
+}
+
+// DeleteTestCase is the resolver for the deleteTestCase field.
+func (r *mutationResolver) DeleteTestCase(ctx context.Context, id string) (bool, error) {
+	return r.Workflow.DeleteTestCase(ctx, id)
+}
+
+// DescriptionHTML is the resolver for the descriptionHtml field.
+func (r *problemResolver) DescriptionHTML(ctx context.Context, obj *model.Problem) (string, error) {
+	return r.Workflow.ProblemDescriptionHTML(ctx, obj)
+}
+
+// Reviewers is the resolver for the reviewers field.
+func (r *problemResolver) Reviewers(ctx context.Context, obj *model.Problem) ([]*model.ProblemReviewer, error) {
+	return r.Workflow.ProblemReviewers(ctx, obj)
+}
+
+// ReviewComments is the resolver for the reviewComments field.
+func (r *problemResolver) ReviewComments(ctx context.Context, obj *model.Problem) ([]*model.ReviewComment, error) {
+	return r.Workflow.ProblemReviewComments(ctx, obj)
+}
+
+// Sections is the resolver for the sections field.
+func (r *problemListResolver) Sections(ctx context.Context, obj *model.ProblemList) ([]*model.ProblemListSection, error) {
+	return r.Workflow.ProblemListSections(ctx, obj)
+}
+
+// Progress is the resolver for the progress field.
+func (r *problemListResolver) Progress(ctx context.Context, obj *model.ProblemList) (*model.ProblemListProgress, error) {
+	return r.Workflow.ProblemListProgress(ctx, obj)
+}
+
+// DescriptionHTML is the resolver for the descriptionHtml field.
+func (r *problemRevisionResolver) DescriptionHTML(ctx context.Context, obj *model.ProblemRevision) (string, error) {
+	return r.Workflow.ProblemRevisionDescriptionHTML(ctx, obj)
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	return r.Workflow.Me(ctx)
@@ -54,6 +224,66 @@ func (r *queryResolver) GetQuestions(ctx context.Context, input model.GetQuestio
 	return r.Workflow.GetQuestions(ctx, input)
 }
 
+// QuestionsConnection is the resolver for the questionsConnection field.
+func (r *queryResolver) QuestionsConnection(ctx context.Context, filter *model.GetQuestionsRequest, first *int, after *string, last *int, before *string) (*model.QuestionConnection, error) {
+	return r.Workflow.QuestionsConnection(ctx, filter, first, after, last, before)
+}
+
+// RandomQuestion is the resolver for the randomQuestion field.
+func (r *queryResolver) RandomQuestion(ctx context.Context, filter *model.GetQuestionsRequest, excludeSolved *bool) (*model.Question, error) {
+	return r.Workflow.RandomQuestion(ctx, filter, excludeSolved)
+}
+
+// SimilarQuestions is the resolver for the similarQuestions field.
+func (r *queryResolver) SimilarQuestions(ctx context.Context, id string, limit *int) ([]*model.Question, error) {
+	return r.Workflow.SimilarQuestions(ctx, id, limit)
+}
+
+// Question is the resolver for the question field.
+func (r *queryResolver) Question(ctx context.Context, slug string) (*model.Question, error) {
+	return r.Workflow.QuestionBySlug(ctx, slug)
+}
+
+// TestCases is the resolver for the testCases field.
+func (r *queryResolver) TestCases(ctx context.Context, questionID string) ([]*model.TestCase, error) {
+	return r.Workflow.TestCases(ctx, questionID)
+}
+
+// ProblemRevisions is the resolver for the problemRevisions field.
+func (r *queryResolver) ProblemRevisions(ctx context.Context, questionID string) ([]*model.ProblemRevision, error) {
+	return r.Workflow.ProblemRevisions(ctx, questionID)
+}
+
+// ProblemRevision is the resolver for the problemRevision field.
+func (r *queryResolver) ProblemRevision(ctx context.Context, id string) (*model.ProblemRevision, error) {
+	return r.Workflow.ProblemRevision(ctx, id)
+}
+
+// Submissions is the resolver for the submissions field.
+func (r *queryResolver) Submissions(ctx context.Context, questionID string, limit *int, offset *int) ([]*model.Submission, error) {
+	return r.Workflow.Submissions(ctx, questionID, limit, offset)
+}
+
+// Submission is the resolver for the submission field.
+func (r *queryResolver) Submission(ctx context.Context, id string) (*model.Submission, error) {
+	return r.Workflow.Submission(ctx, id)
+}
+
+// JudgeQueue is the resolver for the judgeQueue field.
+func (r *queryResolver) JudgeQueue(ctx context.Context) (*model.JudgeQueue, error) {
+	return r.Workflow.JudgeQueue(ctx)
+}
+
+// Languages is the resolver for the languages field.
+func (r *queryResolver) Languages(ctx context.Context) ([]*model.Language, error) {
+	return r.Workflow.Languages(ctx)
+}
+
+// Topics is the resolver for the topics field.
+func (r *queryResolver) Topics(ctx context.Context) ([]*model.Topic, error) {
+	return r.Workflow.Topics(ctx)
+}
+
 // Problems is the resolver for the problems field.
 func (r *queryResolver) Problems(ctx context.Context) ([]*model.Problem, error) {
 	return r.Workflow.Problems(ctx)
@@ -64,6 +294,31 @@ func (r *queryResolver) Problem(ctx context.Context, id string) (*model.Problem,
 	return r.Workflow.Problem(ctx, id)
 }
 
+// ProblemLists is the resolver for the problemLists field.
+func (r *queryResolver) ProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	return r.Workflow.ProblemLists(ctx)
+}
+
+// FollowedProblemLists is the resolver for the followedProblemLists field.
+func (r *queryResolver) FollowedProblemLists(ctx context.Context) ([]*model.ProblemList, error) {
+	return r.Workflow.FollowedProblemLists(ctx)
+}
+
+// ProblemList is the resolver for the problemList field.
+func (r *queryResolver) ProblemList(ctx context.Context, slug string) (*model.ProblemList, error) {
+	return r.Workflow.ProblemList(ctx, slug)
+}
+
+// MyProblems is the resolver for the myProblems field.
+func (r *queryResolver) MyProblems(ctx context.Context) ([]*model.Problem, error) {
+	return r.Workflow.MyProblems(ctx)
+}
+
+// ReviewQueue is the resolver for the reviewQueue field.
+func (r *queryResolver) ReviewQueue(ctx context.Context) ([]*model.Problem, error) {
+	return r.Workflow.ReviewQueue(ctx)
+}
+
 // Matches is the resolver for the matches field.
 func (r *queryResolver) Matches(ctx context.Context) ([]*model.Match, error) {
 	return r.Workflow.Matches(ctx)
@@ -74,11 +329,71 @@ func (r *queryResolver) Match(ctx context.Context, id string) (*model.Match, err
 	return r.Workflow.Match(ctx, id)
 }
 
+// DescriptionHTML is the resolver for the descriptionHtml field.
+func (r *questionResolver) DescriptionHTML(ctx context.Context, obj *model.Question) (string, error) {
+	return r.Workflow.QuestionDescriptionHTML(ctx, obj)
+}
+
+// Examples is the resolver for the examples field.
+func (r *questionResolver) Examples(ctx context.Context, obj *model.Question) ([]*model.QuestionExample, error) {
+	return r.Workflow.QuestionExamples(ctx, obj)
+}
+
+// StarterCode is the resolver for the starterCode field.
+func (r *questionResolver) StarterCode(ctx context.Context, obj *model.Question) ([]*model.StarterCode, error) {
+	return r.Workflow.QuestionStarterCode(ctx, obj)
+}
+
+// Signature is the resolver for the signature field.
+func (r *questionResolver) Signature(ctx context.Context, obj *model.Question) (*model.FunctionSignature, error) {
+	return r.Workflow.QuestionSignature(ctx, obj)
+}
+
+// Checker is the resolver for the checker field.
+func (r *questionResolver) Checker(ctx context.Context, obj *model.Question) (*model.Checker, error) {
+	return r.Workflow.QuestionChecker(ctx, obj)
+}
+
+// Problem is the resolver for the problem field.
+func (r *questionResolver) Problem(ctx context.Context, obj *model.Question) (*model.Problem, error) {
+	return r.Workflow.QuestionProblem(ctx, obj)
+}
+
+// Editorial is the resolver for the editorial field.
+func (r *questionResolver) Editorial(ctx context.Context, obj *model.Question) (*model.QuestionEditorial, error) {
+	return r.Workflow.QuestionEditorial(ctx, obj)
+}
+
+// TestResults is the resolver for the testResults field.
+func (r *submissionResolver) TestResults(ctx context.Context, obj *model.Submission) ([]*model.TestResult, error) {
+	return r.Workflow.SubmissionTestResults(ctx, obj)
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Problem returns ProblemResolver implementation.
+func (r *Resolver) Problem() ProblemResolver { return &problemResolver{r} }
+
+// ProblemList returns ProblemListResolver implementation.
+func (r *Resolver) ProblemList() ProblemListResolver { return &problemListResolver{r} }
+
+// ProblemRevision returns ProblemRevisionResolver implementation.
+func (r *Resolver) ProblemRevision() ProblemRevisionResolver { return &problemRevisionResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Question returns QuestionResolver implementation.
+func (r *Resolver) Question() QuestionResolver { return &questionResolver{r} }
+
+// Submission returns SubmissionResolver implementation.
+func (r *Resolver) Submission() SubmissionResolver { return &submissionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
+type problemResolver struct{ *Resolver }
+type problemListResolver struct{ *Resolver }
+type problemRevisionResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type questionResolver struct{ *Resolver }
+type submissionResolver struct{ *Resolver }
@@ -0,0 +1,162 @@
+// Package bundle reads and writes problem bundles: the on-disk form problems
+// are authored in before they are imported into the database.
+//
+// A bundle is a directory, or a .zip / .tar.gz archive of one, laid out as:
+//
+//	problem.json        metadata (see Metadata)
+//	statement.md        problem statement in markdown
+//	tests/<name>.in     test case input
+//	tests/<name>.out    expected output
+//	starter/<lang>.<ext>    optional starter code, one file per language
+//	solutions/<lang>.<ext>  reference solutions, one file per language
+//
+// Code files are named after their language, e.g. solutions/python.py.
+//
+// Test cases run in the order listed in problem.json. When the list is
+// omitted, every tests/*.in file is used in name order and none are samples.
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	MetadataFile  = "problem.json"
+	StatementFile = "statement.md"
+	testsDir      = "tests"
+	starterDir    = "starter"
+	solutionsDir  = "solutions"
+)
+
+// Limits used when problem.json leaves them out; they match the problems table defaults
+const (
+	DefaultTimeLimitMs   = 2000
+	DefaultMemoryLimitMb = 256
+)
+
+var difficulties = []string{"Easy", "Medium", "Hard"}
+
+// languageExtensions maps a language to the file extension used for its code
+var languageExtensions = map[string]string{
+	"c":          "c",
+	"cpp":        "cpp",
+	"go":         "go",
+	"java":       "java",
+	"javascript": "js",
+	"python":     "py",
+	"rust":       "rs",
+	"typescript": "ts",
+}
+
+// Metadata is the content of problem.json
+type Metadata struct {
+	Slug          string     `json:"slug"`
+	Title         string     `json:"title"`
+	Difficulty    string     `json:"difficulty"`
+	Topics        []string   `json:"topics,omitempty"`
+	Constraints   []string   `json:"constraints,omitempty"`
+	Hints         []string   `json:"hints,omitempty"`
+	TimeLimitMs   int        `json:"timeLimitMs,omitempty"`
+	MemoryLimitMb int        `json:"memoryLimitMb,omitempty"`
+	Tests         []TestMeta `json:"tests,omitempty"`
+}
+
+// TestMeta describes one test case; its data lives in tests/<name>.in and .out
+type TestMeta struct {
+	Name          string `json:"name"`
+	Sample        bool   `json:"sample,omitempty"`
+	Explanation   string `json:"explanation,omitempty"`
+	TimeLimitMs   *int   `json:"timeLimitMs,omitempty"`
+	MemoryLimitMb *int   `json:"memoryLimitMb,omitempty"`
+}
+
+// TestCase is a test case with its data loaded
+type TestCase struct {
+	TestMeta
+	Input  string
+	Output string
+}
+
+// Bundle is a fully loaded problem bundle
+type Bundle struct {
+	Metadata
+	Statement string
+	TestCases []TestCase
+	// StarterCode and Solutions map language to code
+	StarterCode map[string]string
+	Solutions   map[string]string
+}
+
+// Validate checks the bundle is complete enough to import
+func (b *Bundle) Validate() error {
+	var errs []error
+	if b.Slug == "" {
+		errs = append(errs, errors.New("slug is required"))
+	} else if !validSlug(b.Slug) {
+		errs = append(errs, fmt.Errorf("slug %q must be lower-case letters, digits and dashes", b.Slug))
+	}
+	if strings.TrimSpace(b.Title) == "" {
+		errs = append(errs, errors.New("title is required"))
+	}
+	if !validDifficulty(b.Difficulty) {
+		errs = append(errs, fmt.Errorf("invalid difficulty %q: must be one of %s", b.Difficulty, strings.Join(difficulties, ", ")))
+	}
+	if strings.TrimSpace(b.Statement) == "" {
+		errs = append(errs, fmt.Errorf("%s is empty", StatementFile))
+	}
+	if b.TimeLimitMs < 0 || b.MemoryLimitMb < 0 {
+		errs = append(errs, errors.New("limits must be positive"))
+	}
+	if len(b.TestCases) == 0 {
+		errs = append(errs, errors.New("bundle has no test cases"))
+	}
+	for _, tc := range b.TestCases {
+		if (tc.TimeLimitMs != nil && *tc.TimeLimitMs <= 0) || (tc.MemoryLimitMb != nil && *tc.MemoryLimitMb <= 0) {
+			errs = append(errs, fmt.Errorf("test %s: limits must be positive", tc.Name))
+		}
+	}
+	if len(b.Solutions) == 0 {
+		errs = append(errs, errors.New("bundle has no reference solutions"))
+	}
+	return errors.Join(errs...)
+}
+
+func validSlug(slug string) bool {
+	for _, r := range slug {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return !strings.HasPrefix(slug, "-") && !strings.HasSuffix(slug, "-")
+}
+
+func validDifficulty(difficulty string) bool {
+	for _, d := range difficulties {
+		if d == difficulty {
+			return true
+		}
+	}
+	return false
+}
+
+// languageForFile returns the language of a code file, which is its name
+// without the extension (e.g. "python.py" -> "python")
+func languageForFile(name string) (string, error) {
+	file := path.Base(name)
+	language := strings.TrimSuffix(file, path.Ext(file))
+	if language == "" {
+		return "", fmt.Errorf("%s: file name must be the language, e.g. python.py", name)
+	}
+	return language, nil
+}
+
+// codeFileName returns the file name code for language is stored under
+func codeFileName(language string) string {
+	if ext, ok := languageExtensions[language]; ok {
+		return language + "." + ext
+	}
+	return language + ".txt"
+}
@@ -0,0 +1,71 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(b *Bundle)
+		wantErr []string
+	}{
+		{name: "valid", edit: func(b *Bundle) {}},
+		{name: "missing slug", edit: func(b *Bundle) { b.Slug = "" }, wantErr: []string{"slug is required"}},
+		{name: "upper-case slug", edit: func(b *Bundle) { b.Slug = "Two-Sum" }, wantErr: []string{"lower-case letters"}},
+		{name: "slug ending in a dash", edit: func(b *Bundle) { b.Slug = "two-sum-" }, wantErr: []string{"lower-case letters"}},
+		{name: "blank title", edit: func(b *Bundle) { b.Title = " " }, wantErr: []string{"title is required"}},
+		{name: "unknown difficulty", edit: func(b *Bundle) { b.Difficulty = "easy" }, wantErr: []string{`invalid difficulty "easy"`}},
+		{name: "empty statement", edit: func(b *Bundle) { b.Statement = "\n" }, wantErr: []string{"statement.md is empty"}},
+		{name: "negative limit", edit: func(b *Bundle) { b.MemoryLimitMb = -1 }, wantErr: []string{"limits must be positive"}},
+		{name: "no test cases", edit: func(b *Bundle) { b.TestCases = nil }, wantErr: []string{"no test cases"}},
+		{
+			name:    "zero test limit",
+			edit:    func(b *Bundle) { b.TestCases[1].TimeLimitMs = intPtr(0) },
+			wantErr: []string{"test 02: limits must be positive"},
+		},
+		{name: "no solutions", edit: func(b *Bundle) { b.Solutions = nil }, wantErr: []string{"no reference solutions"}},
+		{
+			name: "every error at once",
+			edit: func(b *Bundle) { *b = Bundle{} },
+			wantErr: []string{
+				"slug is required", "title is required", "invalid difficulty", "statement.md is empty",
+				"no test cases", "no reference solutions",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBundle()
+			tt.edit(b)
+			err := b.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate(): %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLanguageForFile(t *testing.T) {
+	for _, language := range []string{"python", "cpp", "javascript", "kotlin"} {
+		name := "solutions/" + codeFileName(language)
+		if got, err := languageForFile(name); err != nil || got != language {
+			t.Errorf("languageForFile(%q) = %q, %v, want %q", name, got, err, language)
+		}
+	}
+	if got, err := languageForFile("solutions/.py"); err == nil {
+		t.Errorf("languageForFile(%q) = %q, want an error", "solutions/.py", got)
+	}
+}
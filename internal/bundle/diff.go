@@ -0,0 +1,147 @@
+package bundle
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"codestandoff/backend/internal/textdiff"
+)
+
+// Change is one difference between two versions of a bundle
+type Change struct {
+	// Op is "+" for something added, "-" for something removed and "~" for a change
+	Op     string
+	Field  string
+	Detail string
+}
+
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s", c.Op, c.Field)
+	}
+	return fmt.Sprintf("%s %s: %s", c.Op, c.Field, c.Detail)
+}
+
+// Diff lists what changes going from current to desired. A nil current means
+// the problem does not exist yet.
+func Diff(current, desired *Bundle) []Change {
+	if current == nil {
+		return []Change{{
+			Op:     "+",
+			Field:  "problem " + desired.Slug,
+			Detail: fmt.Sprintf("%d test cases, %d reference solutions", len(desired.TestCases), len(desired.Solutions)),
+		}}
+	}
+
+	var changes []Change
+	changed := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, Change{Op: "~", Field: field, Detail: fmt.Sprintf("%q -> %q", from, to)})
+		}
+	}
+
+	changed("title", current.Title, desired.Title)
+	changed("difficulty", current.Difficulty, desired.Difficulty)
+	changed("topics", strings.Join(current.Topics, ", "), strings.Join(desired.Topics, ", "))
+	if !equalStrings(current.Constraints, desired.Constraints) {
+		changes = append(changes, Change{Op: "~", Field: "constraints", Detail: fmt.Sprintf("%d -> %d entries", len(current.Constraints), len(desired.Constraints))})
+	}
+	if !equalStrings(current.Hints, desired.Hints) {
+		changes = append(changes, Change{Op: "~", Field: "hints", Detail: fmt.Sprintf("%d -> %d entries", len(current.Hints), len(desired.Hints))})
+	}
+	if current.Statement != desired.Statement {
+		changes = append(changes, Change{Op: "~", Field: "statement", Detail: lineSummary(current.Statement, desired.Statement)})
+	}
+	changed("timeLimitMs", fmt.Sprint(current.TimeLimitMs), fmt.Sprint(desired.TimeLimitMs))
+	changed("memoryLimitMb", fmt.Sprint(current.MemoryLimitMb), fmt.Sprint(desired.MemoryLimitMb))
+
+	for i := 0; i < len(current.TestCases) || i < len(desired.TestCases); i++ {
+		field := fmt.Sprintf("test %d", i+1)
+		switch {
+		case i >= len(current.TestCases):
+			changes = append(changes, Change{Op: "+", Field: field})
+		case i >= len(desired.TestCases):
+			changes = append(changes, Change{Op: "-", Field: field})
+		default:
+			if parts := testCaseChanges(current.TestCases[i], desired.TestCases[i]); len(parts) > 0 {
+				changes = append(changes, Change{Op: "~", Field: field, Detail: strings.Join(parts, ", ")})
+			}
+		}
+	}
+
+	changes = append(changes, codeChanges("starter code", current.StarterCode, desired.StarterCode)...)
+	changes = append(changes, codeChanges("solution", current.Solutions, desired.Solutions)...)
+
+	return changes
+}
+
+func testCaseChanges(current, desired TestCase) []string {
+	var parts []string
+	if current.Input != desired.Input {
+		parts = append(parts, "input")
+	}
+	if current.Output != desired.Output {
+		parts = append(parts, "output")
+	}
+	if current.Sample != desired.Sample {
+		parts = append(parts, fmt.Sprintf("sample %t -> %t", current.Sample, desired.Sample))
+	}
+	if current.Explanation != desired.Explanation {
+		parts = append(parts, "explanation")
+	}
+	if !reflect.DeepEqual(current.TimeLimitMs, desired.TimeLimitMs) || !reflect.DeepEqual(current.MemoryLimitMb, desired.MemoryLimitMb) {
+		parts = append(parts, "limits")
+	}
+	return parts
+}
+
+func codeChanges(kind string, current, desired map[string]string) []Change {
+	languages := map[string]bool{}
+	for language := range current {
+		languages[language] = true
+	}
+	for language := range desired {
+		languages[language] = true
+	}
+	sorted := make([]string, 0, len(languages))
+	for language := range languages {
+		sorted = append(sorted, language)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, language := range sorted {
+		from, had := current[language]
+		to, has := desired[language]
+		field := fmt.Sprintf("%s %s", kind, language)
+		switch {
+		case !had:
+			changes = append(changes, Change{Op: "+", Field: field})
+		case !has:
+			changes = append(changes, Change{Op: "-", Field: field})
+		case from != to:
+			changes = append(changes, Change{Op: "~", Field: field, Detail: lineSummary(from, to)})
+		}
+	}
+	return changes
+}
+
+// lineSummary describes how many lines were added and removed between two texts
+func lineSummary(from, to string) string {
+	inserted, deleted := textdiff.Stats(from, to)
+	return fmt.Sprintf("+%d -%d lines", inserted, deleted)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
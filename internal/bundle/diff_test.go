@@ -0,0 +1,83 @@
+package bundle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(b *Bundle)
+		want []string
+	}{
+		{name: "unchanged", edit: func(b *Bundle) {}},
+		{
+			name: "metadata",
+			edit: func(b *Bundle) {
+				b.Title = "Two Sum II"
+				b.Difficulty = "Medium"
+				b.Topics = []string{"array"}
+				b.Hints = nil
+				b.TimeLimitMs = 2000
+			},
+			want: []string{
+				`~ title: "Two Sum" -> "Two Sum II"`,
+				`~ difficulty: "Easy" -> "Medium"`,
+				`~ topics: "array, hash-table" -> "array"`,
+				"~ hints: 1 -> 0 entries",
+				`~ timeLimitMs: "1000" -> "2000"`,
+			},
+		},
+		{
+			name: "statement",
+			edit: func(b *Bundle) { b.Statement += "Return the indices.\n" },
+			want: []string{"~ statement: +1 -0 lines"},
+		},
+		{
+			name: "test cases",
+			edit: func(b *Bundle) {
+				b.TestCases[0].Output = "[1,0]\n"
+				b.TestCases[0].Sample = false
+				b.TestCases[1].TimeLimitMs = nil
+				b.TestCases = append(b.TestCases, TestCase{Input: "[1]\n1\n", Output: "[]\n"})
+			},
+			want: []string{"~ test 1: output, sample true -> false", "~ test 2: limits", "+ test 3"},
+		},
+		{
+			name: "removed test case",
+			edit: func(b *Bundle) { b.TestCases = b.TestCases[:1] },
+			want: []string{"- test 2"},
+		},
+		{
+			name: "code",
+			edit: func(b *Bundle) {
+				b.StarterCode["go"] = "package main\n"
+				delete(b.Solutions, "cpp")
+				b.Solutions["python"] = "class Solution:\n    def twoSum(self):\n        pass\n"
+			},
+			want: []string{"+ starter code go", "- solution cpp", "~ solution python: +2 -1 lines"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := testBundle()
+			tt.edit(desired)
+			var got []string
+			for _, c := range Diff(testBundle(), desired) {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffNewProblem(t *testing.T) {
+	got := Diff(nil, testBundle())
+	want := []Change{{Op: "+", Field: "problem two-sum", Detail: "2 test cases, 2 reference solutions"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff(nil, b) = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,385 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxFileSize caps the size of a single file read from a bundle
+const maxFileSize = 64 << 20
+
+// Read loads a bundle from a directory, .zip, .tar.gz or .tgz file
+func Read(src string) (*Bundle, error) {
+	files, err := readFiles(src)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decode(files)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	return b, nil
+}
+
+// Write stores a bundle as a directory, or as an archive when dst ends in .zip, .tar.gz or .tgz
+func Write(b *Bundle, dst string) error {
+	files, err := encode(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasSuffix(dst, ".zip"):
+		return writeZip(files, dst)
+	case strings.HasSuffix(dst, ".tar.gz"), strings.HasSuffix(dst, ".tgz"):
+		return writeTarGz(files, dst)
+	default:
+		return writeDir(files, dst)
+	}
+}
+
+// decode builds a bundle from its files, keyed by slash-separated path
+func decode(files map[string][]byte) (*Bundle, error) {
+	raw, ok := files[MetadataFile]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", MetadataFile)
+	}
+	b := &Bundle{StarterCode: map[string]string{}, Solutions: map[string]string{}}
+	if err := json.Unmarshal(raw, &b.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MetadataFile, err)
+	}
+	b.Statement = string(files[StatementFile])
+	if b.TimeLimitMs == 0 {
+		b.TimeLimitMs = DefaultTimeLimitMs
+	}
+	if b.MemoryLimitMb == 0 {
+		b.MemoryLimitMb = DefaultMemoryLimitMb
+	}
+
+	tests := b.Tests
+	if len(tests) == 0 {
+		for name := range files {
+			if dir, file := path.Split(name); dir == testsDir+"/" && strings.HasSuffix(file, ".in") {
+				tests = append(tests, TestMeta{Name: strings.TrimSuffix(file, ".in")})
+			}
+		}
+		sort.Slice(tests, func(i, j int) bool { return tests[i].Name < tests[j].Name })
+	}
+	for _, t := range tests {
+		input, ok := files[path.Join(testsDir, t.Name+".in")]
+		if !ok {
+			return nil, fmt.Errorf("test %s: missing %s.in", t.Name, t.Name)
+		}
+		output, ok := files[path.Join(testsDir, t.Name+".out")]
+		if !ok {
+			return nil, fmt.Errorf("test %s: missing %s.out", t.Name, t.Name)
+		}
+		b.TestCases = append(b.TestCases, TestCase{TestMeta: t, Input: string(input), Output: string(output)})
+	}
+	b.Tests = nil
+
+	for name, data := range files {
+		dir, file := path.Split(name)
+		var target map[string]string
+		switch dir {
+		case starterDir + "/":
+			target = b.StarterCode
+		case solutionsDir + "/":
+			target = b.Solutions
+		default:
+			continue
+		}
+		language, err := languageForFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := target[language]; dup {
+			return nil, fmt.Errorf("%s: more than one %s file in %s", file, language, dir)
+		}
+		target[language] = string(data)
+	}
+
+	return b, nil
+}
+
+// encode lays a bundle out as files keyed by slash-separated path
+func encode(b *Bundle) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	meta := b.Metadata
+	meta.Tests = nil
+	width := len(fmt.Sprint(len(b.TestCases)))
+	if width < 2 {
+		width = 2
+	}
+	for i, tc := range b.TestCases {
+		t := tc.TestMeta
+		t.Name = fmt.Sprintf("%0*d", width, i+1)
+		meta.Tests = append(meta.Tests, t)
+		files[path.Join(testsDir, t.Name+".in")] = []byte(tc.Input)
+		files[path.Join(testsDir, t.Name+".out")] = []byte(tc.Output)
+	}
+
+	raw, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[MetadataFile] = append(raw, '\n')
+	files[StatementFile] = []byte(b.Statement)
+
+	for language, code := range b.StarterCode {
+		files[path.Join(starterDir, codeFileName(language))] = []byte(code)
+	}
+	for language, code := range b.Solutions {
+		files[path.Join(solutionsDir, codeFileName(language))] = []byte(code)
+	}
+
+	return files, nil
+}
+
+// readFiles loads every regular file of a bundle directory or archive
+func readFiles(src string) (map[string][]byte, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	switch {
+	case info.IsDir():
+		files, err = readDir(src)
+	case strings.HasSuffix(src, ".zip"):
+		files, err = readZip(src)
+	case strings.HasSuffix(src, ".tar.gz"), strings.HasSuffix(src, ".tgz"):
+		files, err = readTarGz(src)
+	default:
+		return nil, fmt.Errorf("%s: not a directory, .zip, .tar.gz or .tgz", src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+
+	return stripCommonDir(files), nil
+}
+
+func readDir(root string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		data, err := readLimited(os.Open(p))
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+func readZip(src string) (map[string][]byte, error) {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		name, err := cleanArchivePath(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readLimited(f.Open())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+func readTarGz(src string) (map[string][]byte, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanArchivePath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readLimited(io.NopCloser(tr), nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+		files[name] = data
+	}
+}
+
+func readLimited(r io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxFileSize)
+	}
+	return data, nil
+}
+
+// cleanArchivePath normalizes an archive entry name and rejects ones escaping the bundle
+func cleanArchivePath(name string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid path %q in archive", name)
+	}
+	return clean, nil
+}
+
+// stripCommonDir removes a single top-level directory shared by every file,
+// as archives are usually created from the bundle's parent directory
+func stripCommonDir(files map[string][]byte) map[string][]byte {
+	if _, ok := files[MetadataFile]; ok {
+		return files
+	}
+	prefix := ""
+	for name := range files {
+		first, _, found := strings.Cut(name, "/")
+		if !found || (prefix != "" && first != prefix) {
+			return files
+		}
+		prefix = first
+	}
+
+	stripped := make(map[string][]byte, len(files))
+	for name, data := range files {
+		stripped[strings.TrimPrefix(name, prefix+"/")] = data
+	}
+	return stripped
+}
+
+// writeDir writes files under dst, removing bundle files that are no longer part of it
+func writeDir(files map[string][]byte, dst string) error {
+	for _, dir := range []string{testsDir, starterDir, solutionsDir} {
+		entries, err := os.ReadDir(filepath.Join(dst, dir))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, e := range entries {
+			if _, keep := files[path.Join(dir, e.Name())]; !keep && e.Type().IsRegular() {
+				if err := os.Remove(filepath.Join(dst, dir, e.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedNames(files) {
+		p := filepath.Join(dst, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, files[name], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeZip(files map[string][]byte, dst string) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range sortedNames(files) {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, buf.Bytes(), 0o644)
+}
+
+func writeTarGz(files map[string][]byte, dst string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedNames(files) {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, buf.Bytes(), 0o644)
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
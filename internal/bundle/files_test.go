@@ -0,0 +1,247 @@
+package bundle
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func intPtr(n int) *int { return &n }
+
+func testBundle() *Bundle {
+	return &Bundle{
+		Metadata: Metadata{
+			Slug:          "two-sum",
+			Title:         "Two Sum",
+			Difficulty:    "Easy",
+			Topics:        []string{"array", "hash-table"},
+			Constraints:   []string{"2 <= n <= 10^4"},
+			Hints:         []string{"Use a map"},
+			TimeLimitMs:   1000,
+			MemoryLimitMb: 128,
+		},
+		Statement: "Find two numbers adding up to target.\n",
+		TestCases: []TestCase{
+			{TestMeta: TestMeta{Name: "01", Sample: true, Explanation: "2 + 7 = 9"}, Input: "[2,7,11,15]\n9\n", Output: "[0,1]\n"},
+			{TestMeta: TestMeta{Name: "02", TimeLimitMs: intPtr(3000)}, Input: "[3,3]\n6\n", Output: "[0,1]\n"},
+		},
+		StarterCode: map[string]string{"python": "class Solution:\n    pass\n"},
+		Solutions:   map[string]string{"python": "class Solution:\n    pass\n", "cpp": "int main() {}\n"},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	want := testBundle()
+	files, err := encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{MetadataFile, StatementFile, "tests/01.in", "tests/02.out", "starter/python.py", "solutions/cpp.cpp"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("encode did not write %s", name)
+		}
+	}
+
+	got, err := decode(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decode(encode(b)) = %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeNamesTests(t *testing.T) {
+	b := testBundle()
+	b.TestCases = nil
+	for i := 0; i < 100; i++ {
+		b.TestCases = append(b.TestCases, TestCase{TestMeta: TestMeta{Name: "case"}})
+	}
+	files, err := encode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"tests/001.in", "tests/010.out", "tests/100.in"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("encode did not write %s", name)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    *Bundle
+		wantErr string
+	}{
+		{
+			name: "defaults and tests in name order",
+			files: map[string]string{
+				MetadataFile:          `{"slug": "a", "title": "A", "difficulty": "Easy"}`,
+				StatementFile:         "statement",
+				"tests/b.in":          "2",
+				"tests/b.out":         "4",
+				"tests/a.in":          "1",
+				"tests/a.out":         "2",
+				"solutions/python.py": "print()",
+				"notes.txt":           "ignored",
+			},
+			want: &Bundle{
+				Metadata:  Metadata{Slug: "a", Title: "A", Difficulty: "Easy", TimeLimitMs: DefaultTimeLimitMs, MemoryLimitMb: DefaultMemoryLimitMb},
+				Statement: "statement",
+				TestCases: []TestCase{
+					{TestMeta: TestMeta{Name: "a"}, Input: "1", Output: "2"},
+					{TestMeta: TestMeta{Name: "b"}, Input: "2", Output: "4"},
+				},
+				StarterCode: map[string]string{},
+				Solutions:   map[string]string{"python": "print()"},
+			},
+		},
+		{
+			name: "listed tests only",
+			files: map[string]string{
+				MetadataFile:  `{"slug": "a", "tests": [{"name": "b", "sample": true}]}`,
+				"tests/a.in":  "1",
+				"tests/a.out": "2",
+				"tests/b.in":  "2",
+				"tests/b.out": "4",
+			},
+			want: &Bundle{
+				Metadata:    Metadata{Slug: "a", TimeLimitMs: DefaultTimeLimitMs, MemoryLimitMb: DefaultMemoryLimitMb},
+				TestCases:   []TestCase{{TestMeta: TestMeta{Name: "b", Sample: true}, Input: "2", Output: "4"}},
+				StarterCode: map[string]string{},
+				Solutions:   map[string]string{},
+			},
+		},
+		{name: "missing metadata", files: map[string]string{StatementFile: "x"}, wantErr: "missing problem.json"},
+		{name: "invalid metadata", files: map[string]string{MetadataFile: "{"}, wantErr: "invalid problem.json"},
+		{
+			name:    "missing output",
+			files:   map[string]string{MetadataFile: `{}`, "tests/a.in": "1"},
+			wantErr: "missing a.out",
+		},
+		{
+			name:    "missing listed input",
+			files:   map[string]string{MetadataFile: `{"tests": [{"name": "z"}]}`},
+			wantErr: "missing z.in",
+		},
+		{
+			name:    "two solutions in one language",
+			files:   map[string]string{MetadataFile: `{}`, "solutions/python.py": "a", "solutions/python.txt": "b"},
+			wantErr: "more than one python file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{}
+			for name, data := range tt.files {
+				files[name] = []byte(data)
+			}
+			got, err := decode(files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decode() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanArchivePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "problem.json", want: "problem.json"},
+		{name: "./tests/01.in", want: "tests/01.in"},
+		{name: "two-sum/tests/../problem.json", want: "two-sum/problem.json"},
+		{name: "tests//01.in", want: "tests/01.in"},
+		{name: "../problem.json", wantErr: true},
+		{name: "tests/../../problem.json", wantErr: true},
+		{name: "..", wantErr: true},
+		{name: "./../x", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+		{name: "/tests/01.in", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := cleanArchivePath(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("cleanArchivePath(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("cleanArchivePath(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestStripCommonDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{name: "shared directory", files: []string{"two-sum/problem.json", "two-sum/tests/01.in"}, want: []string{"problem.json", "tests/01.in"}},
+		{name: "bundle at the root", files: []string{"problem.json", "tests/01.in"}, want: []string{"problem.json", "tests/01.in"}},
+		{name: "two directories", files: []string{"a/problem.json", "b/tests/01.in"}, want: []string{"a/problem.json", "b/tests/01.in"}},
+		{name: "file next to the directory", files: []string{"a/problem.json", "README"}, want: []string{"README", "a/problem.json"}},
+	}
+	for _, tt := range tests {
+		files := map[string][]byte{}
+		for _, name := range tt.files {
+			files[name] = nil
+		}
+		if got := sortedNames(stripCommonDir(files)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: stripCommonDir(%v) = %v, want %v", tt.name, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	want := testBundle()
+	for _, name := range []string{"two-sum", "two-sum.zip", "two-sum.tar.gz"} {
+		dst := filepath.Join(t.TempDir(), name)
+		if err := Write(want, dst); err != nil {
+			t.Fatalf("Write(%s): %v", name, err)
+		}
+		got, err := Read(dst)
+		if err != nil {
+			t.Fatalf("Read(%s): %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Read(%s) = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestWriteDirRemovesStaleFiles(t *testing.T) {
+	dst := t.TempDir()
+	b := testBundle()
+	if err := Write(b, dst); err != nil {
+		t.Fatal(err)
+	}
+	b.TestCases = b.TestCases[:1]
+	delete(b.Solutions, "cpp")
+	if err := Write(b, dst); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.TestCases) != 1 || len(got.Solutions) != 1 {
+		t.Errorf("Read after rewrite has %d tests and %d solutions, want 1 and 1", len(got.TestCases), len(got.Solutions))
+	}
+}
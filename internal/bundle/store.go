@@ -0,0 +1,140 @@
+package bundle
+
+import (
+	"database/sql"
+	"fmt"
+
+	"codestandoff/backend/internal/database"
+)
+
+// Load builds the bundle for the question with the given slug from the
+// database. It returns sql.ErrNoRows when there is no such question.
+func Load(db *sql.DB, slug string) (*Bundle, error) {
+	q, err := database.GetQuestionBySlug(db, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		Metadata: Metadata{
+			Slug:          q.Slug,
+			Title:         q.Title,
+			Difficulty:    q.Difficulty,
+			Topics:        q.Topics,
+			Constraints:   q.Constraints,
+			Hints:         q.Hints,
+			TimeLimitMs:   DefaultTimeLimitMs,
+			MemoryLimitMb: DefaultMemoryLimitMb,
+		},
+		Statement:   q.Description,
+		StarterCode: map[string]string{},
+		Solutions:   map[string]string{},
+	}
+
+	p, err := database.GetProblemByID(db, q.ID)
+	switch {
+	case err == nil:
+		b.TimeLimitMs, b.MemoryLimitMb = p.TimeLimitMs, p.MemoryLimitMb
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	testCases, err := database.GetTestCases(db, q.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test cases: %w", err)
+	}
+	for _, tc := range testCases {
+		t := TestCase{
+			TestMeta: TestMeta{Sample: tc.IsSample, Explanation: tc.Explanation.String},
+			Input:    tc.Input,
+			Output:   tc.ExpectedOutput,
+		}
+		if tc.TimeLimitMs.Valid {
+			v := int(tc.TimeLimitMs.Int64)
+			t.TimeLimitMs = &v
+		}
+		if tc.MemoryLimitMb.Valid {
+			v := int(tc.MemoryLimitMb.Int64)
+			t.MemoryLimitMb = &v
+		}
+		b.TestCases = append(b.TestCases, t)
+	}
+
+	starterCode, err := database.GetStarterCode(db, q.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get starter code: %w", err)
+	}
+	for _, sc := range starterCode {
+		b.StarterCode[sc.Language] = sc.Code
+	}
+
+	solutions, err := database.GetReferenceSolutions(db, q.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reference solutions: %w", err)
+	}
+	for _, s := range solutions {
+		b.Solutions[s.Language] = s.Code
+	}
+
+	return b, nil
+}
+
+// ResolveTopics replaces the bundle's topics with their names in the topic
+// catalog, so they compare equal to what an import stores. Unknown topics are an error.
+func ResolveTopics(db *sql.DB, b *Bundle) error {
+	catalog, err := database.LoadTopicCatalog(db)
+	if err != nil {
+		return fmt.Errorf("failed to load topics: %w", err)
+	}
+
+	names := []string{}
+	seen := map[int]bool{}
+	for _, value := range b.Topics {
+		t, ok := catalog.Lookup(value)
+		if !ok {
+			return fmt.Errorf("unknown topic %q", value)
+		}
+		if !seen[t.ID] {
+			seen[t.ID] = true
+			names = append(names, t.Name)
+		}
+	}
+	b.Topics = names
+
+	return nil
+}
+
+// Import writes the bundle to the database, creating the problem or updating
+// the one with the same slug. Call Validate and ResolveTopics first.
+func Import(db *sql.DB, b *Bundle) (created bool, err error) {
+	params := database.SyncProblemParams{
+		Slug:          b.Slug,
+		Title:         b.Title,
+		Description:   b.Statement,
+		Difficulty:    b.Difficulty,
+		Topics:        b.Topics,
+		Constraints:   b.Constraints,
+		Hints:         b.Hints,
+		TimeLimitMs:   b.TimeLimitMs,
+		MemoryLimitMb: b.MemoryLimitMb,
+		StarterCode:   b.StarterCode,
+		Solutions:     b.Solutions,
+	}
+	for _, tc := range b.TestCases {
+		t := database.TestCaseParams{
+			Input:          tc.Input,
+			ExpectedOutput: tc.Output,
+			IsSample:       tc.Sample,
+			TimeLimitMs:    tc.TimeLimitMs,
+			MemoryLimitMb:  tc.MemoryLimitMb,
+		}
+		if tc.Explanation != "" {
+			explanation := tc.Explanation
+			t.Explanation = &explanation
+		}
+		params.TestCases = append(params.TestCases, t)
+	}
+
+	_, created, err = database.SyncProblem(db, params)
+	return created, err
+}
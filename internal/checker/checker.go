@@ -0,0 +1,265 @@
+// Package checker decides whether a program's output answers a test. A
+// problem picks its checker, stored as JSON:
+//
+//	{"kind": "float", "tolerance": 1e-6}
+//
+// Kinds are:
+//
+//   - exact: lines must match, ignoring trailing whitespace on each line and
+//     trailing blank lines. This is the default.
+//   - tokens: whitespace-separated tokens must match.
+//   - float: tokens must match, except that numbers may differ by the
+//     absolute or relative tolerance. Brackets, braces, commas and colons
+//     separate tokens too, so the JSON output of harnesses is compared number
+//     by number.
+//   - unordered_lines: the lines must match in any order.
+//   - program: a checker program supplied with the problem decides, see
+//     ProgramStdin.
+package checker
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is a way of checking output
+type Kind string
+
+const (
+	Exact          Kind = "exact"
+	Tokens         Kind = "tokens"
+	Float          Kind = "float"
+	UnorderedLines Kind = "unordered_lines"
+	Program        Kind = "program"
+)
+
+// Limits on a checker
+const (
+	DefaultTolerance = 1e-6
+	maxSourceBytes   = 64 * 1024
+	// maxQuoteLength bounds the length of output quoted in messages
+	maxQuoteLength = 64
+)
+
+// Spec is a problem's checker
+type Spec struct {
+	Kind Kind `json:"kind"`
+	// Tolerance is the error float checkers allow; 0 means DefaultTolerance
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// Language and Source are the checker program of program checkers. The
+	// program is compiled as a whole program of the language.
+	Language string `json:"language,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// Verdict is the outcome of checking one output
+type Verdict struct {
+	OK bool
+	// Message says why the output was rejected, or holds what a checker
+	// program printed
+	Message string
+}
+
+// Parse decodes and validates a JSON checker. Nil data is the exact checker.
+func Parse(data []byte) (*Spec, error) {
+	s := &Spec{Kind: Exact}
+	if data == nil {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid checker: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks that the checker is complete. It does not check that a
+// checker program's language exists.
+func (s *Spec) Validate() error {
+	switch s.Kind {
+	case Exact, Tokens, UnorderedLines:
+		if s.Tolerance != 0 || s.Language != "" || s.Source != "" {
+			return fmt.Errorf("%s checkers take no tolerance or program", s.Kind)
+		}
+	case Float:
+		if s.Tolerance < 0 || s.Tolerance >= 1 || math.IsNaN(s.Tolerance) {
+			return errors.New("tolerance must be between 0 and 1")
+		}
+		if s.Language != "" || s.Source != "" {
+			return errors.New("float checkers take no program")
+		}
+	case Program:
+		if s.Language == "" || strings.TrimSpace(s.Source) == "" {
+			return errors.New("program checkers need a language and source")
+		}
+		if len(s.Source) > maxSourceBytes {
+			return fmt.Errorf("checker source exceeds %d bytes", maxSourceBytes)
+		}
+		if s.Tolerance != 0 {
+			return errors.New("program checkers take no tolerance")
+		}
+	default:
+		return fmt.Errorf("unknown checker kind %q", s.Kind)
+	}
+	return nil
+}
+
+// String describes the checker on one line, e.g. "float (tolerance 1e-06)"
+func (s *Spec) String() string {
+	switch s.Kind {
+	case Float:
+		return fmt.Sprintf("float (tolerance %g)", s.tolerance())
+	case Program:
+		// The source is not shown, but a hash of it tells versions apart
+		sum := sha256.Sum256([]byte(s.Source))
+		return fmt.Sprintf("program (%s, sha256 %x)", s.Language, sum[:4])
+	}
+	return string(s.Kind)
+}
+
+func (s *Spec) tolerance() float64 {
+	if s.Tolerance == 0 {
+		return DefaultTolerance
+	}
+	return s.Tolerance
+}
+
+// Check compares output to the expected output with a built-in checker.
+// Program checkers are run by the caller.
+func (s *Spec) Check(output, expected string) Verdict {
+	switch s.Kind {
+	case Tokens:
+		return checkTokens(strings.Fields(output), strings.Fields(expected), nil)
+	case Float:
+		tolerance := s.tolerance()
+		return checkTokens(floatTokens(output), floatTokens(expected), func(got, want string) bool {
+			return closeEnough(got, want, tolerance)
+		})
+	case UnorderedLines:
+		return checkUnorderedLines(Lines(output), Lines(expected))
+	case Program:
+		return Verdict{Message: "checker program was not run"}
+	}
+	return checkLines(Lines(output), Lines(expected))
+}
+
+// Lines splits output into lines, ignoring trailing whitespace on each line
+// and trailing blank lines
+func Lines(s string) []string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func checkLines(got, want []string) Verdict {
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if got[i] != want[i] {
+			return Verdict{Message: fmt.Sprintf("Line %d: expected %s, found %s", i+1, quote(want[i]), quote(got[i]))}
+		}
+	}
+	if len(got) != len(want) {
+		return Verdict{Message: fmt.Sprintf("Expected %d lines, found %d", len(want), len(got))}
+	}
+	return Verdict{OK: true}
+}
+
+// checkTokens compares tokens one by one, with equal, or exact comparison if it is nil
+func checkTokens(got, want []string, equal func(got, want string) bool) Verdict {
+	for i := 0; i < len(got) && i < len(want); i++ {
+		same := got[i] == want[i]
+		if !same && equal != nil {
+			same = equal(got[i], want[i])
+		}
+		if !same {
+			return Verdict{Message: fmt.Sprintf("Token %d: expected %s, found %s", i+1, quote(want[i]), quote(got[i]))}
+		}
+	}
+	if len(got) != len(want) {
+		return Verdict{Message: fmt.Sprintf("Expected %d tokens, found %d", len(want), len(got))}
+	}
+	return Verdict{OK: true}
+}
+
+func checkUnorderedLines(got, want []string) Verdict {
+	if len(got) != len(want) {
+		return Verdict{Message: fmt.Sprintf("Expected %d lines, found %d", len(want), len(got))}
+	}
+	got, want = sorted(got), sorted(want)
+	for i := range got {
+		switch {
+		case got[i] < want[i]:
+			return Verdict{Message: fmt.Sprintf("Unexpected line %s", quote(got[i]))}
+		case got[i] > want[i]:
+			return Verdict{Message: fmt.Sprintf("Missing line %s", quote(want[i]))}
+		}
+	}
+	return Verdict{OK: true}
+}
+
+func sorted(lines []string) []string {
+	lines = append([]string{}, lines...)
+	sort.Strings(lines)
+	return lines
+}
+
+// floatTokens splits output at whitespace, and around brackets, braces,
+// commas and colons, which are tokens of their own
+func floatTokens(s string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range s {
+		separator := strings.ContainsRune("[]{},:", r)
+		if unicode.IsSpace(r) || separator {
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+			if separator {
+				tokens = append(tokens, string(r))
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// closeEnough reports whether two tokens are numbers within tolerance of
+// each other, absolutely or relative to want
+func closeEnough(got, want string, tolerance float64) bool {
+	g, err := strconv.ParseFloat(got, 64)
+	if err != nil || math.IsNaN(g) || math.IsInf(g, 0) {
+		return false
+	}
+	w, err := strconv.ParseFloat(want, 64)
+	if err != nil {
+		return false
+	}
+	diff := math.Abs(g - w)
+	return diff <= tolerance || diff <= tolerance*math.Abs(w)
+}
+
+// quote quotes output for a message, shortening it if it is long
+func quote(s string) string {
+	if len(s) > maxQuoteLength {
+		s = strings.ToValidUTF8(s[:maxQuoteLength], "") + "..."
+	}
+	return strconv.Quote(s)
+}
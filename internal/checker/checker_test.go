@@ -0,0 +1,230 @@
+package checker
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Spec
+		wantErr bool
+	}{
+		{name: "exact", data: `{"kind": "exact"}`, want: Spec{Kind: Exact}},
+		{name: "tokens", data: `{"kind": "tokens"}`, want: Spec{Kind: Tokens}},
+		{name: "float", data: `{"kind": "float", "tolerance": 1e-9}`, want: Spec{Kind: Float, Tolerance: 1e-9}},
+		{name: "float default tolerance", data: `{"kind": "float"}`, want: Spec{Kind: Float}},
+		{name: "unordered lines", data: `{"kind": "unordered_lines"}`, want: Spec{Kind: UnorderedLines}},
+		{name: "program", data: `{"kind": "program", "language": "python", "source": "exit(0)"}`, want: Spec{Kind: Program, Language: "python", Source: "exit(0)"}},
+		{name: "unknown kind", data: `{"kind": "regex"}`, wantErr: true},
+		{name: "kind defaults to exact", data: `{}`, want: Spec{Kind: Exact}},
+		{name: "empty kind", data: `{"kind": ""}`, wantErr: true},
+		{name: "invalid json", data: `{"kind": `, wantErr: true},
+		{name: "tolerance on exact", data: `{"kind": "exact", "tolerance": 0.1}`, wantErr: true},
+		{name: "program on tokens", data: `{"kind": "tokens", "language": "python", "source": "exit(0)"}`, wantErr: true},
+		{name: "negative tolerance", data: `{"kind": "float", "tolerance": -1e-6}`, wantErr: true},
+		{name: "tolerance of one", data: `{"kind": "float", "tolerance": 1}`, wantErr: true},
+		{name: "program on float", data: `{"kind": "float", "language": "python", "source": "exit(0)"}`, wantErr: true},
+		{name: "program without language", data: `{"kind": "program", "source": "exit(0)"}`, wantErr: true},
+		{name: "program without source", data: `{"kind": "program", "language": "python", "source": " \n"}`, wantErr: true},
+		{name: "program with tolerance", data: `{"kind": "program", "language": "python", "source": "exit(0)", "tolerance": 0.1}`, wantErr: true},
+		{name: "program too long", data: `{"kind": "program", "language": "python", "source": "` + strings.Repeat("#", maxSourceBytes+1) + `"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%s) = %+v, want an error", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%s): %v", tt.data, err)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse(%s) = %+v, want %+v", tt.data, *got, tt.want)
+			}
+		})
+	}
+
+	got, err := Parse(nil)
+	if err != nil || got.Kind != Exact {
+		t.Errorf("Parse(nil) = %+v, %v, want the exact checker", got, err)
+	}
+}
+
+func TestValidateNaNTolerance(t *testing.T) {
+	s := &Spec{Kind: Float, Tolerance: math.NaN()}
+	if err := s.Validate(); err == nil {
+		t.Error("Validate accepted a NaN tolerance")
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		spec Spec
+		want string
+	}{
+		{Spec{Kind: Exact}, "exact"},
+		{Spec{Kind: UnorderedLines}, "unordered_lines"},
+		{Spec{Kind: Float}, "float (tolerance 1e-06)"},
+		{Spec{Kind: Float, Tolerance: 0.25}, "float (tolerance 0.25)"},
+		{Spec{Kind: Program, Language: "python", Source: "exit(0)"}, "program (python, sha256 "},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.String(); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%+v.String() = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+
+	a := (&Spec{Kind: Program, Language: "python", Source: "exit(0)"}).String()
+	b := (&Spec{Kind: Program, Language: "python", Source: "exit(1)"}).String()
+	if a == b || strings.Contains(a, "exit") {
+		t.Errorf("program checkers with different sources are both %q", a)
+	}
+}
+
+type checkTest struct {
+	name             string
+	output, expected string
+	ok               bool
+	// message, if set, is the message the output is rejected with
+	message string
+}
+
+func runCheckTests(t *testing.T, spec Spec, tests []checkTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spec.Check(tt.output, tt.expected)
+			if got.OK != tt.ok {
+				t.Fatalf("Check(%q, %q) = %+v, want OK %v", tt.output, tt.expected, got, tt.ok)
+			}
+			if !got.OK && got.Message == "" {
+				t.Errorf("Check(%q, %q) rejected the output without a message", tt.output, tt.expected)
+			}
+			if tt.message != "" && got.Message != tt.message {
+				t.Errorf("Check(%q, %q) message = %q, want %q", tt.output, tt.expected, got.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckExact(t *testing.T) {
+	runCheckTests(t, Spec{Kind: Exact}, []checkTest{
+		{name: "same", output: "1 2\n3\n", expected: "1 2\n3\n", ok: true},
+		{name: "trailing spaces", output: "1 2  \n3\t\n", expected: "1 2\n3\n", ok: true},
+		{name: "expected trailing spaces", output: "1 2\n3\n", expected: "1 2 \n3 \n", ok: true},
+		{name: "no final newline", output: "1 2\n3", expected: "1 2\n3\n", ok: true},
+		{name: "trailing blank lines", output: "1 2\n3\n\n\n", expected: "1 2\n3", ok: true},
+		{name: "trailing blank lines with spaces", output: "1 2\n3\n  \n\t\n", expected: "1 2\n3", ok: true},
+		{name: "crlf", output: "1 2\r\n3\r\n", expected: "1 2\n3\n", ok: true},
+		{name: "empty", output: "", expected: "\n", ok: true},
+		{name: "leading spaces", output: " 1 2\n3\n", expected: "1 2\n3\n", message: `Line 1: expected "1 2", found " 1 2"`},
+		{name: "inner spaces", output: "1  2\n3\n", expected: "1 2\n3\n", message: `Line 1: expected "1 2", found "1  2"`},
+		{name: "blank line inside", output: "1 2\n\n3\n", expected: "1 2\n3\n", message: `Line 2: expected "3", found ""`},
+		{name: "different", output: "1 2\n4\n", expected: "1 2\n3\n", message: `Line 2: expected "3", found "4"`},
+		{name: "missing line", output: "1 2\n", expected: "1 2\n3\n", message: "Expected 2 lines, found 1"},
+		{name: "extra line", output: "1 2\n3\n4\n", expected: "1 2\n3\n", message: "Expected 2 lines, found 3"},
+		{name: "empty output", output: "", expected: "3\n", message: "Expected 1 lines, found 0"},
+		{name: "long line", output: strings.Repeat("a", 100), expected: "b", message: `Line 1: expected "b", found "` + strings.Repeat("a", maxQuoteLength) + `..."`},
+	})
+}
+
+func TestCheckTokens(t *testing.T) {
+	runCheckTests(t, Spec{Kind: Tokens}, []checkTest{
+		{name: "same", output: "1 2 3\n", expected: "1 2 3\n", ok: true},
+		{name: "different whitespace", output: "  1\n2\t\t3 \n\n", expected: "1 2 3", ok: true},
+		{name: "empty", output: " \n", expected: "", ok: true},
+		{name: "different", output: "1 2 4", expected: "1 2 3", message: `Token 3: expected "3", found "4"`},
+		{name: "numbers compared as text", output: "1.0", expected: "1", message: `Token 1: expected "1", found "1.0"`},
+		{name: "missing token", output: "1 2", expected: "1 2 3", message: "Expected 3 tokens, found 2"},
+		{name: "extra token", output: "1 2 3 4", expected: "1 2 3", message: "Expected 3 tokens, found 4"},
+	})
+}
+
+func TestCheckFloat(t *testing.T) {
+	// These values are exact in binary, so the boundaries are exact too
+	runCheckTests(t, Spec{Kind: Float, Tolerance: 0.25}, []checkTest{
+		{name: "same", output: "1.5 2", expected: "1.5 2", ok: true},
+		{name: "absolute at tolerance", output: "0.25", expected: "0", ok: true},
+		{name: "absolute past tolerance", output: "0.2501", expected: "0", message: `Token 1: expected "0", found "0.2501"`},
+		{name: "negative absolute at tolerance", output: "-0.25", expected: "0", ok: true},
+		{name: "relative at tolerance", output: "10", expected: "8", ok: true},
+		{name: "relative below", output: "6", expected: "8", ok: true},
+		{name: "relative past tolerance", output: "10.01", expected: "8", ok: false},
+		{name: "negative relative at tolerance", output: "-10", expected: "-8", ok: true},
+		{name: "sign differs", output: "-8", expected: "8", ok: false},
+	})
+
+	runCheckTests(t, Spec{Kind: Float}, []checkTest{
+		{name: "default tolerance", output: "0.000001", expected: "0", ok: true},
+		{name: "past default tolerance", output: "0.0000011", expected: "0", ok: false},
+		{name: "default relative tolerance", output: "1000001", expected: "1000000", ok: true},
+		{name: "past default relative tolerance", output: "1000002", expected: "1000000", ok: false},
+		{name: "negative zero", output: "-0", expected: "0", ok: true},
+		{name: "negative zero expected", output: "0.0", expected: "-0.0", ok: true},
+		{name: "exponent", output: "1.5e3", expected: "1500", ok: true},
+		{name: "integer as decimal", output: "3.0000000", expected: "3", ok: true},
+		{name: "NaN", output: "NaN", expected: "0", ok: false},
+		{name: "NaN expected", output: "0", expected: "nan", ok: false},
+		{name: "NaN as text", output: "nan", expected: "nan", ok: true},
+		{name: "infinity", output: "inf", expected: "1e308", ok: false},
+		{name: "overflow", output: "1e400", expected: "1e308", ok: false},
+		{name: "infinity as text", output: "inf", expected: "inf", ok: true},
+		{name: "infinity other spelling", output: "+Inf", expected: "inf", ok: false},
+		{name: "words", output: "YES 1.0", expected: "YES 1", ok: true},
+		{name: "different words", output: "NO 1", expected: "YES 1", message: `Token 1: expected "YES", found "NO"`},
+		{name: "number for word", output: "1", expected: "one", ok: false},
+		{name: "json", output: `[1.0000001,{"a":2}]`, expected: "[1, {\"a\": 2.0}]", ok: true},
+		{name: "json separators", output: "[1 2]", expected: "[1, 2]", message: `Token 3: expected ",", found "2"`},
+		{name: "missing number", output: "1", expected: "1 2", message: "Expected 2 tokens, found 1"},
+		{name: "whitespace and blank lines", output: "1\n\n  2  \n\n", expected: "1 2", ok: true},
+	})
+}
+
+func TestCheckUnorderedLines(t *testing.T) {
+	runCheckTests(t, Spec{Kind: UnorderedLines}, []checkTest{
+		{name: "same order", output: "a\nb\nc\n", expected: "a\nb\nc\n", ok: true},
+		{name: "other order", output: "c\na\nb\n", expected: "a\nb\nc\n", ok: true},
+		{name: "trailing whitespace", output: "b  \na\t\n\n\n", expected: "a\nb", ok: true},
+		{name: "duplicates", output: "a\nb\na\n", expected: "a\na\nb\n", ok: true},
+		{name: "different duplicates", output: "a\nb\nb\n", expected: "a\na\nb\n", message: `Missing line "a"`},
+		{name: "unexpected line", output: "a\nb\nc\n", expected: "a\nb\nd\n", message: `Unexpected line "c"`},
+		{name: "missing line sorted first", output: "a\nb\nz\n", expected: "a\nb\nc\n", message: `Missing line "c"`},
+		{name: "missing line", output: "a\nb\n", expected: "a\nb\nc\n", message: "Expected 3 lines, found 2"},
+		{name: "blank line inside", output: "a\n\nb\n", expected: "a\nb\n", message: "Expected 2 lines, found 3"},
+		{name: "leading spaces", output: " a\nb\n", expected: "a\nb\n", ok: false},
+	})
+}
+
+func TestCheckProgramKind(t *testing.T) {
+	// Program checkers are run by the grader; Check must not accept for them
+	s := Spec{Kind: Program, Language: "python", Source: "exit(0)"}
+	if got := s.Check("1", "1"); got.OK {
+		t.Errorf("Check with a program checker = %+v, want a rejection", got)
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"\n\n", nil},
+		{"a", []string{"a"}},
+		{"a \n b\t\n", []string{"a", " b"}},
+		{"a\r\n\r\nb\r\n", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		got := Lines(tt.in)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Exit codes of checker programs. Any other exit, or a crash, means the
+// checker itself failed.
+const (
+	ExitAccepted    = 0
+	ExitWrongAnswer = 1
+)
+
+// ProgramStdin returns what a checker program reads from stdin: a line with
+// the byte lengths of the test input, the expected output and the output to
+// check, followed by the three of them back to back. A checker in Python
+// reads them with:
+//
+//	import sys
+//	n = [int(x) for x in sys.stdin.buffer.readline().split()]
+//	test_input, expected, output = (sys.stdin.buffer.read(k).decode() for k in n)
+func ProgramStdin(input, expected, output string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d %d %d\n", len(input), len(expected), len(output))
+	b.WriteString(input)
+	b.WriteString(expected)
+	b.WriteString(output)
+	return b.Bytes()
+}
+
+// maxProgramMessageBytes bounds the message of a checker program
+const maxProgramMessageBytes = 256
+
+// ProgramVerdict interprets how a checker program exited. The first line it
+// printed is the message. It returns an error if the checker failed.
+func ProgramVerdict(exitCode int, stdout []byte) (Verdict, error) {
+	message, _, _ := strings.Cut(strings.TrimSpace(string(stdout)), "\n")
+	if len(message) > maxProgramMessageBytes {
+		message = message[:maxProgramMessageBytes] + "..."
+	}
+	message = strings.ToValidUTF8(message, "")
+	switch exitCode {
+	case ExitAccepted:
+		return Verdict{OK: true, Message: message}, nil
+	case ExitWrongAnswer:
+		return Verdict{Message: message}, nil
+	}
+	if message != "" {
+		return Verdict{}, fmt.Errorf("checker failed with exit code %d: %s", exitCode, message)
+	}
+	return Verdict{}, fmt.Errorf("checker failed with exit code %d", exitCode)
+}
@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgramStdin(t *testing.T) {
+	got := string(ProgramStdin("1 2\n", "3\n", "3"))
+	if want := "4 2 1\n1 2\n3\n3"; got != want {
+		t.Errorf("ProgramStdin = %q, want %q", got, want)
+	}
+
+	// Lengths are in bytes, not runes
+	got = string(ProgramStdin("é", "", ""))
+	if want := "2 0 0\né"; got != want {
+		t.Errorf("ProgramStdin = %q, want %q", got, want)
+	}
+}
+
+func TestProgramVerdict(t *testing.T) {
+	long := strings.Repeat("x", maxProgramMessageBytes+10)
+	tests := []struct {
+		name     string
+		exitCode int
+		stdout   string
+		want     Verdict
+		wantErr  bool
+	}{
+		{name: "accepted", exitCode: ExitAccepted, want: Verdict{OK: true}},
+		{name: "accepted with message", exitCode: ExitAccepted, stdout: "ok 3 numbers\n", want: Verdict{OK: true, Message: "ok 3 numbers"}},
+		{name: "wrong answer", exitCode: ExitWrongAnswer, stdout: "  sum is 5, not 6  \n", want: Verdict{Message: "sum is 5, not 6"}},
+		{name: "first line only", exitCode: ExitWrongAnswer, stdout: "\nfirst\nsecond\n", want: Verdict{Message: "first"}},
+		{name: "long message", exitCode: ExitWrongAnswer, stdout: long, want: Verdict{Message: long[:maxProgramMessageBytes] + "..."}},
+		{name: "invalid utf-8", exitCode: ExitWrongAnswer, stdout: "bad \xff\xfe byte", want: Verdict{Message: "bad  byte"}},
+		{name: "utf-8 cut by truncation", exitCode: ExitWrongAnswer, stdout: strings.Repeat("x", maxProgramMessageBytes-1) + "é", want: Verdict{Message: strings.Repeat("x", maxProgramMessageBytes-1) + "..."}},
+		{name: "checker error", exitCode: 2, stdout: "cannot parse", wantErr: true},
+		{name: "checker error without output", exitCode: 3, wantErr: true},
+		{name: "crash", exitCode: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProgramVerdict(tt.exitCode, []byte(tt.stdout))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ProgramVerdict(%d, %q) = %+v, want an error", tt.exitCode, tt.stdout, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProgramVerdict(%d, %q): %v", tt.exitCode, tt.stdout, err)
+			}
+			if got != tt.want {
+				t.Errorf("ProgramVerdict(%d, %q) = %+v, want %+v", tt.exitCode, tt.stdout, got, tt.want)
+			}
+		})
+	}
+}
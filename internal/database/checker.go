@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetQuestionChecker retrieves the checker JSON of a question, or nil if its outputs are compared exactly
+func GetQuestionChecker(db *sql.DB, questionID int) ([]byte, error) {
+	var checker []byte
+	err := db.QueryRow(`SELECT checker FROM questions WHERE id = $1`, questionID).Scan(&checker)
+	if err != nil {
+		return nil, err
+	}
+	return checker, nil
+}
+
+// SetQuestionChecker sets the checker of a question; nil restores exact comparison
+func SetQuestionChecker(db *sql.DB, questionID int, checker []byte) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE questions SET checker = $2, updated_at = NOW() WHERE id = $1`, questionID, nullJSON(checker))
+	if err != nil {
+		return fmt.Errorf("failed to update checker: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCodeRunRateLimited is returned when a user ran code too often recently
+var ErrCodeRunRateLimited = errors.New("too many code runs, please wait a moment")
+
+// RecordCodeRun records that a user is running code, unless they already ran
+// code limit times within window, in which case it returns
+// ErrCodeRunRateLimited. Runs racing each other can exceed the limit.
+func RecordCodeRun(db *sql.DB, userID uuid.UUID, limit int, window time.Duration) error {
+	_, err := db.Exec(`
+		DELETE FROM code_runs
+		WHERE user_id = $1 AND created_at < NOW() - $2 * INTERVAL '1 millisecond'
+	`, userID, window.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to purge code runs: %w", err)
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO code_runs (user_id)
+		SELECT $1
+		WHERE (
+			SELECT COUNT(*) FROM code_runs
+			WHERE user_id = $1 AND created_at >= NOW() - $3 * INTERVAL '1 millisecond'
+		) < $2
+	`, userID, limit, window.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to record code run: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrCodeRunRateLimited
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons an editorial was unlocked
+const (
+	UnlockSolved = "solved"
+	UnlockGaveUp = "gave_up"
+)
+
+// Editorial explains the solution to a question
+type Editorial struct {
+	QuestionID      int
+	Body            string
+	TimeComplexity  sql.NullString
+	SpaceComplexity sql.NullString
+	UpdatedBy       uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// EditorialUnlock records when and why a user unlocked a question's editorial
+type EditorialUnlock struct {
+	UserID     uuid.UUID
+	QuestionID int
+	Reason     string
+	UnlockedAt time.Time
+}
+
+const editorialColumns = `question_id, body, time_complexity, space_complexity, updated_by, created_at, updated_at`
+
+func scanEditorial(row rowScanner) (*Editorial, error) {
+	e := &Editorial{}
+	err := row.Scan(&e.QuestionID, &e.Body, &e.TimeComplexity, &e.SpaceComplexity, &e.UpdatedBy, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetEditorial retrieves the editorial of a question
+func GetEditorial(db *sql.DB, questionID int) (*Editorial, error) {
+	return scanEditorial(db.QueryRow(`SELECT `+editorialColumns+` FROM editorials WHERE question_id = $1`, questionID))
+}
+
+// UpsertEditorial creates or replaces the editorial of a question
+func UpsertEditorial(db *sql.DB, questionID int, body string, timeComplexity, spaceComplexity *string, updatedBy uuid.UUID) (*Editorial, error) {
+	return scanEditorial(db.QueryRow(`
+		INSERT INTO editorials (question_id, body, time_complexity, space_complexity, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (question_id) DO UPDATE SET
+			body = EXCLUDED.body,
+			time_complexity = EXCLUDED.time_complexity,
+			space_complexity = EXCLUDED.space_complexity,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING `+editorialColumns,
+		questionID, body, timeComplexity, spaceComplexity, updatedBy,
+	))
+}
+
+// GetEditorialUnlock retrieves a user's unlock of a question's editorial
+func GetEditorialUnlock(db *sql.DB, userID uuid.UUID, questionID int) (*EditorialUnlock, error) {
+	return getEditorialUnlock(db, userID, questionID)
+}
+
+func getEditorialUnlock(q queryer, userID uuid.UUID, questionID int) (*EditorialUnlock, error) {
+	u := &EditorialUnlock{}
+	err := q.QueryRow(`
+		SELECT user_id, question_id, reason, unlocked_at
+		FROM editorial_unlocks
+		WHERE user_id = $1 AND question_id = $2
+	`, userID, questionID).Scan(&u.UserID, &u.QuestionID, &u.Reason, &u.UnlockedAt)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// unlockEditorial records that a user unlocked a question's editorial. The
+// first unlock wins; later ones leave it unchanged.
+func unlockEditorial(q queryer, userID uuid.UUID, questionID int, reason string) error {
+	_, err := q.Exec(`
+		INSERT INTO editorial_unlocks (user_id, question_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, question_id) DO NOTHING
+	`, userID, questionID, reason)
+	return err
+}
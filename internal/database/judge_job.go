@@ -0,0 +1,212 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Judge job states. Done and failed are final; a failed job gave up after
+// too many attempts.
+const (
+	JudgeJobQueued  = "queued"
+	JudgeJobRunning = "running"
+	JudgeJobDone    = "done"
+	JudgeJobFailed  = "failed"
+)
+
+// JudgeJob is a submission waiting to be judged, or being judged by a worker
+type JudgeJob struct {
+	ID           int64
+	SubmissionID uuid.UUID
+	UserID       uuid.UUID
+	Status       string
+	// Attempts counts claims, including the current one
+	Attempts    int
+	RunAt       time.Time
+	Worker      sql.NullString
+	HeartbeatAt sql.NullTime
+	LastError   sql.NullString
+	CreatedAt   time.Time
+	StartedAt   sql.NullTime
+	FinishedAt  sql.NullTime
+}
+
+const judgeJobColumns = `id, submission_id, user_id, status, attempts, run_at, worker, heartbeat_at, last_error, created_at, started_at, finished_at`
+
+func scanJudgeJob(row rowScanner) (*JudgeJob, error) {
+	j := &JudgeJob{}
+	err := row.Scan(
+		&j.ID,
+		&j.SubmissionID,
+		&j.UserID,
+		&j.Status,
+		&j.Attempts,
+		&j.RunAt,
+		&j.Worker,
+		&j.HeartbeatAt,
+		&j.LastError,
+		&j.CreatedAt,
+		&j.StartedAt,
+		&j.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// enqueueJudgeJob queues a submission for judging. Call it inside the
+// transaction that creates the submission.
+func enqueueJudgeJob(q queryer, submissionID, userID uuid.UUID) error {
+	_, err := q.Exec(`INSERT INTO judge_jobs (submission_id, user_id) VALUES ($1, $2)`, submissionID, userID)
+	return err
+}
+
+// ClaimJudgeJob marks the oldest due queued job as running by worker and
+// returns it, or returns nil if there is none. Jobs of users who already have
+// userLimit jobs running are skipped. Rows other workers are claiming are
+// skipped rather than waited for, so a claim racing another for the same user
+// can exceed the limit by one.
+func ClaimJudgeJob(db *sql.DB, worker string, userLimit int) (*JudgeJob, error) {
+	j, err := scanJudgeJob(db.QueryRow(`
+		UPDATE judge_jobs
+		SET status = $1,
+			attempts = attempts + 1,
+			worker = $2,
+			heartbeat_at = NOW(),
+			started_at = NOW()
+		WHERE id = (
+			SELECT j.id
+			FROM judge_jobs j
+			WHERE j.status = $3
+				AND j.run_at <= NOW()
+				AND (SELECT COUNT(*) FROM judge_jobs r WHERE r.user_id = j.user_id AND r.status = $1) < $4
+			ORDER BY j.run_at, j.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+judgeJobColumns,
+		JudgeJobRunning, worker, JudgeJobQueued, userLimit,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim judge job: %w", err)
+	}
+	return j, nil
+}
+
+// HeartbeatJudgeJob records that worker is still running a job. It reports
+// false if the job is no longer the worker's, because it was recovered as stale.
+func HeartbeatJudgeJob(db *sql.DB, id int64, worker string) (bool, error) {
+	res, err := db.Exec(`
+		UPDATE judge_jobs SET heartbeat_at = NOW()
+		WHERE id = $1 AND worker = $2 AND status = $3
+	`, id, worker, JudgeJobRunning)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CompleteJudgeJob marks a job worker is running as done
+func CompleteJudgeJob(db *sql.DB, id int64, worker string) error {
+	return endJudgeJob(db, id, worker, JudgeJobDone, "")
+}
+
+// FailJudgeJob marks a job worker is running as failed for good
+func FailJudgeJob(db *sql.DB, id int64, worker, lastError string) error {
+	return endJudgeJob(db, id, worker, JudgeJobFailed, lastError)
+}
+
+func endJudgeJob(db *sql.DB, id int64, worker, status, lastError string) error {
+	_, err := db.Exec(`
+		UPDATE judge_jobs
+		SET status = $3,
+			last_error = COALESCE(NULLIF($4, ''), last_error),
+			finished_at = NOW()
+		WHERE id = $1 AND worker = $2 AND status = $5
+	`, id, worker, status, lastError, JudgeJobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to finish judge job: %w", err)
+	}
+	return nil
+}
+
+// RetryJudgeJob queues a job worker is running again, to be claimed after delay
+func RetryJudgeJob(db *sql.DB, id int64, worker string, delay time.Duration, lastError string) error {
+	_, err := db.Exec(`
+		UPDATE judge_jobs
+		SET status = $3,
+			run_at = NOW() + $4 * INTERVAL '1 millisecond',
+			worker = NULL,
+			heartbeat_at = NULL,
+			last_error = NULLIF($5, '')
+		WHERE id = $1 AND worker = $2 AND status = $6
+	`, id, worker, JudgeJobQueued, delay.Milliseconds(), lastError, JudgeJobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to retry judge job: %w", err)
+	}
+	return nil
+}
+
+// RecoverJudgeJobs queues running jobs again whose worker has not sent a
+// heartbeat for staleAfter, which means it crashed or lost the database.
+// The claim that recovered jobs get next counts as another attempt.
+func RecoverJudgeJobs(db *sql.DB, staleAfter time.Duration) (int64, error) {
+	res, err := db.Exec(`
+		UPDATE judge_jobs
+		SET status = $1,
+			run_at = NOW(),
+			worker = NULL,
+			heartbeat_at = NULL,
+			last_error = 'worker ' || worker || ' stopped responding'
+		WHERE status = $2 AND heartbeat_at < NOW() - $3 * INTERVAL '1 millisecond'
+	`, JudgeJobQueued, JudgeJobRunning, staleAfter.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to recover judge jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// PurgeJudgeJobs deletes jobs that finished more than olderThan ago
+func PurgeJudgeJobs(db *sql.DB, olderThan time.Duration) (int64, error) {
+	res, err := db.Exec(`
+		DELETE FROM judge_jobs
+		WHERE finished_at < NOW() - $1 * INTERVAL '1 millisecond'
+	`, olderThan.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge judge jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// JudgeQueueStats describes the judge queue right now
+type JudgeQueueStats struct {
+	// Queued counts jobs waiting, including ones backing off after a failed attempt
+	Queued  int
+	Running int
+	// Finished counts jobs that finished within the window the stats were taken over
+	Finished int
+}
+
+// GetJudgeQueueStats counts queued and running jobs, and jobs finished within window
+func GetJudgeQueueStats(db *sql.DB, window time.Duration) (*JudgeQueueStats, error) {
+	s := &JudgeQueueStats{}
+	err := db.QueryRow(`
+		SELECT
+			COUNT(*) FILTER (WHERE status = $1),
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE finished_at >= NOW() - $3 * INTERVAL '1 millisecond')
+		FROM judge_jobs
+	`, JudgeJobQueued, JudgeJobRunning, window.Milliseconds()).Scan(&s.Queued, &s.Running, &s.Finished)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get judge queue stats: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match is a 1v1 match on a problem, pinned to the revision it is played against
+type Match struct {
+	ID         uuid.UUID
+	Player1ID  uuid.UUID
+	Player2ID  uuid.NullUUID
+	Status     string
+	QuestionID int
+	RevisionID int64
+	CreatedAt  time.Time
+}
+
+const matchColumns = `id, player1_id, player2_id, status, question_id, revision_id, created_at`
+
+func scanMatch(row rowScanner) (*Match, error) {
+	m := &Match{}
+	err := row.Scan(&m.ID, &m.Player1ID, &m.Player2ID, &m.Status, &m.QuestionID, &m.RevisionID, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CreateMatch opens a match on a problem, pinned to the problem's current revision
+func CreateMatch(db *sql.DB, player1ID uuid.UUID, questionID int) (*Match, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	revisionID, err := snapshotRevision(tx, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin revision: %w", err)
+	}
+
+	m, err := scanMatch(tx.QueryRow(`
+		INSERT INTO matches (id, player1_id, status, question_id, revision_id)
+		VALUES ($1, $2, 'waiting', $3, $4)
+		RETURNING `+matchColumns,
+		uuid.New(), player1ID, questionID, revisionID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert match: %w", err)
+	}
+
+	return m, tx.Commit()
+}
+
+// GetMatchByID retrieves a match by ID
+func GetMatchByID(db *sql.DB, id uuid.UUID) (*Match, error) {
+	return scanMatch(db.QueryRow(`SELECT `+matchColumns+` FROM matches WHERE id = $1`, id))
+}
+
+// GetAllMatches retrieves all matches, newest first
+func GetAllMatches(db *sql.DB) ([]*Match, error) {
+	rows, err := db.Query(`SELECT ` + matchColumns + ` FROM matches ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*Match
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
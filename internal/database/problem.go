@@ -0,0 +1,315 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Problem represents a competitive (1v1) problem. Problems are backed by a row
+// in public.questions, so the same catalog serves training and matches.
+type Problem struct {
+	QuestionID    int
+	Title         string
+	Slug          string
+	Description   string
+	Difficulty    string
+	Topics        []string
+	TimeLimitMs   int
+	MemoryLimitMb int
+	AuthorID      uuid.NullUUID
+	Status        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CreateProblemParams holds the fields needed to create a problem
+type CreateProblemParams struct {
+	Title         string
+	Description   string
+	Difficulty    string
+	Topics        []string
+	TimeLimitMs   int
+	MemoryLimitMb int
+	AuthorID      uuid.UUID
+}
+
+// UpdateProblemParams holds the fields to change on a problem; nil fields are left untouched
+type UpdateProblemParams struct {
+	Title         *string
+	Description   *string
+	Difficulty    *string
+	Topics        []string
+	Constraints   []string
+	Hints         []string
+	TimeLimitMs   *int
+	MemoryLimitMb *int
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+const problemSelect = `
+	SELECT q.id, q.title, q.slug, q.description, q.difficulty, q.topics, p.time_limit_ms, p.memory_limit_mb, q.author_id, q.status, p.created_at, p.updated_at
+	FROM problems p
+	JOIN questions q ON q.id = p.question_id
+`
+
+func scanProblem(row rowScanner) (*Problem, error) {
+	p := &Problem{}
+	err := row.Scan(
+		&p.QuestionID,
+		&p.Title,
+		&p.Slug,
+		&p.Description,
+		&p.Difficulty,
+		pq.Array(&p.Topics),
+		&p.TimeLimitMs,
+		&p.MemoryLimitMb,
+		&p.AuthorID,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetAllProblems retrieves all published problems, oldest first
+func GetAllProblems(db *sql.DB) ([]*Problem, error) {
+	return queryProblems(db, problemSelect+` WHERE q.status = $1 ORDER BY q.id ASC`, StatusPublished)
+}
+
+// GetProblemsByAuthor retrieves every problem a user authored, in any state, newest first
+func GetProblemsByAuthor(db *sql.DB, authorID uuid.UUID) ([]*Problem, error) {
+	return queryProblems(db, problemSelect+` WHERE q.author_id = $1 ORDER BY q.id DESC`, authorID)
+}
+
+// GetProblemsInReviewFor retrieves the problems in review that a user is assigned to and has not decided on yet
+func GetProblemsInReviewFor(db *sql.DB, reviewerID uuid.UUID) ([]*Problem, error) {
+	return queryProblems(db, problemSelect+`
+		JOIN problem_reviewers r ON r.question_id = q.id
+		WHERE r.reviewer_id = $1 AND r.decision IS NULL AND q.status = $2
+		ORDER BY r.assigned_at ASC
+	`, reviewerID, StatusInReview)
+}
+
+func queryProblems(db *sql.DB, query string, args ...interface{}) ([]*Problem, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []*Problem
+	for rows.Next() {
+		p, err := scanProblem(rows)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, p)
+	}
+
+	return problems, rows.Err()
+}
+
+// GetProblemByID retrieves a problem by its question ID
+func GetProblemByID(db *sql.DB, questionID int) (*Problem, error) {
+	return scanProblem(db.QueryRow(problemSelect+` WHERE p.question_id = $1`, questionID))
+}
+
+// slugAttempts bounds how often a create is retried after a concurrent create
+// took the slug it picked
+const slugAttempts = 3
+
+// CreateProblem inserts a question and marks it as a problem in a single transaction
+func CreateProblem(db *sql.DB, params CreateProblemParams) (*Problem, error) {
+	// The slug is checked before it is inserted, so a concurrent create with
+	// the same title can take it first; trying again picks the next free one
+	for attempt := 1; ; attempt++ {
+		p, err := createProblem(db, params)
+		if attempt < slugAttempts && isUniqueViolation(err, "idx_questions_slug") {
+			continue
+		}
+		return p, err
+	}
+}
+
+func createProblem(db *sql.DB, params CreateProblemParams) (*Problem, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	slug, err := uniqueSlug(tx, params.Title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate slug: %w", err)
+	}
+
+	topics := params.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	var questionID int
+	err = tx.QueryRow(`
+		INSERT INTO questions (title, slug, description, difficulty, topics, test_case_count, author_id)
+		VALUES ($1, $2, $3, $4, $5, 0, $6)
+		RETURNING id
+	`, params.Title, slug, params.Description, params.Difficulty, pq.Array(topics), params.AuthorID).Scan(&questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert question: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO problems (question_id, time_limit_ms, memory_limit_mb)
+		VALUES ($1, $2, $3)
+	`, questionID, params.TimeLimitMs, params.MemoryLimitMb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert problem: %w", err)
+	}
+
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return nil, err
+	}
+
+	p, err := scanProblem(tx.QueryRow(problemSelect+` WHERE p.question_id = $1`, questionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return p, tx.Commit()
+}
+
+// UpdateProblem updates a problem and its backing question
+func UpdateProblem(db *sql.DB, questionID int, params UpdateProblemParams) (*Problem, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var topics, constraints, hints interface{}
+	if params.Topics != nil {
+		topics = pq.Array(params.Topics)
+	}
+	if params.Constraints != nil {
+		constraints = pq.Array(params.Constraints)
+	}
+	if params.Hints != nil {
+		hints = pq.Array(params.Hints)
+	}
+
+	res, err := tx.Exec(`
+		UPDATE questions
+		SET title = COALESCE($2, title),
+			description = COALESCE($3, description),
+			difficulty = COALESCE($4, difficulty),
+			topics = COALESCE($5, topics),
+			constraints = COALESCE($6, constraints),
+			hints = COALESCE($7, hints),
+			updated_at = NOW()
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM problems WHERE question_id = $1)
+	`, questionID, params.Title, params.Description, params.Difficulty, topics, constraints, hints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+		UPDATE problems
+		SET time_limit_ms = COALESCE($2, time_limit_ms),
+			memory_limit_mb = COALESCE($3, memory_limit_mb),
+			updated_at = NOW()
+		WHERE question_id = $1
+	`, questionID, params.TimeLimitMs, params.MemoryLimitMb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update problem: %w", err)
+	}
+
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return nil, err
+	}
+
+	p, err := scanProblem(tx.QueryRow(problemSelect+` WHERE p.question_id = $1`, questionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return p, tx.Commit()
+}
+
+// DeleteProblem removes a question from the 1v1 pool. The question itself
+// stays in the catalog so training is unaffected.
+func DeleteProblem(db *sql.DB, questionID int) (bool, error) {
+	res, err := db.Exec(`DELETE FROM problems WHERE question_id = $1`, questionID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns a title into a URL-safe slug, e.g. "Two Sum II" -> "two-sum-ii"
+func Slugify(title string) string {
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(title), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "problem"
+	}
+	return slug
+}
+
+// isUniqueViolation reports whether err comes from a row violating the named
+// unique constraint or index
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// uniqueSlug returns a slug for title that is not yet used by any question
+func uniqueSlug(q queryer, title string) (string, error) {
+	return uniqueSlugIn(q, "questions", title)
+}
+
+// uniqueSlugIn returns a slug for title that is not yet used in table
+func uniqueSlugIn(q queryer, table, title string) (string, error) {
+	base := Slugify(title)
+	slug := base
+	for i := 2; ; i++ {
+		var exists bool
+		err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE slug = $1)`, slug).Scan(&exists)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
@@ -0,0 +1,408 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ProblemList is a curated, ordered collection of questions such as a study plan
+type ProblemList struct {
+	ID            int
+	Slug          string
+	Title         string
+	Description   sql.NullString
+	OwnerID       uuid.UUID
+	IsPublic      bool
+	QuestionCount int
+	FollowerCount int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// ProblemListSection is a titled group of questions within a list, e.g. one day of a plan
+type ProblemListSection struct {
+	ID          int
+	ListID      int
+	Title       string
+	Description sql.NullString
+	Position    int
+	Questions   []*Question
+}
+
+// ProblemListSectionParams describes a section when setting a list's contents
+type ProblemListSectionParams struct {
+	Title       string
+	Description *string
+	QuestionIDs []int
+}
+
+// ProblemListParams holds the fields for creating or updating a list. Nil
+// fields are left unchanged on update; nil Sections keeps the current contents.
+type ProblemListParams struct {
+	Title       *string
+	Description *string
+	IsPublic    *bool
+	Sections    []ProblemListSectionParams
+}
+
+// ProblemListFollow is a user's follow of a list
+type ProblemListFollow struct {
+	UserID      uuid.UUID
+	ListID      int
+	FollowedAt  time.Time
+	CompletedAt sql.NullTime
+}
+
+// Question counts only include published questions, so archived ones drop out of lists
+const problemListSelect = `
+	SELECT l.id, l.slug, l.title, l.description, l.owner_id, l.is_public,
+		(SELECT COUNT(*) FROM problem_list_items i JOIN questions q ON q.id = i.question_id
+			WHERE i.list_id = l.id AND q.status = 'published') AS question_count,
+		(SELECT COUNT(*) FROM problem_list_follows f WHERE f.list_id = l.id) AS follower_count,
+		l.created_at, l.updated_at
+	FROM problem_lists l`
+
+func scanProblemList(row rowScanner) (*ProblemList, error) {
+	l := &ProblemList{}
+	err := row.Scan(&l.ID, &l.Slug, &l.Title, &l.Description, &l.OwnerID, &l.IsPublic, &l.QuestionCount, &l.FollowerCount, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func queryProblemLists(db *sql.DB, query string, args ...interface{}) ([]*ProblemList, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*ProblemList
+	for rows.Next() {
+		l, err := scanProblemList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
+
+// GetVisibleProblemLists retrieves the public lists plus those owned by
+// viewerID (if any), most followed first
+func GetVisibleProblemLists(db *sql.DB, viewerID uuid.NullUUID) ([]*ProblemList, error) {
+	return queryProblemLists(db, problemListSelect+`
+		WHERE l.is_public OR l.owner_id = $1
+		ORDER BY follower_count DESC, l.id ASC
+	`, viewerID)
+}
+
+// GetFollowedProblemLists retrieves the lists a user follows, most recently followed first
+func GetFollowedProblemLists(db *sql.DB, userID uuid.UUID) ([]*ProblemList, error) {
+	return queryProblemLists(db, problemListSelect+`
+		JOIN problem_list_follows uf ON uf.list_id = l.id AND uf.user_id = $1
+		ORDER BY uf.followed_at DESC
+	`, userID)
+}
+
+// GetProblemListByID retrieves a list by ID
+func GetProblemListByID(db *sql.DB, id int) (*ProblemList, error) {
+	return scanProblemList(db.QueryRow(problemListSelect+` WHERE l.id = $1`, id))
+}
+
+// GetProblemListBySlug retrieves a list by slug
+func GetProblemListBySlug(db *sql.DB, slug string) (*ProblemList, error) {
+	return scanProblemList(db.QueryRow(problemListSelect+` WHERE l.slug = $1`, slug))
+}
+
+// CreateProblemList creates a list owned by ownerID with the given sections
+func CreateProblemList(db *sql.DB, ownerID uuid.UUID, params ProblemListParams) (*ProblemList, error) {
+	// As with problems, a concurrent create can take the slug first
+	for attempt := 1; ; attempt++ {
+		l, err := createProblemList(db, ownerID, params)
+		if attempt < slugAttempts && isUniqueViolation(err, "problem_lists_slug_key") {
+			continue
+		}
+		return l, err
+	}
+}
+
+func createProblemList(db *sql.DB, ownerID uuid.UUID, params ProblemListParams) (*ProblemList, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	slug, err := uniqueSlugIn(tx, "problem_lists", *params.Title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate slug: %w", err)
+	}
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO problem_lists (slug, title, description, owner_id, is_public)
+		VALUES ($1, $2, $3, $4, COALESCE($5, FALSE))
+		RETURNING id
+	`, slug, *params.Title, params.Description, ownerID, params.IsPublic).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert list: %w", err)
+	}
+
+	if err := setProblemListSections(tx, id, params.Sections); err != nil {
+		return nil, err
+	}
+
+	l, err := scanProblemList(tx.QueryRow(problemListSelect+` WHERE l.id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return l, tx.Commit()
+}
+
+// UpdateProblemList updates a list's details and, if Sections is set, replaces its contents
+func UpdateProblemList(db *sql.DB, id int, params ProblemListParams) (*ProblemList, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE problem_lists
+		SET title = COALESCE($2, title),
+			description = COALESCE($3, description),
+			is_public = COALESCE($4, is_public),
+			updated_at = NOW()
+		WHERE id = $1
+	`, id, params.Title, params.Description, params.IsPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update list: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if params.Sections != nil {
+		if _, err := tx.Exec(`DELETE FROM problem_list_sections WHERE list_id = $1`, id); err != nil {
+			return nil, err
+		}
+		if err := setProblemListSections(tx, id, params.Sections); err != nil {
+			return nil, err
+		}
+		// The contents changed, so re-evaluate which followers have completed the list
+		if err := refreshListCompletion(tx, `f.list_id = $1`, id); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := scanProblemList(tx.QueryRow(problemListSelect+` WHERE l.id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return l, tx.Commit()
+}
+
+func setProblemListSections(q queryer, listID int, sections []ProblemListSectionParams) error {
+	for i, section := range sections {
+		var sectionID int
+		err := q.QueryRow(`
+			INSERT INTO problem_list_sections (list_id, title, description, position)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, listID, section.Title, section.Description, i+1).Scan(&sectionID)
+		if err != nil {
+			return fmt.Errorf("failed to insert section: %w", err)
+		}
+
+		for j, questionID := range section.QuestionIDs {
+			_, err := q.Exec(`
+				INSERT INTO problem_list_items (list_id, section_id, question_id, position)
+				VALUES ($1, $2, $3, $4)
+			`, listID, sectionID, questionID, j+1)
+			if err != nil {
+				return fmt.Errorf("failed to add question %d: %w", questionID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteProblemList deletes a list with its sections and follows
+func DeleteProblemList(db *sql.DB, id int) (bool, error) {
+	res, err := db.Exec(`DELETE FROM problem_lists WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetProblemListSections retrieves the sections of a list in order, each with
+// its published questions in order
+func GetProblemListSections(db *sql.DB, listID int) ([]*ProblemListSection, error) {
+	rows, err := db.Query(`
+		SELECT id, list_id, title, description, position
+		FROM problem_list_sections
+		WHERE list_id = $1
+		ORDER BY position ASC
+	`, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []*ProblemListSection
+	byID := map[int]*ProblemListSection{}
+	for rows.Next() {
+		s := &ProblemListSection{Questions: []*Question{}}
+		if err := rows.Scan(&s.ID, &s.ListID, &s.Title, &s.Description, &s.Position); err != nil {
+			return nil, err
+		}
+		sections = append(sections, s)
+		byID[s.ID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemRows, err := db.Query(`
+		SELECT i.section_id, `+questionColumns+`
+		FROM problem_list_items i
+		JOIN questions q ON q.id = i.question_id
+		WHERE i.list_id = $1 AND q.status = 'published'
+		ORDER BY i.section_id, i.position ASC
+	`, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var sectionID int
+		q, err := scanQuestion(prefixScanner{itemRows, &sectionID})
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := byID[sectionID]; ok {
+			s.Questions = append(s.Questions, q)
+		}
+	}
+
+	return sections, itemRows.Err()
+}
+
+// prefixScanner scans leading columns into extra destinations before handing
+// the rest to another scan function
+type prefixScanner struct {
+	row   rowScanner
+	extra interface{}
+}
+
+func (p prefixScanner) Scan(dest ...interface{}) error {
+	return p.row.Scan(append([]interface{}{p.extra}, dest...)...)
+}
+
+// GetProblemListFollow retrieves a user's follow of a list
+func GetProblemListFollow(db *sql.DB, userID uuid.UUID, listID int) (*ProblemListFollow, error) {
+	f := &ProblemListFollow{}
+	err := db.QueryRow(`
+		SELECT user_id, list_id, followed_at, completed_at
+		FROM problem_list_follows
+		WHERE user_id = $1 AND list_id = $2
+	`, userID, listID).Scan(&f.UserID, &f.ListID, &f.FollowedAt, &f.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FollowProblemList makes a user follow a list; following twice is a no-op
+func FollowProblemList(db *sql.DB, userID uuid.UUID, listID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO problem_list_follows (user_id, list_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, list_id) DO NOTHING
+	`, userID, listID)
+	if err != nil {
+		return err
+	}
+
+	// Questions solved before following count towards completion
+	if err := refreshListCompletion(tx, `f.user_id = $1 AND f.list_id = $2`, userID, listID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnfollowProblemList stops a user following a list
+func UnfollowProblemList(db *sql.DB, userID uuid.UUID, listID int) error {
+	_, err := db.Exec(`DELETE FROM problem_list_follows WHERE user_id = $1 AND list_id = $2`, userID, listID)
+	return err
+}
+
+// GetSolvedQuestionIDs returns which of the given questions a user has solved
+func GetSolvedQuestionIDs(db *sql.DB, userID uuid.UUID, questionIDs []int) (map[int]bool, error) {
+	rows, err := db.Query(`
+		SELECT question_id FROM question_attempts
+		WHERE user_id = $1 AND question_id = ANY($2) AND solved_at IS NOT NULL
+	`, userID, pq.Array(questionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	solved := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		solved[id] = true
+	}
+
+	return solved, rows.Err()
+}
+
+// refreshListCompletion sets or clears completed_at on the follows matching
+// where, depending on whether the follower has solved every published
+// question on the list. Empty lists are never complete.
+func refreshListCompletion(q queryer, where string, args ...interface{}) error {
+	_, err := q.Exec(`
+		UPDATE problem_list_follows f
+		SET completed_at = CASE
+			WHEN EXISTS (
+				SELECT 1 FROM problem_list_items i
+				JOIN questions q ON q.id = i.question_id AND q.status = 'published'
+				WHERE i.list_id = f.list_id
+			) AND NOT EXISTS (
+				SELECT 1 FROM problem_list_items i
+				JOIN questions q ON q.id = i.question_id AND q.status = 'published'
+				LEFT JOIN question_attempts a ON a.question_id = i.question_id AND a.user_id = f.user_id
+				WHERE i.list_id = f.list_id AND a.solved_at IS NULL
+			) THEN COALESCE(f.completed_at, NOW())
+			ELSE NULL END
+		WHERE `+where, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update list completion: %w", err)
+	}
+	return nil
+}
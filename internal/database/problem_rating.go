@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// unsolvedAfter is how long an attempt can stay unsolved before it counts as a failure
+const unsolvedAfter = "1 day"
+
+// RatingSample holds the solve outcomes of one question
+type RatingSample struct {
+	QuestionID int
+	Difficulty string
+	Outcomes   []AttemptOutcome
+}
+
+// AttemptOutcome is one user's result on a question, with their rating when they attempted it
+type AttemptOutcome struct {
+	UserRating int
+	Solved     bool
+}
+
+// ProblemRating is an estimated rating for a question
+type ProblemRating struct {
+	QuestionID int
+	Rating     int
+	Samples    int
+}
+
+// GetRatingSamples retrieves the outcomes of every question. An attempt is a
+// solve, or a failure once the user gave up or left it unsolved for a day;
+// attempts still in progress are left out.
+func GetRatingSamples(db *sql.DB) ([]*RatingSample, error) {
+	rows, err := db.Query(`
+		SELECT q.id, q.difficulty, a.user_rating, a.solved_at IS NOT NULL
+		FROM questions q
+		LEFT JOIN question_attempts a ON a.question_id = q.id
+			AND (a.solved_at IS NOT NULL OR a.gave_up_at IS NOT NULL OR a.created_at < NOW() - INTERVAL '` + unsolvedAfter + `')
+		ORDER BY q.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*RatingSample
+	var current *RatingSample
+	for rows.Next() {
+		var questionID int
+		var difficulty string
+		var userRating sql.NullInt64
+		var solved sql.NullBool
+		if err := rows.Scan(&questionID, &difficulty, &userRating, &solved); err != nil {
+			return nil, err
+		}
+		if current == nil || current.QuestionID != questionID {
+			current = &RatingSample{QuestionID: questionID, Difficulty: difficulty}
+			samples = append(samples, current)
+		}
+		// No attempts yet, from the LEFT JOIN
+		if !solved.Valid {
+			continue
+		}
+		current.Outcomes = append(current.Outcomes, AttemptOutcome{UserRating: int(userRating.Int64), Solved: solved.Bool})
+	}
+
+	return samples, rows.Err()
+}
+
+// SetProblemRatings stores estimated ratings, skipping questions whose rating
+// and sample count are unchanged. It returns the number of questions updated.
+func SetProblemRatings(db *sql.DB, ratings []ProblemRating) (int, error) {
+	ids := make([]int64, len(ratings))
+	values := make([]int64, len(ratings))
+	samples := make([]int64, len(ratings))
+	for i, r := range ratings {
+		ids[i] = int64(r.QuestionID)
+		values[i] = int64(r.Rating)
+		samples[i] = int64(r.Samples)
+	}
+
+	res, err := db.Exec(`
+		UPDATE questions q
+		SET rating = r.rating, rating_samples = r.samples, rating_updated_at = NOW()
+		FROM unnest($1::integer[], $2::integer[], $3::integer[]) AS r(id, rating, samples)
+		WHERE q.id = r.id AND (q.rating, q.rating_samples) IS DISTINCT FROM (r.rating, r.samples)
+	`, pq.Array(ids), pq.Array(values), pq.Array(samples))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Question lifecycle states
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// Review decisions
+const (
+	DecisionApproved         = "approved"
+	DecisionChangesRequested = "changes_requested"
+)
+
+// User roles
+const (
+	RoleUser     = "user"
+	RoleAuthor   = "author"
+	RoleReviewer = "reviewer"
+	RoleAdmin    = "admin"
+)
+
+// ErrStatusChanged is returned when a question is no longer in the state a transition expects
+var ErrStatusChanged = errors.New("question status changed concurrently")
+
+// ProblemReviewer is a reviewer assigned to a question
+type ProblemReviewer struct {
+	QuestionID int
+	ReviewerID uuid.UUID
+	AssignedBy uuid.NullUUID
+	AssignedAt time.Time
+	Decision   sql.NullString
+	DecidedAt  sql.NullTime
+}
+
+// ReviewComment is a comment left on a question during review
+type ReviewComment struct {
+	ID         int64
+	QuestionID int
+	AuthorID   uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+}
+
+// GetQuestionStatus returns the lifecycle state of a question
+func GetQuestionStatus(db *sql.DB, questionID int) (string, error) {
+	var status string
+	err := db.QueryRow(`SELECT status FROM questions WHERE id = $1`, questionID).Scan(&status)
+	return status, err
+}
+
+// SetQuestionStatus moves a question from one state to another. It returns
+// ErrStatusChanged if the question is not in the from state. Submitting for
+// review clears earlier review decisions.
+func SetQuestionStatus(db *sql.DB, questionID int, from, to string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setQuestionStatus(tx, questionID, from, to); err != nil {
+		return err
+	}
+	if to == StatusInReview {
+		if _, err := tx.Exec(`UPDATE problem_reviewers SET decision = NULL, decided_at = NULL WHERE question_id = $1`, questionID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func setQuestionStatus(q queryer, questionID int, from, to string) error {
+	res, err := q.Exec(`
+		UPDATE questions
+		SET status = $3,
+			published_at = CASE WHEN $3 = 'published' THEN NOW() ELSE published_at END,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, questionID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+// AssignReviewer adds a reviewer to a question; assigning the same reviewer twice is a no-op
+func AssignReviewer(db *sql.DB, questionID int, reviewerID, assignedBy uuid.UUID) error {
+	_, err := db.Exec(`
+		INSERT INTO problem_reviewers (question_id, reviewer_id, assigned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (question_id, reviewer_id) DO NOTHING
+	`, questionID, reviewerID, assignedBy)
+	return err
+}
+
+// GetProblemReviewers retrieves the reviewers assigned to a question, in assignment order
+func GetProblemReviewers(db *sql.DB, questionID int) ([]*ProblemReviewer, error) {
+	rows, err := db.Query(`
+		SELECT question_id, reviewer_id, assigned_by, assigned_at, decision, decided_at
+		FROM problem_reviewers
+		WHERE question_id = $1
+		ORDER BY assigned_at ASC
+	`, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviewers []*ProblemReviewer
+	for rows.Next() {
+		r := &ProblemReviewer{}
+		if err := rows.Scan(&r.QuestionID, &r.ReviewerID, &r.AssignedBy, &r.AssignedAt, &r.Decision, &r.DecidedAt); err != nil {
+			return nil, err
+		}
+		reviewers = append(reviewers, r)
+	}
+
+	return reviewers, rows.Err()
+}
+
+// IsProblemReviewer reports whether userID is assigned to review the question
+func IsProblemReviewer(db *sql.DB, questionID int, userID uuid.UUID) (bool, error) {
+	var assigned bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM problem_reviewers WHERE question_id = $1 AND reviewer_id = $2)`, questionID, userID).Scan(&assigned)
+	return assigned, err
+}
+
+// RecordReviewDecision stores a reviewer's decision on a question that is in
+// review and applies its effect: requesting changes sends the question back
+// to draft, and once every assigned reviewer has approved it is published.
+// A non-empty comment is added in the same transaction, so the decision and
+// the comment explaining it are stored together or not at all. It returns the
+// question's resulting status.
+func RecordReviewDecision(db *sql.DB, questionID int, reviewerID uuid.UUID, decision, comment string) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM questions WHERE id = $1 FOR UPDATE`, questionID).Scan(&status); err != nil {
+		return "", err
+	}
+	if status != StatusInReview {
+		return "", ErrStatusChanged
+	}
+
+	res, err := tx.Exec(`
+		UPDATE problem_reviewers SET decision = $3, decided_at = NOW()
+		WHERE question_id = $1 AND reviewer_id = $2
+	`, questionID, reviewerID, decision)
+	if err != nil {
+		return "", fmt.Errorf("failed to record decision: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return "", sql.ErrNoRows
+	}
+
+	switch decision {
+	case DecisionChangesRequested:
+		status = StatusDraft
+	case DecisionApproved:
+		var pending int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM problem_reviewers
+			WHERE question_id = $1 AND decision IS DISTINCT FROM 'approved'
+		`, questionID).Scan(&pending)
+		if err != nil {
+			return "", err
+		}
+		if pending == 0 {
+			status = StatusPublished
+		}
+	}
+
+	if status != StatusInReview {
+		if err := setQuestionStatus(tx, questionID, StatusInReview, status); err != nil {
+			return "", err
+		}
+	}
+
+	if comment != "" {
+		if _, err := addReviewComment(tx, questionID, reviewerID, comment); err != nil {
+			return "", fmt.Errorf("failed to add comment: %w", err)
+		}
+	}
+
+	return status, tx.Commit()
+}
+
+// AddReviewComment adds a review comment to a question
+func AddReviewComment(db *sql.DB, questionID int, authorID uuid.UUID, body string) (*ReviewComment, error) {
+	return addReviewComment(db, questionID, authorID, body)
+}
+
+func addReviewComment(q queryer, questionID int, authorID uuid.UUID, body string) (*ReviewComment, error) {
+	c := &ReviewComment{}
+	err := q.QueryRow(`
+		INSERT INTO problem_review_comments (question_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, question_id, author_id, body, created_at
+	`, questionID, authorID, body).Scan(&c.ID, &c.QuestionID, &c.AuthorID, &c.Body, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetReviewComments retrieves the review comments on a question, oldest first
+func GetReviewComments(db *sql.DB, questionID int) ([]*ReviewComment, error) {
+	rows, err := db.Query(`
+		SELECT id, question_id, author_id, body, created_at
+		FROM problem_review_comments
+		WHERE question_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*ReviewComment
+	for rows.Next() {
+		c := &ReviewComment{}
+		if err := rows.Scan(&c.ID, &c.QuestionID, &c.AuthorID, &c.Body, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// GetUserRole returns the role of a user
+func GetUserRole(db *sql.DB, userID uuid.UUID) (string, error) {
+	var role string
+	err := db.QueryRow(`SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	return role, err
+}
+
+// SetUserRole changes the role of a user
+func SetUserRole(db *sql.DB, userID uuid.UUID, role string) error {
+	res, err := db.Exec(`UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1`, userID, role)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
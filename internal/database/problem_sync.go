@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// SyncProblemParams is the full desired state of a problem, keyed by slug
+type SyncProblemParams struct {
+	Slug          string
+	Title         string
+	Description   string
+	Difficulty    string
+	Topics        []string
+	Constraints   []string
+	Hints         []string
+	TimeLimitMs   int
+	MemoryLimitMb int
+	TestCases     []TestCaseParams
+	// StarterCode and Solutions map language to code
+	StarterCode map[string]string
+	Solutions   map[string]string
+}
+
+// SyncProblem makes the problem with the given slug match params, creating it
+// if needed. Bundles are imported by operators, so new problems are published
+// directly and existing ones keep their status. Rows that already match are
+// left untouched, so running it twice with the same params changes nothing.
+// Test cases are matched by position; starter code and solutions for languages
+// not in params are removed.
+func SyncProblem(db *sql.DB, params SyncProblemParams) (questionID int, created bool, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	topics, constraints, hints := emptyIfNil(params.Topics), emptyIfNil(params.Constraints), emptyIfNil(params.Hints)
+
+	err = tx.QueryRow(`SELECT id FROM questions WHERE slug = $1 FOR UPDATE`, params.Slug).Scan(&questionID)
+	switch {
+	case err == sql.ErrNoRows:
+		created = true
+		err = tx.QueryRow(`
+			INSERT INTO questions (title, slug, description, difficulty, topics, constraints, hints, test_case_count, status, published_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, 0, 'published', NOW())
+			RETURNING id
+		`, params.Title, params.Slug, params.Description, params.Difficulty, pq.Array(topics), pq.Array(constraints), pq.Array(hints)).Scan(&questionID)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to insert question: %w", err)
+		}
+	case err != nil:
+		return 0, false, err
+	default:
+		_, err = tx.Exec(`
+			UPDATE questions
+			SET title = $2, description = $3, difficulty = $4, topics = $5, constraints = $6, hints = $7, updated_at = NOW()
+			WHERE id = $1 AND (title, description, difficulty, topics, constraints, hints) IS DISTINCT FROM ($2, $3, $4, $5::text[], $6::text[], $7::text[])
+		`, questionID, params.Title, params.Description, params.Difficulty, pq.Array(topics), pq.Array(constraints), pq.Array(hints))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to update question: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO problems (question_id, time_limit_ms, memory_limit_mb)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (question_id) DO UPDATE
+		SET time_limit_ms = EXCLUDED.time_limit_ms, memory_limit_mb = EXCLUDED.memory_limit_mb, updated_at = NOW()
+		WHERE (problems.time_limit_ms, problems.memory_limit_mb) IS DISTINCT FROM (EXCLUDED.time_limit_ms, EXCLUDED.memory_limit_mb)
+	`, questionID, params.TimeLimitMs, params.MemoryLimitMb)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to upsert problem: %w", err)
+	}
+
+	if err := syncTestCases(tx, questionID, params.TestCases); err != nil {
+		return 0, false, err
+	}
+	if err := syncLanguageCode(tx, "question_starter_code", questionID, params.StarterCode); err != nil {
+		return 0, false, fmt.Errorf("failed to sync starter code: %w", err)
+	}
+	if err := syncLanguageCode(tx, "reference_solutions", questionID, params.Solutions); err != nil {
+		return 0, false, fmt.Errorf("failed to sync reference solutions: %w", err)
+	}
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return 0, false, err
+	}
+
+	return questionID, created, tx.Commit()
+}
+
+// syncTestCases makes a question's test cases match testCases by position
+func syncTestCases(tx *sql.Tx, questionID int, testCases []TestCaseParams) error {
+	var existing int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM test_cases WHERE question_id = $1`, questionID).Scan(&existing); err != nil {
+		return err
+	}
+
+	changed := false
+	for i, tc := range testCases {
+		position := i + 1
+		if position > existing {
+			_, err := tx.Exec(`
+				INSERT INTO test_cases (question_id, position, input, expected_output, is_sample, time_limit_ms, memory_limit_mb, explanation)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, questionID, position, tc.Input, tc.ExpectedOutput, tc.IsSample, tc.TimeLimitMs, tc.MemoryLimitMb, tc.Explanation)
+			if err != nil {
+				return fmt.Errorf("failed to insert test case %d: %w", position, err)
+			}
+			changed = true
+			continue
+		}
+
+		res, err := tx.Exec(`
+			UPDATE test_cases
+			SET input = $3, expected_output = $4, is_sample = $5, time_limit_ms = $6, memory_limit_mb = $7, explanation = $8, updated_at = NOW()
+			WHERE question_id = $1 AND position = $2
+				AND (input, expected_output, is_sample, time_limit_ms, memory_limit_mb, explanation) IS DISTINCT FROM ($3, $4, $5, $6::integer, $7::integer, $8::text)
+		`, questionID, position, tc.Input, tc.ExpectedOutput, tc.IsSample, tc.TimeLimitMs, tc.MemoryLimitMb, tc.Explanation)
+		if err != nil {
+			return fmt.Errorf("failed to update test case %d: %w", position, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			changed = true
+		}
+	}
+
+	if existing > len(testCases) {
+		if _, err := tx.Exec(`DELETE FROM test_cases WHERE question_id = $1 AND position > $2`, questionID, len(testCases)); err != nil {
+			return fmt.Errorf("failed to delete test cases: %w", err)
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return refreshTestCaseCount(tx, questionID)
+}
+
+// syncLanguageCode makes the per-language code rows of a question in table match code
+func syncLanguageCode(tx *sql.Tx, table string, questionID int, code map[string]string) error {
+	languages := []string{}
+	for language, src := range code {
+		languages = append(languages, language)
+		_, err := tx.Exec(`
+			INSERT INTO `+table+` (question_id, language, code, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (question_id, language) DO UPDATE SET code = EXCLUDED.code, updated_at = EXCLUDED.updated_at
+			WHERE `+table+`.code IS DISTINCT FROM EXCLUDED.code
+		`, questionID, language, src)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`DELETE FROM `+table+` WHERE question_id = $1 AND NOT (language = ANY($2))`, questionID, pq.Array(languages))
+	return err
+}
+
+func emptyIfNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 )
@@ -17,6 +18,15 @@ type Question struct {
 	Difficulty    string
 	Topics        []string
 	TestCaseCount int
+	Constraints   []string
+	Hints         []string
+	Status        string
+	Rating        int
+	// Users who attempted the question, and how many of them solved it
+	AttemptCount int
+	SolveCount   int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // GetQuestions retrieves questions from the database with optional filtering and pagination
@@ -88,3 +98,35 @@ func GetQuestions(db *sql.DB, offset, limit int, difficulty *string, topics []st
 
 	return questions, nil
 }
+
+const questionColumns = `q.id, q.title, q.slug, q.description, q.difficulty, q.topics, q.test_case_count, q.constraints, q.hints, q.status, q.rating, q.attempt_count, q.solve_count, q.created_at, q.updated_at`
+
+func scanQuestion(row rowScanner) (*Question, error) {
+	q := &Question{}
+	err := row.Scan(
+		&q.ID,
+		&q.Title,
+		&q.Slug,
+		&q.Description,
+		&q.Difficulty,
+		pq.Array(&q.Topics),
+		&q.TestCaseCount,
+		pq.Array(&q.Constraints),
+		pq.Array(&q.Hints),
+		&q.Status,
+		&q.Rating,
+		&q.AttemptCount,
+		&q.SolveCount,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// GetQuestionBySlug retrieves a single question by its slug
+func GetQuestionBySlug(db *sql.DB, slug string) (*Question, error) {
+	return scanQuestion(db.QueryRow(`SELECT `+questionColumns+` FROM questions q WHERE q.slug = $1`, slug))
+}
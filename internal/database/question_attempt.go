@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuestionAttempt is a user's progress on a question
+type QuestionAttempt struct {
+	UserID     uuid.UUID
+	QuestionID int
+	SolvedAt   sql.NullTime
+	GaveUpAt   sql.NullTime
+	UserRating sql.NullInt64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// currentUserRating is the SQL for the rating of the user in $1, recorded when an attempt starts
+const currentUserRating = `(SELECT COALESCE(rating, 0) FROM users WHERE id = $1)`
+
+// GetQuestionAttempt retrieves a user's progress on a question
+func GetQuestionAttempt(db *sql.DB, userID uuid.UUID, questionID int) (*QuestionAttempt, error) {
+	a := &QuestionAttempt{}
+	err := db.QueryRow(`
+		SELECT user_id, question_id, solved_at, gave_up_at, user_rating, created_at, updated_at
+		FROM question_attempts
+		WHERE user_id = $1 AND question_id = $2
+	`, userID, questionID).Scan(&a.UserID, &a.QuestionID, &a.SolvedAt, &a.GaveUpAt, &a.UserRating, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// recordQuestionSolved marks a question as solved by a user, unlocks its
+// editorial for them and updates their progress on lists containing it
+func recordQuestionSolved(q queryer, userID uuid.UUID, questionID int) error {
+	_, err := q.Exec(`
+		INSERT INTO question_attempts (user_id, question_id, solved_at, user_rating)
+		VALUES ($1, $2, NOW(), `+currentUserRating+`)
+		ON CONFLICT (user_id, question_id) DO UPDATE SET
+			solved_at = COALESCE(question_attempts.solved_at, NOW()),
+			updated_at = NOW()
+	`, userID, questionID)
+	if err != nil {
+		return err
+	}
+
+	if err := unlockEditorial(q, userID, questionID, UnlockSolved); err != nil {
+		return err
+	}
+
+	return refreshListCompletion(q, `f.user_id = $1 AND f.list_id IN (SELECT list_id FROM problem_list_items WHERE question_id = $2)`, userID, questionID)
+}
+
+// GiveUpQuestion records that a user gave up on a question and unlocks its
+// editorial for them. Giving up on a question already solved only unlocks the
+// editorial. It returns the resulting unlock.
+func GiveUpQuestion(db *sql.DB, userID uuid.UUID, questionID int) (*EditorialUnlock, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO question_attempts (user_id, question_id, gave_up_at, user_rating)
+		VALUES ($1, $2, NOW(), `+currentUserRating+`)
+		ON CONFLICT (user_id, question_id) DO UPDATE SET
+			gave_up_at = CASE WHEN question_attempts.solved_at IS NULL
+				THEN COALESCE(question_attempts.gave_up_at, NOW())
+				ELSE question_attempts.gave_up_at END,
+			updated_at = NOW()
+	`, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unlockEditorial(tx, userID, questionID, UnlockGaveUp); err != nil {
+		return nil, err
+	}
+
+	u, err := getEditorialUnlock(tx, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, tx.Commit()
+}
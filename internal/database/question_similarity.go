@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// SimilarityQuestion holds what similar-question scoring needs about a question
+type SimilarityQuestion struct {
+	ID         int
+	Rating     int
+	Topics     []string
+	SolveCount int
+}
+
+// CoSolve counts the users who solved both of two questions. QuestionA is
+// always the lower ID.
+type CoSolve struct {
+	QuestionA int
+	QuestionB int
+	Users     int
+}
+
+// QuestionSimilarity ranks SimilarID among the questions similar to QuestionID
+type QuestionSimilarity struct {
+	QuestionID int
+	SimilarID  int
+	Rank       int
+	Score      float64
+}
+
+// GetSimilarityQuestions retrieves every published question for similarity scoring
+func GetSimilarityQuestions(db *sql.DB) ([]*SimilarityQuestion, error) {
+	rows, err := db.Query(`
+		SELECT id, rating, topics, solve_count
+		FROM questions
+		WHERE status = $1
+		ORDER BY id
+	`, StatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []*SimilarityQuestion
+	for rows.Next() {
+		q := &SimilarityQuestion{}
+		if err := rows.Scan(&q.ID, &q.Rating, pq.Array(&q.Topics), &q.SolveCount); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+
+	return questions, rows.Err()
+}
+
+// GetCoSolves counts, for every pair of published questions solved by at
+// least one common user, how many users solved both
+func GetCoSolves(db *sql.DB) ([]CoSolve, error) {
+	rows, err := db.Query(`
+		SELECT a.question_id, b.question_id, COUNT(*)
+		FROM question_attempts a
+		JOIN question_attempts b ON b.user_id = a.user_id AND b.question_id > a.question_id AND b.solved_at IS NOT NULL
+		JOIN questions qa ON qa.id = a.question_id AND qa.status = $1
+		JOIN questions qb ON qb.id = b.question_id AND qb.status = $1
+		WHERE a.solved_at IS NOT NULL
+		GROUP BY a.question_id, b.question_id
+	`, StatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var coSolves []CoSolve
+	for rows.Next() {
+		var c CoSolve
+		if err := rows.Scan(&c.QuestionA, &c.QuestionB, &c.Users); err != nil {
+			return nil, err
+		}
+		coSolves = append(coSolves, c)
+	}
+
+	return coSolves, rows.Err()
+}
+
+// ReplaceQuestionSimilarities replaces every stored similarity with the given ones
+func ReplaceQuestionSimilarities(db *sql.DB, similarities []QuestionSimilarity) error {
+	questionIDs := make([]int64, len(similarities))
+	similarIDs := make([]int64, len(similarities))
+	ranks := make([]int64, len(similarities))
+	scores := make([]float64, len(similarities))
+	for i, s := range similarities {
+		questionIDs[i] = int64(s.QuestionID)
+		similarIDs[i] = int64(s.SimilarID)
+		ranks[i] = int64(s.Rank)
+		scores[i] = s.Score
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM question_similarities`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO question_similarities (question_id, similar_id, rank, score)
+		SELECT * FROM unnest($1::integer[], $2::integer[], $3::integer[], $4::double precision[])
+	`, pq.Array(questionIDs), pq.Array(similarIDs), pq.Array(ranks), pq.Array(scores))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSimilarQuestions retrieves up to limit published questions similar to a
+// question, most similar first
+func GetSimilarQuestions(db *sql.DB, questionID, limit int) ([]*Question, error) {
+	rows, err := db.Query(`
+		SELECT `+questionColumns+`
+		FROM question_similarities s
+		JOIN questions q ON q.id = s.similar_id
+		WHERE s.question_id = $1 AND q.status = $2
+		ORDER BY s.rank ASC
+		LIMIT $3
+	`, questionID, StatusPublished, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []*Question
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+
+	return questions, rows.Err()
+}
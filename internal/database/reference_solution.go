@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ReferenceSolution is an accepted solution to a question in one language
+type ReferenceSolution struct {
+	QuestionID int
+	Language   string
+	Code       string
+	UpdatedAt  time.Time
+}
+
+// GetReferenceSolutions retrieves the reference solutions of a question for every language
+func GetReferenceSolutions(db *sql.DB, questionID int) ([]*ReferenceSolution, error) {
+	query := `
+		SELECT question_id, language, code, updated_at
+		FROM reference_solutions
+		WHERE question_id = $1
+		ORDER BY language ASC
+	`
+
+	rows, err := db.Query(query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var solutions []*ReferenceSolution
+	for rows.Next() {
+		s := &ReferenceSolution{}
+		if err := rows.Scan(&s.QuestionID, &s.Language, &s.Code, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		solutions = append(solutions, s)
+	}
+
+	return solutions, rows.Err()
+}
+
+// UpsertReferenceSolution sets the reference solution of a question for one language
+func UpsertReferenceSolution(db *sql.DB, questionID int, language, code string) (*ReferenceSolution, error) {
+	query := `
+		INSERT INTO reference_solutions (question_id, language, code, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (question_id, language) DO UPDATE SET code = EXCLUDED.code, updated_at = EXCLUDED.updated_at
+		RETURNING question_id, language, code, updated_at
+	`
+
+	s := &ReferenceSolution{}
+	err := db.QueryRow(query, questionID, language, code).Scan(&s.QuestionID, &s.Language, &s.Code, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProblemRevision is an immutable snapshot of what a question was judged against
+type ProblemRevision struct {
+	ID            int64
+	QuestionID    int
+	Revision      int
+	Title         string
+	Description   string
+	Difficulty    string
+	Constraints   []string
+	Hints         []string
+	TimeLimitMs   sql.NullInt64
+	MemoryLimitMb sql.NullInt64
+	TestCases     []RevisionTestCase
+	// Signature is the function signature JSON, or nil if the question had none
+	Signature []byte
+	// Checker is the checker JSON, or nil if outputs were compared exactly
+	Checker   []byte
+	CreatedAt time.Time
+}
+
+// RevisionTestCase is a test case as stored in a revision
+type RevisionTestCase struct {
+	Input          string  `json:"input"`
+	ExpectedOutput string  `json:"expectedOutput"`
+	IsSample       bool    `json:"isSample"`
+	TimeLimitMs    *int    `json:"timeLimitMs"`
+	MemoryLimitMb  *int    `json:"memoryLimitMb"`
+	Explanation    *string `json:"explanation"`
+}
+
+const revisionColumns = `id, question_id, revision, title, description, difficulty, constraints, hints, time_limit_ms, memory_limit_mb, test_cases, signature, checker, created_at`
+
+func scanRevision(row rowScanner) (*ProblemRevision, error) {
+	r := &ProblemRevision{}
+	var testCases []byte
+	err := row.Scan(
+		&r.ID,
+		&r.QuestionID,
+		&r.Revision,
+		&r.Title,
+		&r.Description,
+		&r.Difficulty,
+		pq.Array(&r.Constraints),
+		pq.Array(&r.Hints),
+		&r.TimeLimitMs,
+		&r.MemoryLimitMb,
+		&testCases,
+		&r.Signature,
+		&r.Checker,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(testCases, &r.TestCases); err != nil {
+		return nil, fmt.Errorf("invalid test cases in revision %d: %w", r.ID, err)
+	}
+	return r, nil
+}
+
+// GetProblemRevisions retrieves every revision of a question, oldest first
+func GetProblemRevisions(db *sql.DB, questionID int) ([]*ProblemRevision, error) {
+	rows, err := db.Query(`SELECT `+revisionColumns+` FROM problem_revisions WHERE question_id = $1 ORDER BY revision ASC`, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*ProblemRevision
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetProblemRevisionByID retrieves a revision by ID
+func GetProblemRevisionByID(db *sql.DB, id int64) (*ProblemRevision, error) {
+	return scanRevision(db.QueryRow(`SELECT `+revisionColumns+` FROM problem_revisions WHERE id = $1`, id))
+}
+
+// GetProblemRevisionByNumber retrieves a question's revision by its number
+func GetProblemRevisionByNumber(db *sql.DB, questionID, revision int) (*ProblemRevision, error) {
+	return scanRevision(db.QueryRow(`SELECT `+revisionColumns+` FROM problem_revisions WHERE question_id = $1 AND revision = $2`, questionID, revision))
+}
+
+// snapshotRevision records the current state of a question as a new revision
+// unless it is unchanged since the latest one, and returns the ID of the
+// revision matching the current state. Call it inside the transaction that
+// made the change.
+func snapshotRevision(q queryer, questionID int) (int64, error) {
+	current := &ProblemRevision{QuestionID: questionID}
+	err := q.QueryRow(`
+		SELECT q.title, q.description, q.difficulty, q.constraints, q.hints, p.time_limit_ms, p.memory_limit_mb, q.signature, q.checker
+		FROM questions q
+		LEFT JOIN problems p ON p.question_id = q.id
+		WHERE q.id = $1
+		FOR UPDATE OF q
+	`, questionID).Scan(
+		&current.Title,
+		&current.Description,
+		&current.Difficulty,
+		pq.Array(&current.Constraints),
+		pq.Array(&current.Hints),
+		&current.TimeLimitMs,
+		&current.MemoryLimitMb,
+		&current.Signature,
+		&current.Checker,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	testCases, err := queryTestCases(q, `SELECT `+testCaseColumns+` FROM test_cases WHERE question_id = $1 ORDER BY position ASC`, questionID)
+	if err != nil {
+		return 0, err
+	}
+	current.TestCases = []RevisionTestCase{}
+	for _, tc := range testCases {
+		current.TestCases = append(current.TestCases, revisionTestCase(tc))
+	}
+
+	latest, err := scanRevision(q.QueryRow(`SELECT `+revisionColumns+` FROM problem_revisions WHERE question_id = $1 ORDER BY revision DESC LIMIT 1`, questionID))
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	if latest != nil && sameRevisionContent(latest, current) {
+		return latest.ID, nil
+	}
+
+	testCasesJSON, err := json.Marshal(current.TestCases)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = q.QueryRow(`
+		INSERT INTO problem_revisions (question_id, revision, title, description, difficulty, constraints, hints, time_limit_ms, memory_limit_mb, test_cases, signature, checker)
+		VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM problem_revisions WHERE question_id = $1), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, questionID, current.Title, current.Description, current.Difficulty, pq.Array(emptyIfNil(current.Constraints)), pq.Array(emptyIfNil(current.Hints)),
+		current.TimeLimitMs, current.MemoryLimitMb, testCasesJSON, nullJSON(current.Signature), nullJSON(current.Checker)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	if _, err := q.Exec(`UPDATE questions SET current_revision_id = $2 WHERE id = $1`, questionID, id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func revisionTestCase(tc *TestCase) RevisionTestCase {
+	r := RevisionTestCase{
+		Input:          tc.Input,
+		ExpectedOutput: tc.ExpectedOutput,
+		IsSample:       tc.IsSample,
+	}
+	if tc.TimeLimitMs.Valid {
+		v := int(tc.TimeLimitMs.Int64)
+		r.TimeLimitMs = &v
+	}
+	if tc.MemoryLimitMb.Valid {
+		v := int(tc.MemoryLimitMb.Int64)
+		r.MemoryLimitMb = &v
+	}
+	if tc.Explanation.Valid {
+		r.Explanation = &tc.Explanation.String
+	}
+	return r
+}
+
+// sameRevisionContent reports whether two revisions snapshot the same content
+func sameRevisionContent(a, b *ProblemRevision) bool {
+	return a.Title == b.Title &&
+		a.Description == b.Description &&
+		a.Difficulty == b.Difficulty &&
+		reflect.DeepEqual(emptyIfNil(a.Constraints), emptyIfNil(b.Constraints)) &&
+		reflect.DeepEqual(emptyIfNil(a.Hints), emptyIfNil(b.Hints)) &&
+		a.TimeLimitMs == b.TimeLimitMs &&
+		a.MemoryLimitMb == b.MemoryLimitMb &&
+		bytes.Equal(a.Signature, b.Signature) &&
+		bytes.Equal(a.Checker, b.Checker) &&
+		len(a.TestCases) == len(b.TestCases) &&
+		(len(a.TestCases) == 0 || reflect.DeepEqual(a.TestCases, b.TestCases))
+}
+
+// RenderedDescription is a revision's description source with its cached HTML
+type RenderedDescription struct {
+	RevisionID  int64
+	Description string
+	// HTML is null until the description is first rendered
+	HTML        sql.NullString
+	HTMLVersion sql.NullInt64
+}
+
+// GetCurrentRenderedDescription retrieves the description of a question's current revision
+func GetCurrentRenderedDescription(db *sql.DB, questionID int) (*RenderedDescription, error) {
+	return scanRenderedDescription(db.QueryRow(`
+		SELECT r.id, r.description, r.description_html, r.description_html_version
+		FROM questions q
+		JOIN problem_revisions r ON r.id = q.current_revision_id
+		WHERE q.id = $1
+	`, questionID))
+}
+
+// GetRenderedDescription retrieves the description of a revision
+func GetRenderedDescription(db *sql.DB, revisionID int64) (*RenderedDescription, error) {
+	return scanRenderedDescription(db.QueryRow(`
+		SELECT id, description, description_html, description_html_version
+		FROM problem_revisions
+		WHERE id = $1
+	`, revisionID))
+}
+
+func scanRenderedDescription(row rowScanner) (*RenderedDescription, error) {
+	d := &RenderedDescription{}
+	if err := row.Scan(&d.RevisionID, &d.Description, &d.HTML, &d.HTMLVersion); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetDescriptionHTML caches the rendered description of a revision. Revisions
+// are immutable, so the HTML only changes when the renderer version does.
+func SetDescriptionHTML(db *sql.DB, revisionID int64, html string, version int) error {
+	_, err := db.Exec(`
+		UPDATE problem_revisions
+		SET description_html = $2, description_html_version = $3
+		WHERE id = $1
+	`, revisionID, html, version)
+	return err
+}
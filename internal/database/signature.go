@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetQuestionSignature retrieves the function signature JSON of a question, or nil if it has none
+func GetQuestionSignature(db *sql.DB, questionID int) ([]byte, error) {
+	var signature []byte
+	err := db.QueryRow(`SELECT signature FROM questions WHERE id = $1`, questionID).Scan(&signature)
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// SetQuestionSignature sets the function signature of a question and replaces
+// its generated starter code with starterCode, which maps language to code.
+// Starter code an author set by hand is kept.
+func SetQuestionSignature(db *sql.DB, questionID int, signature []byte, starterCode map[string]string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE questions SET signature = $2, updated_at = NOW() WHERE id = $1`, questionID, nullJSON(signature))
+	if err != nil {
+		return fmt.Errorf("failed to update signature: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	for language, code := range starterCode {
+		_, err := tx.Exec(`
+			INSERT INTO question_starter_code (question_id, language, code, generated, updated_at)
+			VALUES ($1, $2, $3, TRUE, NOW())
+			ON CONFLICT (question_id, language) DO UPDATE SET code = EXCLUDED.code, updated_at = EXCLUDED.updated_at
+			WHERE question_starter_code.generated AND question_starter_code.code IS DISTINCT FROM EXCLUDED.code
+		`, questionID, language, code)
+		if err != nil {
+			return fmt.Errorf("failed to generate starter code: %w", err)
+		}
+	}
+
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// nullJSON passes an empty JSON document as SQL NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
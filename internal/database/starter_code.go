@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StarterCode is the code a question's editor starts with for one language
+type StarterCode struct {
+	QuestionID int
+	Language   string
+	Code       string
+	Generated  bool // generated from the question's function signature
+	UpdatedAt  time.Time
+}
+
+// GetStarterCode retrieves the starter code of a question for every language
+func GetStarterCode(db *sql.DB, questionID int) ([]*StarterCode, error) {
+	query := `
+		SELECT question_id, language, code, generated, updated_at
+		FROM question_starter_code
+		WHERE question_id = $1
+		ORDER BY language ASC
+	`
+
+	rows, err := db.Query(query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var starterCode []*StarterCode
+	for rows.Next() {
+		sc := &StarterCode{}
+		if err := rows.Scan(&sc.QuestionID, &sc.Language, &sc.Code, &sc.Generated, &sc.UpdatedAt); err != nil {
+			return nil, err
+		}
+		starterCode = append(starterCode, sc)
+	}
+
+	return starterCode, rows.Err()
+}
+
+// UpsertStarterCode sets the starter code of a question for one language. Code
+// set this way is never replaced by generated code.
+func UpsertStarterCode(db *sql.DB, questionID int, language, code string) (*StarterCode, error) {
+	query := `
+		INSERT INTO question_starter_code (question_id, language, code, generated, updated_at)
+		VALUES ($1, $2, $3, FALSE, NOW())
+		ON CONFLICT (question_id, language) DO UPDATE SET code = EXCLUDED.code, generated = FALSE, updated_at = EXCLUDED.updated_at
+		RETURNING question_id, language, code, generated, updated_at
+	`
+
+	sc := &StarterCode{}
+	err := db.QueryRow(query, questionID, language, code).Scan(&sc.QuestionID, &sc.Language, &sc.Code, &sc.Generated, &sc.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
@@ -0,0 +1,385 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Submission states. Queued, compiling and running are pending; the rest are
+// final verdicts.
+const (
+	SubmissionQueued              = "queued"
+	SubmissionCompiling           = "compiling"
+	SubmissionRunning             = "running"
+	SubmissionAccepted            = "accepted"
+	SubmissionWrongAnswer         = "wrong_answer"
+	SubmissionTimeLimitExceeded   = "time_limit_exceeded"
+	SubmissionMemoryLimitExceeded = "memory_limit_exceeded"
+	SubmissionRuntimeError        = "runtime_error"
+	SubmissionCompileError        = "compile_error"
+	SubmissionInternalError       = "internal_error"
+)
+
+// submissionTransitions lists the states each pending state may move to.
+// Interpreted languages skip compiling, and a compile error can only follow
+// compiling. Any pending submission can end with an internal error when the
+// judge gives up on it.
+var submissionTransitions = map[string][]string{
+	SubmissionQueued:    {SubmissionCompiling, SubmissionRunning, SubmissionInternalError},
+	SubmissionCompiling: {SubmissionRunning, SubmissionCompileError, SubmissionInternalError},
+	SubmissionRunning: {
+		SubmissionAccepted,
+		SubmissionWrongAnswer,
+		SubmissionTimeLimitExceeded,
+		SubmissionMemoryLimitExceeded,
+		SubmissionRuntimeError,
+		SubmissionInternalError,
+	},
+}
+
+// ErrSubmissionStatusChanged is returned when a submission is no longer in the state a transition expects
+var ErrSubmissionStatusChanged = errors.New("submission status changed concurrently")
+
+// CanTransitionSubmission reports whether a submission may move from one state to another
+func CanTransitionSubmission(from, to string) bool {
+	for _, next := range submissionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubmissionFinal reports whether a state is a verdict
+func IsSubmissionFinal(status string) bool {
+	_, pending := submissionTransitions[status]
+	return !pending
+}
+
+// Submission is a user's code submitted against a pinned problem revision
+type Submission struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	QuestionID  int
+	RevisionID  int64
+	MatchID     uuid.NullUUID
+	Language    string
+	Source      string
+	Status      string
+	Message     sql.NullString
+	TestsPassed sql.NullInt64
+	TestsTotal  sql.NullInt64
+	TimeMs      sql.NullInt64
+	MemoryKb    sql.NullInt64
+	CreatedAt   time.Time
+	StartedAt   sql.NullTime
+	FinishedAt  sql.NullTime
+}
+
+// SubmissionResult is the outcome of judging a submission
+type SubmissionResult struct {
+	Status      string
+	Message     string
+	TestsPassed int
+	TestsTotal  int
+	TimeMs      int
+	MemoryKb    int
+	// Tests holds the results of the test cases that ran, in order
+	Tests []SubmissionTestResult
+}
+
+// SubmissionTestResult is how a submission did on one test case
+type SubmissionTestResult struct {
+	// Position is the 1-based position of the test case in the revision
+	Position   int
+	IsSample   bool
+	Status     string
+	TimeMs     int
+	WallTimeMs int
+	MemoryKb   int
+	// Message is the checker's message or the error output, and Diff the
+	// diff of a wrong answer; empty when there is none
+	Message string
+	Diff    string
+}
+
+const submissionColumns = `id, user_id, question_id, revision_id, match_id, language, source, status, message, tests_passed, tests_total, time_ms, memory_kb, created_at, started_at, finished_at`
+
+func scanSubmission(row rowScanner) (*Submission, error) {
+	s := &Submission{}
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.QuestionID,
+		&s.RevisionID,
+		&s.MatchID,
+		&s.Language,
+		&s.Source,
+		&s.Status,
+		&s.Message,
+		&s.TestsPassed,
+		&s.TestsTotal,
+		&s.TimeMs,
+		&s.MemoryKb,
+		&s.CreatedAt,
+		&s.StartedAt,
+		&s.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CreateSubmission queues a submission and its judge job. It is pinned to the
+// match's revision when matchID is set and to the question's current revision
+// otherwise. The user's first submission on a question starts their attempt at it.
+func CreateSubmission(db *sql.DB, userID uuid.UUID, questionID int, matchID uuid.NullUUID, language, source string) (*Submission, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	revisionID, err := pinRevision(tx, questionID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO question_attempts (user_id, question_id, user_rating)
+		VALUES ($1, $2, `+currentUserRating+`)
+		ON CONFLICT (user_id, question_id) DO NOTHING
+	`, userID, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start attempt: %w", err)
+	}
+
+	s, err := scanSubmission(tx.QueryRow(`
+		INSERT INTO submissions (id, user_id, question_id, revision_id, match_id, language, source, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+submissionColumns,
+		uuid.New(), userID, questionID, revisionID, matchID, language, source, SubmissionQueued,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert submission: %w", err)
+	}
+
+	if err := enqueueJudgeJob(tx, s.ID, userID); err != nil {
+		return nil, fmt.Errorf("failed to queue submission: %w", err)
+	}
+
+	return s, tx.Commit()
+}
+
+// PinProblemRevision returns the revision code submitted now would be judged
+// against: the match's when matchID is set, the question's current one otherwise
+func PinProblemRevision(db *sql.DB, questionID int, matchID uuid.NullUUID) (*ProblemRevision, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	revisionID, err := pinRevision(tx, questionID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := scanRevision(tx.QueryRow(`SELECT `+revisionColumns+` FROM problem_revisions WHERE id = $1`, revisionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return r, tx.Commit()
+}
+
+// pinRevision returns the ID of the revision a submission is judged against.
+// Call it inside a transaction, since it may record a new revision.
+func pinRevision(q queryer, questionID int, matchID uuid.NullUUID) (int64, error) {
+	var revisionID int64
+	var err error
+	if matchID.Valid {
+		err = q.QueryRow(`SELECT revision_id FROM matches WHERE id = $1 AND question_id = $2`, matchID.UUID, questionID).Scan(&revisionID)
+	} else {
+		revisionID, err = snapshotRevision(q, questionID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to pin revision: %w", err)
+	}
+	return revisionID, nil
+}
+
+// GetSubmissionByID retrieves a submission by ID
+func GetSubmissionByID(db *sql.DB, id uuid.UUID) (*Submission, error) {
+	return scanSubmission(db.QueryRow(`SELECT `+submissionColumns+` FROM submissions WHERE id = $1`, id))
+}
+
+// GetUserSubmissions retrieves a user's submissions on a question, newest first
+func GetUserSubmissions(db *sql.DB, userID uuid.UUID, questionID, limit, offset int) ([]*Submission, error) {
+	rows, err := db.Query(`
+		SELECT `+submissionColumns+`
+		FROM submissions
+		WHERE user_id = $1 AND question_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, userID, questionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submissions []*Submission
+	for rows.Next() {
+		s, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, s)
+	}
+
+	return submissions, rows.Err()
+}
+
+// AdvanceSubmission moves a pending submission to compiling or running. It
+// returns ErrSubmissionStatusChanged if the submission is not in the from state.
+func AdvanceSubmission(db *sql.DB, id uuid.UUID, from, to string) error {
+	if !CanTransitionSubmission(from, to) || IsSubmissionFinal(to) {
+		return fmt.Errorf("invalid submission transition from %s to %s", from, to)
+	}
+
+	res, err := db.Exec(`
+		UPDATE submissions
+		SET status = $3,
+			started_at = COALESCE(started_at, NOW()),
+			updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, id, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSubmissionStatusChanged
+	}
+
+	return nil
+}
+
+// FinishSubmission records the verdict of a submission in the from state. An
+// accepted submission also marks the question as solved by its user. It
+// returns ErrSubmissionStatusChanged if the submission is not in the from state.
+func FinishSubmission(db *sql.DB, id uuid.UUID, from string, result SubmissionResult) error {
+	if !CanTransitionSubmission(from, result.Status) || !IsSubmissionFinal(result.Status) {
+		return fmt.Errorf("invalid submission transition from %s to %s", from, result.Status)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	var questionID int
+	err = tx.QueryRow(`
+		UPDATE submissions
+		SET status = $3,
+			message = NULLIF($4, ''),
+			tests_passed = $5,
+			tests_total = $6,
+			time_ms = $7,
+			memory_kb = $8,
+			started_at = COALESCE(started_at, NOW()),
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND status = $2
+		RETURNING user_id, question_id
+	`, id, from, result.Status, result.Message, result.TestsPassed, result.TestsTotal, result.TimeMs, result.MemoryKb).Scan(&userID, &questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSubmissionStatusChanged
+		}
+		return fmt.Errorf("failed to record verdict: %w", err)
+	}
+
+	for _, t := range result.Tests {
+		_, err := tx.Exec(`
+			INSERT INTO submission_test_results (submission_id, position, is_sample, status, time_ms, wall_time_ms, memory_kb, message, diff)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))
+		`, id, t.Position, t.IsSample, t.Status, t.TimeMs, t.WallTimeMs, t.MemoryKb, t.Message, t.Diff)
+		if err != nil {
+			return fmt.Errorf("failed to record test result: %w", err)
+		}
+	}
+
+	if result.Status == SubmissionAccepted {
+		if err := recordQuestionSolved(tx, userID, questionID); err != nil {
+			return fmt.Errorf("failed to record solve: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSubmissionTestResults retrieves the test results of a submission in
+// test case order; a submission that is not judged yet has none
+func GetSubmissionTestResults(db *sql.DB, submissionID uuid.UUID) ([]*SubmissionTestResult, error) {
+	rows, err := db.Query(`
+		SELECT position, is_sample, status, time_ms, wall_time_ms, memory_kb, COALESCE(message, ''), COALESCE(diff, '')
+		FROM submission_test_results
+		WHERE submission_id = $1
+		ORDER BY position ASC
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*SubmissionTestResult
+	for rows.Next() {
+		t := &SubmissionTestResult{}
+		if err := rows.Scan(&t.Position, &t.IsSample, &t.Status, &t.TimeMs, &t.WallTimeMs, &t.MemoryKb, &t.Message, &t.Diff); err != nil {
+			return nil, err
+		}
+		results = append(results, t)
+	}
+
+	return results, rows.Err()
+}
+
+// RestartSubmission moves a pending submission back to queued, so that a
+// retried judge job judges it from the start. Verdicts are left alone.
+func RestartSubmission(db *sql.DB, id uuid.UUID) error {
+	_, err := db.Exec(`
+		UPDATE submissions
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status IN ($3, $4)
+	`, id, SubmissionQueued, SubmissionCompiling, SubmissionRunning)
+	if err != nil {
+		return fmt.Errorf("failed to restart submission: %w", err)
+	}
+	return nil
+}
+
+// AbortSubmission ends a pending submission, whatever its state, with an
+// internal error. It returns ErrSubmissionStatusChanged if it already has a verdict.
+func AbortSubmission(db *sql.DB, id uuid.UUID, message string) error {
+	res, err := db.Exec(`
+		UPDATE submissions
+		SET status = $2,
+			message = NULLIF($3, ''),
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ($4, $5, $6)
+	`, id, SubmissionInternalError, message, SubmissionQueued, SubmissionCompiling, SubmissionRunning)
+	if err != nil {
+		return fmt.Errorf("failed to abort submission: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSubmissionStatusChanged
+	}
+	return nil
+}
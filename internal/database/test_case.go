@@ -0,0 +1,275 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// TestCase represents a single input/output pair for a question
+type TestCase struct {
+	ID             int64
+	QuestionID     int
+	Position       int
+	Input          string
+	ExpectedOutput string
+	IsSample       bool
+	TimeLimitMs    sql.NullInt64
+	MemoryLimitMb  sql.NullInt64
+	Explanation    sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TestCaseParams holds the fields used to create a test case
+type TestCaseParams struct {
+	Input          string
+	ExpectedOutput string
+	IsSample       bool
+	TimeLimitMs    *int
+	MemoryLimitMb  *int
+	Explanation    *string
+}
+
+// UpdateTestCaseParams holds the fields to change on a test case; nil fields are left untouched
+type UpdateTestCaseParams struct {
+	Input          *string
+	ExpectedOutput *string
+	IsSample       *bool
+	TimeLimitMs    *int
+	MemoryLimitMb  *int
+	Explanation    *string
+}
+
+const testCaseColumns = `id, question_id, position, input, expected_output, is_sample, time_limit_ms, memory_limit_mb, explanation, created_at, updated_at`
+
+func scanTestCase(row rowScanner) (*TestCase, error) {
+	tc := &TestCase{}
+	err := row.Scan(
+		&tc.ID,
+		&tc.QuestionID,
+		&tc.Position,
+		&tc.Input,
+		&tc.ExpectedOutput,
+		&tc.IsSample,
+		&tc.TimeLimitMs,
+		&tc.MemoryLimitMb,
+		&tc.Explanation,
+		&tc.CreatedAt,
+		&tc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// GetTestCases retrieves the test cases of a question in order. When samplesOnly
+// is set, hidden cases are left out.
+func GetTestCases(db *sql.DB, questionID int, samplesOnly bool) ([]*TestCase, error) {
+	query := `SELECT ` + testCaseColumns + ` FROM test_cases WHERE question_id = $1`
+	if samplesOnly {
+		query += ` AND is_sample`
+	}
+	query += ` ORDER BY position ASC`
+
+	return queryTestCases(db, query, questionID)
+}
+
+func queryTestCases(q queryer, query string, args ...interface{}) ([]*TestCase, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var testCases []*TestCase
+	for rows.Next() {
+		tc, err := scanTestCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		testCases = append(testCases, tc)
+	}
+
+	return testCases, rows.Err()
+}
+
+// GetTestCaseByID retrieves a test case by ID
+func GetTestCaseByID(db *sql.DB, id int64) (*TestCase, error) {
+	return scanTestCase(db.QueryRow(`SELECT `+testCaseColumns+` FROM test_cases WHERE id = $1`, id))
+}
+
+// CreateTestCase appends a test case to the end of a question's list
+func CreateTestCase(db *sql.DB, questionID int, params TestCaseParams) (*TestCase, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the question row so concurrent appends get distinct positions
+	if _, err := tx.Exec(`SELECT 1 FROM questions WHERE id = $1 FOR UPDATE`, questionID); err != nil {
+		return nil, err
+	}
+
+	tc, err := scanTestCase(tx.QueryRow(`
+		INSERT INTO test_cases (question_id, position, input, expected_output, is_sample, time_limit_ms, memory_limit_mb, explanation)
+		VALUES ($1, (SELECT COALESCE(MAX(position), 0) + 1 FROM test_cases WHERE question_id = $1), $2, $3, $4, $5, $6, $7)
+		RETURNING `+testCaseColumns,
+		questionID, params.Input, params.ExpectedOutput, params.IsSample, params.TimeLimitMs, params.MemoryLimitMb, params.Explanation,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert test case: %w", err)
+	}
+
+	if err := refreshTestCaseCount(tx, questionID); err != nil {
+		return nil, err
+	}
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return nil, err
+	}
+
+	return tc, tx.Commit()
+}
+
+// UpdateTestCase updates the contents or limits of a test case
+func UpdateTestCase(db *sql.DB, id int64, params UpdateTestCaseParams) (*TestCase, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tc, err := scanTestCase(tx.QueryRow(`
+		UPDATE test_cases
+		SET input = COALESCE($2, input),
+			expected_output = COALESCE($3, expected_output),
+			is_sample = COALESCE($4, is_sample),
+			time_limit_ms = COALESCE($5, time_limit_ms),
+			memory_limit_mb = COALESCE($6, memory_limit_mb),
+			explanation = COALESCE($7, explanation),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+testCaseColumns,
+		id, params.Input, params.ExpectedOutput, params.IsSample, params.TimeLimitMs, params.MemoryLimitMb, params.Explanation,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := snapshotRevision(tx, tc.QuestionID); err != nil {
+		return nil, err
+	}
+
+	return tc, tx.Commit()
+}
+
+// DeleteTestCase removes a test case and closes the gap it leaves in the ordering
+func DeleteTestCase(db *sql.DB, id int64) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var questionID, position int
+	err = tx.QueryRow(`DELETE FROM test_cases WHERE id = $1 RETURNING question_id, position`, id).Scan(&questionID, &position)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`UPDATE test_cases SET position = position - 1 WHERE question_id = $1 AND position > $2`, questionID, position)
+	if err != nil {
+		return false, err
+	}
+
+	if err := refreshTestCaseCount(tx, questionID); err != nil {
+		return false, err
+	}
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ReorderTestCases sets the order of a question's test cases. ids must list
+// every test case of the question exactly once.
+func ReorderTestCases(db *sql.DB, questionID int, ids []int64) ([]*TestCase, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existing []int64
+	err = tx.QueryRow(`SELECT COALESCE(array_agg(id ORDER BY id), '{}') FROM test_cases WHERE question_id = $1`, questionID).Scan(pq.Array(&existing))
+	if err != nil {
+		return nil, err
+	}
+	if !sameIDSet(existing, ids) {
+		return nil, errors.New("test case IDs must list every test case of the question exactly once")
+	}
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE test_cases SET position = $1, updated_at = NOW() WHERE id = $2`, i+1, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := snapshotRevision(tx, questionID); err != nil {
+		return nil, err
+	}
+
+	testCases, err := queryTestCases(tx, `SELECT `+testCaseColumns+` FROM test_cases WHERE question_id = $1 ORDER BY position ASC`, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return testCases, tx.Commit()
+}
+
+// GetQuestionAuthorID returns the author of a question, if it has one
+func GetQuestionAuthorID(db *sql.DB, questionID int) (uuid.NullUUID, error) {
+	var authorID uuid.NullUUID
+	err := db.QueryRow(`SELECT author_id FROM questions WHERE id = $1`, questionID).Scan(&authorID)
+	return authorID, err
+}
+
+// refreshTestCaseCount recomputes questions.test_case_count from test_cases
+func refreshTestCaseCount(q queryer, questionID int) error {
+	_, err := q.Exec(`
+		UPDATE questions
+		SET test_case_count = (SELECT COUNT(*) FROM test_cases WHERE question_id = $1), updated_at = NOW()
+		WHERE id = $1
+	`, questionID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh test case count: %w", err)
+	}
+	return nil
+}
+
+func sameIDSet(existing, ids []int64) bool {
+	if len(existing) != len(ids) {
+		return false
+	}
+	seen := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Topic is an entry in the managed topic catalog
+type Topic struct {
+	ID        int
+	Slug      string
+	Name      string
+	ParentID  sql.NullInt64
+	Aliases   []string
+	CreatedAt time.Time
+}
+
+// GetAllTopics retrieves every topic with its aliases, ordered by name
+func GetAllTopics(db *sql.DB) ([]*Topic, error) {
+	query := `
+		SELECT t.id, t.slug, t.name, t.parent_id, t.created_at,
+			COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')
+		FROM topics t
+		LEFT JOIN topic_aliases a ON a.topic_id = t.id
+		GROUP BY t.id
+		ORDER BY t.name ASC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []*Topic
+	for rows.Next() {
+		t := &Topic{}
+		err := rows.Scan(&t.ID, &t.Slug, &t.Name, &t.ParentID, &t.CreatedAt, pq.Array(&t.Aliases))
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, t)
+	}
+
+	return topics, rows.Err()
+}
+
+// CreateTopic inserts a topic and its aliases
+func CreateTopic(db *sql.DB, slug, name string, parentID *int, aliases []string) (*Topic, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t := &Topic{}
+	err = tx.QueryRow(`
+		INSERT INTO topics (slug, name, parent_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, slug, name, parent_id, created_at
+	`, slug, name, parentID).Scan(&t.ID, &t.Slug, &t.Name, &t.ParentID, &t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert topic: %w", err)
+	}
+
+	for _, alias := range aliases {
+		if err := insertTopicAlias(tx, t.ID, alias); err != nil {
+			return nil, err
+		}
+		t.Aliases = append(t.Aliases, strings.ToLower(alias))
+	}
+
+	return t, tx.Commit()
+}
+
+// AddTopicAlias adds an alternative spelling for a topic
+func AddTopicAlias(db *sql.DB, topicID int, alias string) error {
+	return insertTopicAlias(db, topicID, alias)
+}
+
+func insertTopicAlias(q queryer, topicID int, alias string) error {
+	_, err := q.Exec(`INSERT INTO topic_aliases (alias, topic_id) VALUES ($1, $2)`, strings.ToLower(alias), topicID)
+	if err != nil {
+		return fmt.Errorf("failed to insert alias %q: %w", alias, err)
+	}
+	return nil
+}
+
+// TopicCatalog indexes the topic catalog for resolving filter values
+type TopicCatalog struct {
+	Topics   []*Topic
+	byID     map[int]*Topic
+	byTerm   map[string]*Topic
+	children map[int][]*Topic
+}
+
+// LoadTopicCatalog loads every topic and indexes it by slug, name and alias
+func LoadTopicCatalog(db *sql.DB) (*TopicCatalog, error) {
+	topics, err := GetAllTopics(db)
+	if err != nil {
+		return nil, err
+	}
+	return NewTopicCatalog(topics), nil
+}
+
+// NewTopicCatalog indexes the given topics
+func NewTopicCatalog(topics []*Topic) *TopicCatalog {
+	c := &TopicCatalog{
+		Topics:   topics,
+		byID:     make(map[int]*Topic, len(topics)),
+		byTerm:   make(map[string]*Topic),
+		children: make(map[int][]*Topic),
+	}
+	for _, t := range topics {
+		c.byID[t.ID] = t
+		for _, term := range c.Terms(t) {
+			c.byTerm[term] = t
+		}
+		if t.ParentID.Valid {
+			parentID := int(t.ParentID.Int64)
+			c.children[parentID] = append(c.children[parentID], t)
+		}
+	}
+	return c
+}
+
+// Lookup finds a topic by slug, display name or alias, ignoring case
+func (c *TopicCatalog) Lookup(term string) (*Topic, bool) {
+	t, ok := c.byTerm[strings.ToLower(strings.TrimSpace(term))]
+	return t, ok
+}
+
+// ByID finds a topic by ID
+func (c *TopicCatalog) ByID(id int) (*Topic, bool) {
+	t, ok := c.byID[id]
+	return t, ok
+}
+
+// Children returns the direct children of a topic
+func (c *TopicCatalog) Children(t *Topic) []*Topic {
+	return c.children[t.ID]
+}
+
+// Descendants returns t and every topic below it
+func (c *TopicCatalog) Descendants(t *Topic) []*Topic {
+	result := []*Topic{t}
+	seen := map[int]bool{t.ID: true}
+	for i := 0; i < len(result); i++ {
+		for _, child := range c.children[result[i].ID] {
+			if !seen[child.ID] {
+				seen[child.ID] = true
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// Ancestors returns t and every topic above it, nearest first
+func (c *TopicCatalog) Ancestors(t *Topic) []*Topic {
+	result := []*Topic{t}
+	seen := map[int]bool{t.ID: true}
+	for cur := t; cur.ParentID.Valid; {
+		parent, ok := c.byID[int(cur.ParentID.Int64)]
+		if !ok || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		result = append(result, parent)
+		cur = parent
+	}
+	return result
+}
+
+// Terms returns the lower-case spellings that refer to a topic on questions
+func (c *TopicCatalog) Terms(t *Topic) []string {
+	terms := []string{strings.ToLower(t.Slug), strings.ToLower(t.Name)}
+	return append(terms, t.Aliases...)
+}
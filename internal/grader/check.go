@@ -0,0 +1,86 @@
+package grader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codestandoff/backend/internal/checker"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/judge"
+)
+
+// errBrokenChecker is wrapped by errors of checkers that cannot work, which
+// retrying does not fix
+var errBrokenChecker = errors.New("the problem's checker is broken")
+
+// checkerLimits bound each run of a checker program, which reads the whole
+// test input and output
+var checkerLimits = judge.Limits{
+	CPUTime:     5 * time.Second,
+	WallTime:    10 * time.Second,
+	MemoryBytes: 512 << 20,
+	OutputBytes: 64 << 10,
+}
+
+// outputChecker checks outputs with a revision's checker
+type outputChecker struct {
+	spec *checker.Spec
+	// program is the compiled checker program of program checkers
+	program judge.Program
+}
+
+// newOutputChecker returns the checker of a revision, compiling its program
+// if it has one. Close it when done.
+func newOutputChecker(ctx context.Context, j judge.Judge, languages *judge.Registry, revision *database.ProblemRevision) (*outputChecker, error) {
+	spec, err := checker.Parse(revision.Checker)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBrokenChecker, err)
+	}
+	c := &outputChecker{spec: spec}
+	if spec.Kind != checker.Program {
+		return c, nil
+	}
+
+	language, ok := languages.Language(spec.Language)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not available on the judge", errBrokenChecker, spec.Language)
+	}
+	program, compiled, err := j.Compile(ctx, language, map[string]string{language.SourceFile: spec.Source}, judge.DefaultCompileLimits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile checker: %w", err)
+	}
+	if program == nil {
+		return nil, fmt.Errorf("%w: compilation failed: %s", errBrokenChecker, compileMessage(compiled))
+	}
+	c.program = program
+	return c, nil
+}
+
+// check checks the output of a test
+func (c *outputChecker) check(ctx context.Context, tc database.RevisionTestCase, output []byte) (checker.Verdict, error) {
+	if c.program == nil {
+		return c.spec.Check(string(output), tc.ExpectedOutput), nil
+	}
+
+	res, err := c.program.Run(ctx, checker.ProgramStdin(tc.Input, tc.ExpectedOutput, string(output)), checkerLimits)
+	if err != nil {
+		return checker.Verdict{}, fmt.Errorf("failed to run checker: %w", err)
+	}
+	if res.Status != judge.StatusOK && res.Status != judge.StatusRuntimeError {
+		return checker.Verdict{}, fmt.Errorf("%w: checker ended with %s", errBrokenChecker, res.Status)
+	}
+	verdict, err := checker.ProgramVerdict(res.ExitCode, res.Stdout)
+	if err != nil {
+		return checker.Verdict{}, fmt.Errorf("%w: %v", errBrokenChecker, err)
+	}
+	return verdict, nil
+}
+
+// Close releases the checker program
+func (c *outputChecker) Close() {
+	if c.program != nil {
+		c.program.Close()
+	}
+}
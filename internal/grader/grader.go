@@ -0,0 +1,292 @@
+// Package grader judges queued submissions. A Pool of workers claims judge
+// jobs from the database, and a Grader compiles and runs each submission
+// against the test cases of its pinned revision and records the verdict.
+package grader
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"codestandoff/backend/internal/checker"
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/judge"
+	"codestandoff/backend/internal/signature"
+	"codestandoff/backend/internal/textdiff"
+
+	"github.com/google/uuid"
+)
+
+// Limits of runs whose revision and test case set none, matching the defaults of new problems
+const (
+	defaultTimeLimitMs   = 2000
+	defaultMemoryLimitMb = 256
+)
+
+const (
+	// wallTimeSlack is added to the CPU time limit to bound wall time, which
+	// also covers waiting for I/O and the sandbox starting
+	wallTimeSlack = 2 * time.Second
+	// maxOutputBytes bounds the output of a run
+	maxOutputBytes = 8 << 20
+	// maxMessageBytes bounds the compiler or error output kept with a
+	// verdict, and the diff kept with a test result
+	maxMessageBytes = 4 << 10
+	// maxDiffInputBytes bounds how much of an output is diffed
+	maxDiffInputBytes = 64 << 10
+	// diffContextLines is the number of unchanged lines shown around each
+	// change in output diffs
+	diffContextLines = 2
+)
+
+// Grader judges submissions with Judge
+type Grader struct {
+	DB    *sql.DB
+	Judge judge.Judge
+	// Languages says how the judge runs each submission language
+	Languages *judge.Registry
+}
+
+// Grade judges a submission and records its verdict. A submission left
+// compiling or running by an earlier attempt is judged again from the start;
+// one that already has a verdict is left alone. Errors are failures of the
+// grader or the judge, and leave the submission pending. If another worker
+// records a verdict first, Grade returns database.ErrSubmissionStatusChanged.
+func (g *Grader) Grade(ctx context.Context, submissionID uuid.UUID) error {
+	s, err := database.GetSubmissionByID(g.DB, submissionID)
+	if err != nil {
+		return fmt.Errorf("failed to get submission: %w", err)
+	}
+	if database.IsSubmissionFinal(s.Status) {
+		return nil
+	}
+	if s.Status != database.SubmissionQueued {
+		if err := database.RestartSubmission(g.DB, s.ID); err != nil {
+			return err
+		}
+	}
+
+	revision, err := database.GetProblemRevisionByID(g.DB, s.RevisionID)
+	if err != nil {
+		return fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	// Submissions this server cannot judge will not get better on a retry
+	language, ok := g.Languages.Language(s.Language)
+	if !ok {
+		return database.AbortSubmission(g.DB, s.ID, fmt.Sprintf("%s is not available on the judge", s.Language))
+	}
+	files, err := sourceFiles(revision, language, s.Source)
+	if err != nil {
+		return database.AbortSubmission(g.DB, s.ID, err.Error())
+	}
+	check, err := newOutputChecker(ctx, g.Judge, g.Languages, revision)
+	if err != nil {
+		return g.abortIfBroken(s.ID, err)
+	}
+	defer check.Close()
+
+	status := database.SubmissionQueued
+	if len(language.Compile) > 0 {
+		if err := database.AdvanceSubmission(g.DB, s.ID, status, database.SubmissionCompiling); err != nil {
+			return err
+		}
+		status = database.SubmissionCompiling
+	}
+
+	program, compiled, err := g.Judge.Compile(ctx, language, files, judge.DefaultCompileLimits)
+	if err != nil {
+		return fmt.Errorf("failed to compile: %w", err)
+	}
+	if program == nil {
+		return database.FinishSubmission(g.DB, s.ID, status, database.SubmissionResult{
+			Status:  database.SubmissionCompileError,
+			Message: compileMessage(compiled),
+		})
+	}
+	defer program.Close()
+
+	if err := database.AdvanceSubmission(g.DB, s.ID, status, database.SubmissionRunning); err != nil {
+		return err
+	}
+
+	result, err := runTests(ctx, program, revision, language, check)
+	if err != nil {
+		return g.abortIfBroken(s.ID, err)
+	}
+	return database.FinishSubmission(g.DB, s.ID, database.SubmissionRunning, *result)
+}
+
+// abortIfBroken ends a submission with an internal error if err is from a
+// broken checker, which no retry fixes, and returns err otherwise
+func (g *Grader) abortIfBroken(submissionID uuid.UUID, err error) error {
+	if !errors.Is(err, errBrokenChecker) {
+		return err
+	}
+	log.Printf("[grader] submission %s: %v", submissionID, err)
+	return database.AbortSubmission(g.DB, submissionID, "The problem's checker failed. Please report the problem.")
+}
+
+// sourceFiles returns the files to compile: the revision's harness around
+// the solution, or the program alone if the revision has no function signature
+func sourceFiles(revision *database.ProblemRevision, language judge.Language, source string) (map[string]string, error) {
+	if len(revision.Signature) == 0 {
+		return map[string]string{language.SourceFile: source}, nil
+	}
+
+	spec, err := signature.Parse(revision.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid function signature: %w", err)
+	}
+	harness, err := spec.Harness(language.Name)
+	if err != nil {
+		return nil, err
+	}
+	return harness.Files(source), nil
+}
+
+// runTests runs program on every test case in order and stops at the first
+// that fails. Time and memory are the peaks over the cases that ran.
+func runTests(ctx context.Context, program judge.Program, revision *database.ProblemRevision, language judge.Language, check *outputChecker) (*database.SubmissionResult, error) {
+	result := &database.SubmissionResult{
+		Status:     database.SubmissionAccepted,
+		TestsTotal: len(revision.TestCases),
+	}
+
+	for i, tc := range revision.TestCases {
+		input := tc.Input
+		if !strings.HasSuffix(input, "\n") {
+			input += "\n"
+		}
+
+		res, err := program.Run(ctx, []byte(input), testLimits(revision, tc, language))
+		if err != nil {
+			return nil, fmt.Errorf("failed to run test %d: %w", i+1, err)
+		}
+		result.TimeMs = max(result.TimeMs, int(res.Usage.CPUTime.Milliseconds()))
+		result.MemoryKb = max(result.MemoryKb, int(res.Usage.MemoryBytes/1024))
+
+		test := database.SubmissionTestResult{
+			Position:   i + 1,
+			IsSample:   tc.IsSample,
+			TimeMs:     int(res.Usage.CPUTime.Milliseconds()),
+			WallTimeMs: int(res.Usage.WallTime.Milliseconds()),
+			MemoryKb:   int(res.Usage.MemoryBytes / 1024),
+		}
+		status, message := testVerdict(res)
+		detail, diff := "", ""
+		if status == database.SubmissionRuntimeError {
+			detail = truncateMessage(res.Stderr)
+		}
+		if status == database.SubmissionAccepted {
+			verdict, err := check.check(ctx, tc, res.Stdout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check test %d: %w", i+1, err)
+			}
+			detail = verdict.Message
+			if !verdict.OK {
+				status, message = database.SubmissionWrongAnswer, "Wrong answer"
+				diff = outputDiff(tc.ExpectedOutput, res.Stdout)
+			}
+		}
+		test.Status = status
+		// Error output, checker messages and diffs of hidden tests could give them away
+		if tc.IsSample {
+			test.Message, test.Diff = detail, diff
+		}
+		result.Tests = append(result.Tests, test)
+
+		if status != database.SubmissionAccepted {
+			result.Status = status
+			result.Message = fmt.Sprintf("%s on test %d", message, i+1)
+			if test.Message != "" {
+				result.Message += "\n" + test.Message
+			}
+			break
+		}
+		result.TestsPassed++
+	}
+
+	return result, nil
+}
+
+// testVerdict returns the status of a run that ended abnormally and what to
+// call it, or accepted if the run completed and its output is to be checked
+func testVerdict(res *judge.Result) (string, string) {
+	switch res.Status {
+	case judge.StatusTimeLimit:
+		return database.SubmissionTimeLimitExceeded, "Time limit exceeded"
+	case judge.StatusMemoryLimit:
+		return database.SubmissionMemoryLimitExceeded, "Memory limit exceeded"
+	case judge.StatusOutputLimit:
+		return database.SubmissionRuntimeError, "Output limit exceeded"
+	case judge.StatusRuntimeError:
+		if res.Signal != "" {
+			return database.SubmissionRuntimeError, "Runtime error (" + res.Signal + ")"
+		}
+		return database.SubmissionRuntimeError, fmt.Sprintf("Runtime error (exit code %d)", res.ExitCode)
+	}
+	return database.SubmissionAccepted, ""
+}
+
+// testLimits returns the limits of one test: the test case's own, else the
+// revision's, else the defaults, scaled by the language's multipliers
+func testLimits(revision *database.ProblemRevision, tc database.RevisionTestCase, language judge.Language) judge.Limits {
+	timeLimitMs := defaultTimeLimitMs
+	if tc.TimeLimitMs != nil {
+		timeLimitMs = *tc.TimeLimitMs
+	} else if revision.TimeLimitMs.Valid {
+		timeLimitMs = int(revision.TimeLimitMs.Int64)
+	}
+	memoryLimitMb := defaultMemoryLimitMb
+	if tc.MemoryLimitMb != nil {
+		memoryLimitMb = *tc.MemoryLimitMb
+	} else if revision.MemoryLimitMb.Valid {
+		memoryLimitMb = int(revision.MemoryLimitMb.Int64)
+	}
+
+	cpu := time.Duration(float64(timeLimitMs)*language.TimeMultiplier) * time.Millisecond
+	return judge.Limits{
+		CPUTime:     cpu,
+		WallTime:    cpu + wallTimeSlack,
+		MemoryBytes: int64(float64(memoryLimitMb)*language.MemoryMultiplier) << 20,
+		OutputBytes: maxOutputBytes,
+	}
+}
+
+// outputDiff returns a unified diff from the expected output to the output,
+// ignoring trailing whitespace like the exact checker, truncated to
+// maxMessageBytes. Only the start of long outputs is compared.
+func outputDiff(expected string, output []byte) string {
+	want := strings.Join(checker.Lines(expected[:min(len(expected), maxDiffInputBytes)]), "\n")
+	got := strings.Join(checker.Lines(string(output[:min(len(output), maxDiffInputBytes)])), "\n")
+	return truncateMessage([]byte(textdiff.Unified(want, got, diffContextLines)))
+}
+
+// compileMessage is what the compiler printed, or why it was stopped
+func compileMessage(res *judge.Result) string {
+	output := bytes.TrimSpace(append(append([]byte{}, res.Stderr...), res.Stdout...))
+	switch res.Status {
+	case judge.StatusTimeLimit:
+		return "Compilation timed out"
+	case judge.StatusMemoryLimit:
+		return "Compilation ran out of memory"
+	}
+	if len(output) == 0 {
+		return fmt.Sprintf("Compilation failed (exit code %d)", res.ExitCode)
+	}
+	return truncateMessage(output)
+}
+
+// truncateMessage keeps the first maxMessageBytes of output
+func truncateMessage(output []byte) string {
+	if len(output) <= maxMessageBytes {
+		return string(output)
+	}
+	return strings.ToValidUTF8(string(output[:maxMessageBytes]), "") + "\n..."
+}
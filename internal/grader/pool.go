@@ -0,0 +1,246 @@
+package grader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/jobs"
+)
+
+// Config configures a Pool
+type Config struct {
+	// Workers is how many submissions this server judges at once
+	Workers int
+	// UserConcurrency bounds how many of one user's submissions are judged at
+	// once, across all servers
+	UserConcurrency int
+	// MaxAttempts bounds how often a job is tried before its submission ends
+	// with an internal error
+	MaxAttempts int
+	// PollInterval is how long an idle worker waits before looking for a job again
+	PollInterval time.Duration
+	// HeartbeatInterval is how often a worker reports that its job is still running
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long a running job may go without a heartbeat before
+	// it is taken for a crashed worker's and queued again
+	StaleAfter time.Duration
+	// RetryBackoff is the delay before the second attempt, doubling for every
+	// later one up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// RetainFinished is how long finished jobs are kept for queue statistics
+	RetainFinished time.Duration
+}
+
+// ConfigFromEnv reads JUDGE_WORKERS, JUDGE_USER_CONCURRENCY, JUDGE_MAX_ATTEMPTS
+// and JUDGE_POLL_INTERVAL, using defaults for the rest
+func ConfigFromEnv() Config {
+	return Config{
+		Workers:           envInt("JUDGE_WORKERS", runtime.NumCPU()),
+		UserConcurrency:   envInt("JUDGE_USER_CONCURRENCY", 2),
+		MaxAttempts:       envInt("JUDGE_MAX_ATTEMPTS", 3),
+		PollInterval:      jobs.Interval("JUDGE_POLL_INTERVAL", time.Second),
+		HeartbeatInterval: 10 * time.Second,
+		StaleAfter:        time.Minute,
+		RetryBackoff:      5 * time.Second,
+		MaxRetryBackoff:   5 * time.Minute,
+		RetainFinished:    24 * time.Hour,
+	}
+}
+
+// envInt reads a non-negative integer from the environment variable key,
+// falling back to def when it is unset or invalid
+func envInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("[grader] invalid %s %q, using %d", key, value, def)
+		return def
+	}
+	return n
+}
+
+// Pool runs workers that claim judge jobs and grade their submissions
+type Pool struct {
+	grader *Grader
+	config Config
+	// name identifies this process in the workers' names
+	name string
+}
+
+// NewPool returns a pool grading with g
+func NewPool(g *Grader, config Config) *Pool {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Pool{grader: g, config: config, name: fmt.Sprintf("%s-%d", host, os.Getpid())}
+}
+
+// Run starts the workers and the recovery of stale jobs, and returns once ctx
+// is cancelled and every worker has stopped. Jobs interrupted by the
+// cancellation are queued again.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.config.Workers; i++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			p.work(ctx, worker)
+		}(fmt.Sprintf("%s-%d", p.name, i))
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.recover(ctx)
+	}()
+
+	log.Printf("[grader] started %d workers", p.config.Workers)
+	wg.Wait()
+}
+
+// work claims and processes jobs until ctx is cancelled
+func (p *Pool) work(ctx context.Context, worker string) {
+	for ctx.Err() == nil {
+		job, err := database.ClaimJudgeJob(p.grader.DB, worker, p.config.UserConcurrency)
+		if err != nil {
+			log.Printf("[grader] %s: %v", worker, err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.config.PollInterval):
+			}
+			continue
+		}
+		p.process(ctx, worker, job)
+	}
+}
+
+// process grades a claimed job's submission and records how the job ended
+func (p *Pool) process(ctx context.Context, worker string, job *database.JudgeJob) {
+	db := p.grader.DB
+	if job.Attempts > p.config.MaxAttempts {
+		p.giveUp(worker, job, job.LastError.String)
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var lost atomic.Bool
+	go p.heartbeat(jobCtx, worker, job, func() {
+		lost.Store(true)
+		cancel()
+	})
+
+	start := time.Now()
+	err := p.grader.Grade(jobCtx, job.SubmissionID)
+	cancel()
+
+	switch {
+	case lost.Load():
+		// Another worker has the job now
+		log.Printf("[grader] %s lost job %d to recovery", worker, job.ID)
+	case err == nil || errors.Is(err, database.ErrSubmissionStatusChanged):
+		if err := database.CompleteJudgeJob(db, job.ID, worker); err != nil {
+			log.Printf("[grader] %s: %v", worker, err)
+		}
+		log.Printf("[grader] %s judged submission %s in %s", worker, job.SubmissionID, time.Since(start).Round(time.Millisecond))
+	case ctx.Err() != nil:
+		// Shutting down; another worker picks the job up straight away
+		if err := database.RetryJudgeJob(db, job.ID, worker, 0, "worker stopped"); err != nil {
+			log.Printf("[grader] %s: %v", worker, err)
+		}
+	case job.Attempts >= p.config.MaxAttempts:
+		p.giveUp(worker, job, err.Error())
+	default:
+		delay := p.backoff(job.Attempts)
+		log.Printf("[grader] %s failed submission %s, retrying in %s: %v", worker, job.SubmissionID, delay, err)
+		if err := database.RetryJudgeJob(db, job.ID, worker, delay, err.Error()); err != nil {
+			log.Printf("[grader] %s: %v", worker, err)
+		}
+	}
+}
+
+// giveUp ends a job that failed too often, and its submission with an internal error
+func (p *Pool) giveUp(worker string, job *database.JudgeJob, lastError string) {
+	log.Printf("[grader] %s gave up on submission %s after %d attempts: %s", worker, job.SubmissionID, job.Attempts, lastError)
+	err := database.AbortSubmission(p.grader.DB, job.SubmissionID, "The judge failed to judge this submission. Please submit again.")
+	if err != nil && !errors.Is(err, database.ErrSubmissionStatusChanged) {
+		log.Printf("[grader] %s: %v", worker, err)
+		return
+	}
+	if err := database.FailJudgeJob(p.grader.DB, job.ID, worker, lastError); err != nil {
+		log.Printf("[grader] %s: %v", worker, err)
+	}
+}
+
+// heartbeat reports the job as running until ctx is cancelled, and calls
+// lost if the job turns out to have been recovered from this worker
+func (p *Pool) heartbeat(ctx context.Context, worker string, job *database.JudgeJob, lost func()) {
+	ticker := time.NewTicker(p.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := database.HeartbeatJudgeJob(p.grader.DB, job.ID, worker)
+		if err != nil {
+			log.Printf("[grader] %s: heartbeat failed: %v", worker, err)
+			continue
+		}
+		if !ok {
+			lost()
+			return
+		}
+	}
+}
+
+// recover periodically queues stale jobs again and purges old finished ones
+func (p *Pool) recover(ctx context.Context) {
+	ticker := time.NewTicker(p.config.StaleAfter / 2)
+	defer ticker.Stop()
+
+	for {
+		if n, err := database.RecoverJudgeJobs(p.grader.DB, p.config.StaleAfter); err != nil {
+			log.Printf("[grader] %v", err)
+		} else if n > 0 {
+			log.Printf("[grader] recovered %d stale judge jobs", n)
+		}
+		if _, err := database.PurgeJudgeJobs(p.grader.DB, p.config.RetainFinished); err != nil {
+			log.Printf("[grader] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backoff returns the delay after a job's attempt-th failed attempt
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.config.RetryBackoff
+	for i := 1; i < attempt && delay < p.config.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.config.MaxRetryBackoff)
+}
@@ -0,0 +1,170 @@
+package grader
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/judge"
+)
+
+// Limits of running code outside of judging, which are lower than the
+// limits of problems so that runs stay quick and cheap. Time and memory are
+// scaled by the language's multipliers like the limits of tests.
+const (
+	maxRunTimeLimitMs   = 2000
+	maxRunMemoryLimitMb = 256
+	maxRunOutputBytes   = 64 << 10
+)
+
+// RunConfig configures a Runner
+type RunConfig struct {
+	// Concurrency is how many runs this server executes at once; further runs
+	// wait for one to finish
+	Concurrency int
+	// RateLimit bounds how often one user may run code per RateWindow
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// RunConfigFromEnv reads RUN_CODE_CONCURRENCY and RUN_CODE_RATE_LIMIT, the
+// runs allowed per user per minute
+func RunConfigFromEnv() RunConfig {
+	return RunConfig{
+		Concurrency: envInt("RUN_CODE_CONCURRENCY", runtime.NumCPU()),
+		RateLimit:   envInt("RUN_CODE_RATE_LIMIT", 10),
+		RateWindow:  time.Minute,
+	}
+}
+
+// Runner compiles and runs code on custom input or sample tests without
+// recording a submission
+type Runner struct {
+	judge     judge.Judge
+	languages *judge.Registry
+	config    RunConfig
+	// slots holds a token for every run in progress
+	slots chan struct{}
+}
+
+// NewRunner returns a runner executing code with j
+func NewRunner(j judge.Judge, languages *judge.Registry, config RunConfig) *Runner {
+	return &Runner{judge: j, languages: languages, config: config, slots: make(chan struct{}, max(config.Concurrency, 1))}
+}
+
+// Config returns the runner's configuration
+func (r *Runner) Config() RunConfig {
+	return r.config
+}
+
+// RunResult is the outcome of running code
+type RunResult struct {
+	// CompileError is what the compiler printed if the code did not compile;
+	// Runs is then empty
+	CompileError string
+	Runs         []CaseRun
+}
+
+// CaseRun is how code ran on one input
+type CaseRun struct {
+	Input string
+	// ExpectedOutput is set when running a sample test
+	ExpectedOutput *string
+	// Passed reports whether the run completed and the problem's checker
+	// accepted its output
+	Passed bool
+	// CheckerMessage is what the checker said about the output
+	CheckerMessage string
+	Result         *judge.Result
+}
+
+// Run compiles source and runs it on input, or on the revision's sample
+// tests if input is nil, under the lower limits of runs
+func (r *Runner) Run(ctx context.Context, revision *database.ProblemRevision, languageName, source string, input *string) (*RunResult, error) {
+	language, ok := r.languages.Language(languageName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not available on the judge", languageName)
+	}
+	files, err := sourceFiles(revision, language, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []database.RevisionTestCase
+	if input != nil {
+		cases = []database.RevisionTestCase{{Input: *input}}
+	} else {
+		for _, tc := range revision.TestCases {
+			if tc.IsSample {
+				cases = append(cases, tc)
+			}
+		}
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var check *outputChecker
+	if input == nil {
+		check, err = newOutputChecker(ctx, r.judge, r.languages, revision)
+		if err != nil {
+			return nil, err
+		}
+		defer check.Close()
+	}
+
+	program, compiled, err := r.judge.Compile(ctx, language, files, judge.DefaultCompileLimits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile: %w", err)
+	}
+	if program == nil {
+		return &RunResult{CompileError: compileMessage(compiled)}, nil
+	}
+	defer program.Close()
+
+	result := &RunResult{Runs: make([]CaseRun, 0, len(cases))}
+	for i, tc := range cases {
+		stdin := tc.Input
+		if !strings.HasSuffix(stdin, "\n") {
+			stdin += "\n"
+		}
+
+		res, err := program.Run(ctx, []byte(stdin), runLimits(revision, tc, language))
+		if err != nil {
+			return nil, fmt.Errorf("failed to run on input %d: %w", i+1, err)
+		}
+
+		run := CaseRun{Input: tc.Input, Result: res}
+		if check != nil {
+			run.ExpectedOutput = &tc.ExpectedOutput
+			if res.Status == judge.StatusOK {
+				verdict, err := check.check(ctx, tc, res.Stdout)
+				if err != nil {
+					return nil, err
+				}
+				run.Passed, run.CheckerMessage = verdict.OK, verdict.Message
+			}
+		}
+		result.Runs = append(result.Runs, run)
+	}
+
+	return result, nil
+}
+
+// runLimits returns the limits of running code on a test: the test's own,
+// capped at the limits of runs
+func runLimits(revision *database.ProblemRevision, tc database.RevisionTestCase, language judge.Language) judge.Limits {
+	limits := testLimits(revision, tc, language)
+	limits.CPUTime = min(limits.CPUTime, time.Duration(float64(maxRunTimeLimitMs)*language.TimeMultiplier)*time.Millisecond)
+	limits.WallTime = limits.CPUTime + wallTimeSlack
+	limits.MemoryBytes = min(limits.MemoryBytes, int64(float64(maxRunMemoryLimitMb)*language.MemoryMultiplier)<<20)
+	limits.OutputBytes = maxRunOutputBytes
+	return limits
+}
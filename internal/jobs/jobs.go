@@ -0,0 +1,46 @@
+// Package jobs runs background maintenance tasks on a schedule.
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// Every runs job once immediately and then every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func Every(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := job(ctx); err != nil {
+			log.Printf("[jobs] %s failed: %v", name, err)
+		} else {
+			log.Printf("[jobs] %s finished in %s", name, time.Since(start).Round(time.Millisecond))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Interval reads a duration such as "30m" from the environment variable key,
+// falling back to def when it is unset or invalid
+func Interval(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("[jobs] invalid %s %q, using %s", key, value, def)
+		return def
+	}
+	return d
+}
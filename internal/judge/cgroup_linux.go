@@ -0,0 +1,114 @@
+//go:build linux
+
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// sandboxInitTasks is how many tasks the sandbox init, which joins the run's
+// cgroup to start the command in it, may add to the cgroup's pids count:
+// itself and the threads of the Go runtime
+const sandboxInitTasks = 16
+
+// runCgroup is the cgroup v2 group of one compilation or run. Its memory
+// limit covers every process of the command together, and the files they
+// leave in the tmpfs /tmp.
+type runCgroup struct {
+	dir string
+}
+
+// checkCgroup checks that dir is a cgroup v2 group whose children get the
+// memory and pids controllers
+func checkCgroup(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, "cgroup.subtree_control"))
+	if err != nil {
+		return fmt.Errorf("invalid judge cgroup: %w", err)
+	}
+	controllers := strings.Fields(string(data))
+	for _, c := range []string{"memory", "pids"} {
+		if !slices.Contains(controllers, c) {
+			return fmt.Errorf("judge cgroup %s does not enable the %s controller for its children", dir, c)
+		}
+	}
+	return nil
+}
+
+// newRunCgroup creates a cgroup under parent allowing memoryBytes of memory,
+// no swap, and processes tasks besides the sandbox init
+func newRunCgroup(parent string, memoryBytes int64, processes int) (*runCgroup, error) {
+	dir, err := os.MkdirTemp(parent, "run-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	c := &runCgroup{dir: dir}
+	limits := []struct{ file, value string }{
+		{"memory.max", strconv.FormatInt(memoryBytes, 10)},
+		{"memory.swap.max", "0"},
+		{"pids.max", strconv.Itoa(processes + sandboxInitTasks)},
+	}
+	for _, l := range limits {
+		err := os.WriteFile(filepath.Join(dir, l.file), []byte(l.value), 0)
+		// Without swap accounting there is no swap to limit
+		if err != nil && !(l.file == "memory.swap.max" && errors.Is(err, os.ErrNotExist)) {
+			c.remove()
+			return nil, fmt.Errorf("failed to set %s: %w", l.file, err)
+		}
+	}
+	return c, nil
+}
+
+// openProcs opens the file a process writes to join the cgroup. Since Linux
+// 5.16 the write is checked against whoever opened the file, so the sandbox
+// init can join with it after leaving the host's user namespace.
+func (c *runCgroup) openProcs() (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(c.dir, "cgroup.procs"), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	return f, nil
+}
+
+// usage returns the cgroup's peak memory and whether the OOM killer killed
+// any of its processes
+func (c *runCgroup) usage() (int64, bool, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, "memory.peak"))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read peak memory: %w", err)
+	}
+	peak, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid peak memory %q", data)
+	}
+	data, err = os.ReadFile(filepath.Join(c.dir, "memory.events"))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read memory events: %w", err)
+	}
+	oomKilled := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if n, ok := strings.CutPrefix(line, "oom_kill "); ok && n != "0" {
+			oomKilled = true
+		}
+	}
+	return peak, oomKilled, nil
+}
+
+// remove kills whatever is left in the cgroup and removes it
+func (c *runCgroup) remove() {
+	os.WriteFile(filepath.Join(c.dir, "cgroup.kill"), []byte("1"), 0)
+	// Killed processes leave the cgroup shortly after
+	for i := 0; i < 100; i++ {
+		if err := syscall.Rmdir(c.dir); err != syscall.EBUSY {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
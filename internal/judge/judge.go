@@ -0,0 +1,117 @@
+// Package judge compiles and runs untrusted programs. A Judge turns source
+// files into a Program, which can then be run against many inputs; every
+// compilation and run is limited in CPU time, wall time, memory and output
+// and reports what it used. Local is the implementation that isolates runs on
+// this machine.
+package judge
+
+import (
+	"context"
+	"time"
+)
+
+// Judge compiles programs for running
+type Judge interface {
+	// Compile writes files to a fresh workspace and runs the language's
+	// compile command there, if it has one. When compilation fails, the
+	// result says why and the program is nil. Errors are reserved for
+	// failures of the judge itself, never of the code being judged.
+	Compile(ctx context.Context, language Language, files map[string]string, limits Limits) (Program, *Result, error)
+}
+
+// Program is a compiled program that can be run any number of times
+type Program interface {
+	// Run runs the program with stdin as its input. Errors are reserved for
+	// failures of the judge itself.
+	Run(ctx context.Context, stdin []byte, limits Limits) (*Result, error)
+	// Close removes the program's workspace
+	Close() error
+}
+
+// Language says how to compile and run programs written in a language.
+// Commands run in the workspace holding the program's files.
+type Language struct {
+	Name string `json:"name"`
+	// Version names the compiler or runtime, e.g. "GCC 12, C++17"
+	Version string `json:"version"`
+	// SourceFile is the file a whole program is written to when no harness
+	// wraps it
+	SourceFile string `json:"sourceFile"`
+	// Compile is empty for interpreted languages
+	Compile []string `json:"compile"`
+	Run     []string `json:"run"`
+	// Env holds extra NAME=value environment variables for both commands
+	Env []string `json:"env"`
+	// TimeMultiplier and MemoryMultiplier scale a problem's limits for
+	// programs in this language, making up for slower runtimes
+	TimeMultiplier   float64 `json:"timeMultiplier"`
+	MemoryMultiplier float64 `json:"memoryMultiplier"`
+	// Enabled languages are accepted for new submissions
+	Enabled bool `json:"enabled"`
+}
+
+// Limits bound the resources of one compilation or run
+type Limits struct {
+	CPUTime  time.Duration
+	WallTime time.Duration
+	// MemoryBytes bounds the program's memory, counting its files in /tmp,
+	// which is a tmpfs of the same size
+	MemoryBytes int64
+	// OutputBytes bounds stdout and stderr, each
+	OutputBytes int64
+	// StackBytes bounds the main thread's stack and is the default stack of
+	// other threads, so it is counted again for each thread; 0 means
+	// DefaultStackBytes
+	StackBytes int64
+	// Processes bounds the processes and threads the program may create;
+	// 0 means DefaultProcesses
+	Processes int
+}
+
+// Defaults for zero Limits fields
+const (
+	DefaultProcesses  = 64
+	DefaultStackBytes = 8 << 20
+)
+
+// DefaultCompileLimits are generous limits for compilers, which need far more
+// than the programs they build
+var DefaultCompileLimits = Limits{
+	CPUTime:     30 * time.Second,
+	WallTime:    60 * time.Second,
+	MemoryBytes: 2 << 30,
+	OutputBytes: 64 << 10,
+	Processes:   256,
+}
+
+// Status is how a run ended
+type Status string
+
+const (
+	StatusOK           Status = "ok"
+	StatusTimeLimit    Status = "time_limit"
+	StatusMemoryLimit  Status = "memory_limit"
+	StatusOutputLimit  Status = "output_limit"
+	StatusRuntimeError Status = "runtime_error"
+)
+
+// Result is the outcome of one compilation or run
+type Result struct {
+	Status Status
+	// ExitCode is -1 when the program was killed by a signal
+	ExitCode int
+	// Signal is the name of the signal that killed the program, if any
+	Signal string
+	// Stdout and Stderr are truncated to Limits.OutputBytes
+	Stdout []byte
+	Stderr []byte
+	Usage  Usage
+}
+
+// Usage is what a run consumed
+type Usage struct {
+	CPUTime  time.Duration
+	WallTime time.Duration
+	// MemoryBytes is the peak memory, counting files left in /tmp
+	MemoryBytes int64
+}
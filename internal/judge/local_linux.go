@@ -0,0 +1,465 @@
+//go:build linux
+
+package judge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// nobody is the host user sandboxes run as when the server runs as root
+const nobody = 65534
+
+// killGrace is how long a stopped sandbox has to report before it is killed
+const killGrace = time.Second
+
+// DefaultReadOnlyPaths are the host paths visible inside the sandbox, read
+// only. Globs are expanded and missing paths skipped. Nothing else of the
+// host filesystem is visible.
+var DefaultReadOnlyPaths = []string{
+	"/bin",
+	"/sbin",
+	"/lib",
+	"/lib32",
+	"/lib64",
+	"/usr",
+	"/etc/alternatives",
+	"/etc/ld.so.cache",
+	"/etc/ld.so.conf",
+	"/etc/ld.so.conf.d",
+	"/etc/java-*",
+}
+
+// baseEnv is the environment of every sandboxed command, before the language's own
+var baseEnv = []string{
+	"PATH=/usr/local/go/bin:/usr/local/bin:/usr/bin:/bin",
+	"HOME=/tmp",
+	"TMPDIR=/tmp",
+	"LANG=C.UTF-8",
+}
+
+// Local runs programs on this machine. Each compilation and run gets its own
+// user, mount, PID, network, IPC and UTS namespaces: the program sees a
+// read-only root holding only ReadOnlyPaths, its workspace at /work
+// (writable only while compiling), a private /tmp and no network. rlimits
+// bound its CPU time, memory, processes and files; a seccomp filter blocks
+// syscalls that could reach outside the sandbox, and it runs without
+// capabilities. Given a cgroup, each run also gets a cgroup of its own that
+// bounds the memory and tasks of all its processes and its files in /tmp
+// together; otherwise memory is bounded per process and the files in /tmp
+// are only counted afterwards. The sandbox setup runs in a re-executed copy
+// of the current binary, so the binary must be executable by the sandbox user.
+type Local struct {
+	// ReadOnlyPaths are the host paths visible inside the sandbox
+	ReadOnlyPaths []string
+
+	dir      string
+	cgroup   string
+	uid, gid int
+}
+
+var _ Judge = (*Local)(nil)
+
+// NewLocal returns a local judge keeping program workspaces under dir. cgroup
+// is an optional cgroup v2 directory, delegated to this process, that runs
+// get their cgroups under; it needs Linux 5.19 or later. NewLocal fails if
+// the kernel does not allow the namespaces or cgroups it needs.
+func NewLocal(dir, cgroup string) (*Local, error) {
+	if seccompArch == 0 {
+		return nil, errors.New("the local judge does not support this architecture")
+	}
+	if err := os.MkdirAll(dir, 0o711); err != nil {
+		return nil, fmt.Errorf("failed to create judge directory: %w", err)
+	}
+	if cgroup != "" {
+		if err := checkCgroup(cgroup); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &Local{
+		ReadOnlyPaths: DefaultReadOnlyPaths,
+		dir:           dir,
+		cgroup:        cgroup,
+		uid:           os.Getuid(),
+		gid:           os.Getgid(),
+	}
+	// Never map the sandbox to host root
+	if l.uid == 0 {
+		l.uid, l.gid = nobody, nobody
+	}
+
+	// Check the sandbox works by running something trivial
+	p, res, err := l.Compile(context.Background(), Language{Name: "check", Run: []string{"true"}}, nil, DefaultCompileLimits)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	if res, err = p.Run(context.Background(), nil, DefaultCompileLimits); err != nil {
+		return nil, fmt.Errorf("sandbox check failed: %w", err)
+	}
+	if res.Status != StatusOK {
+		return nil, fmt.Errorf("sandbox check failed: %s: %s", res.Status, res.Stderr)
+	}
+
+	return l, nil
+}
+
+// Compile writes files to a fresh workspace and compiles them
+func (l *Local) Compile(ctx context.Context, language Language, files map[string]string, limits Limits) (Program, *Result, error) {
+	dir, err := os.MkdirTemp(l.dir, "program-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	p := &localProgram{judge: l, dir: dir, language: language}
+
+	// The sandbox user has to reach the workspace inside it
+	if err := os.Chmod(dir, 0o711); err != nil {
+		p.Close()
+		return nil, nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	if err := p.prepare(files); err != nil {
+		p.Close()
+		return nil, nil, err
+	}
+	if len(language.Compile) == 0 {
+		return p, nil, nil
+	}
+
+	res, err := l.run(ctx, p, true, language.Compile, nil, limits)
+	if err != nil {
+		p.Close()
+		return nil, nil, err
+	}
+	if res.Status != StatusOK {
+		p.Close()
+		return nil, res, nil
+	}
+	return p, res, nil
+}
+
+// localProgram is a workspace under Local's directory: work holds the
+// program's files and root is the empty mount point its sandbox root is built on
+type localProgram struct {
+	judge    *Local
+	dir      string
+	language Language
+}
+
+func (p *localProgram) work() string { return filepath.Join(p.dir, "work") }
+func (p *localProgram) root() string { return filepath.Join(p.dir, "root") }
+
+// prepare creates the workspace and writes files into it
+func (p *localProgram) prepare(files map[string]string) error {
+	for _, d := range []string{p.work(), p.root()} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+	}
+	for name, content := range files {
+		if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(p.work(), name), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	// The sandbox user must own its workspace to compile into it
+	return filepath.Walk(p.work(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, p.judge.uid, p.judge.gid)
+	})
+}
+
+// Run runs the compiled program with a read-only workspace
+func (p *localProgram) Run(ctx context.Context, stdin []byte, limits Limits) (*Result, error) {
+	return p.judge.run(ctx, p, false, p.language.Run, stdin, limits)
+}
+
+// Close removes the workspace
+func (p *localProgram) Close() error {
+	return os.RemoveAll(p.dir)
+}
+
+// run executes args in a new sandbox around p's workspace
+func (l *Local) run(ctx context.Context, p *localProgram, writable bool, args []string, stdin []byte, limits Limits) (*Result, error) {
+	readOnly, err := expandPaths(l.ReadOnlyPaths)
+	if err != nil {
+		return nil, err
+	}
+	processes := limits.Processes
+	if processes == 0 {
+		processes = DefaultProcesses
+	}
+	stack := limits.StackBytes
+	if stack == 0 {
+		stack = DefaultStackBytes
+	}
+
+	cfg := sandboxConfig{
+		Root:          p.root(),
+		Work:          p.work(),
+		WorkWritable:  writable,
+		ReadOnlyPaths: readOnly,
+		TmpBytes:      limits.MemoryBytes,
+		Args:          args,
+		Env:           append(append([]string{}, baseEnv...), p.language.Env...),
+		Limits: []rlimit{
+			{syscall.RLIMIT_CPU, cpuSeconds(limits.CPUTime)},
+			{syscall.RLIMIT_DATA, uint64(limits.MemoryBytes)},
+			{syscall.RLIMIT_STACK, uint64(min(stack, limits.MemoryBytes))},
+			{syscall.RLIMIT_FSIZE, maxFileBytes},
+			{syscall.RLIMIT_NOFILE, maxOpenFiles},
+			{syscall.RLIMIT_CORE, 0},
+			{rlimitNPROC, uint64(processes)},
+		},
+	}
+	var cgroup *runCgroup
+	var extraFiles []*os.File
+	if l.cgroup != "" {
+		cgroup, err = newRunCgroup(l.cgroup, limits.MemoryBytes, processes)
+		if err != nil {
+			return nil, err
+		}
+		defer cgroup.remove()
+		procs, err := cgroup.openProcs()
+		if err != nil {
+			return nil, err
+		}
+		defer procs.Close()
+		extraFiles = append(extraFiles, procs)
+		cfg.Cgroup = true
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	configR, configW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer configR.Close()
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		configW.Close()
+		return nil, err
+	}
+	defer errR.Close()
+	statusR, statusW, err := os.Pipe()
+	if err != nil {
+		configW.Close()
+		errW.Close()
+		return nil, err
+	}
+	defer statusR.Close()
+
+	cmd := exec.Command("/proc/self/exe")
+	cmd.Args = []string{sandboxInitArg}
+	cmd.Env = []string{}
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	// Stopping the sandbox init lets it kill and reap the command, so the
+	// command's usage is still reported. Killing it outright kills
+	// everything in the sandbox, and is the fallback when it does not exit.
+	forceKill := time.AfterFunc(time.Hour, func() { cmd.Process.Kill() })
+	forceKill.Stop()
+	var once sync.Once
+	kill := func() {
+		once.Do(func() {
+			cmd.Process.Signal(syscall.SIGTERM)
+			forceKill.Reset(killGrace)
+		})
+	}
+	stdout := &limitedBuffer{limit: limits.OutputBytes, onExceed: kill}
+	stderr := &limitedBuffer{limit: limits.OutputBytes, onExceed: kill}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.ExtraFiles = append([]*os.File{configR, errW, statusW}, extraFiles...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 sandboxCloneFlags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: l.uid, Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: l.gid, Size: 1}},
+		GidMappingsEnableSetgroups: false,
+		// Become the mapped root; otherwise the child keeps the host IDs,
+		// which mean nothing in its namespace and leave it powerless
+		Credential: &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: true},
+		Pdeathsig:  syscall.SIGKILL,
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		configW.Close()
+		errW.Close()
+		statusW.Close()
+		return nil, fmt.Errorf("failed to start sandbox: %w", err)
+	}
+	errW.Close()
+	statusW.Close()
+	_, writeErr := configW.Write(data)
+	configW.Close()
+
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(limits.WallTime, func() {
+		timedOut.Store(true)
+		kill()
+	})
+	stop := context.AfterFunc(ctx, kill)
+
+	waitErr := cmd.Wait()
+	wall := time.Since(start)
+	timer.Stop()
+	forceKill.Stop()
+	stop()
+
+	// The sandbox reports setup failures before it runs anything
+	if setupErr, _ := io.ReadAll(errR); len(setupErr) > 0 {
+		return nil, fmt.Errorf("sandbox setup failed: %s", setupErr)
+	}
+	if writeErr != nil {
+		return nil, fmt.Errorf("failed to configure sandbox: %w", writeErr)
+	}
+	if ctx.Err() != nil && !timedOut.Load() {
+		return nil, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, fmt.Errorf("failed to run sandbox: %w", waitErr)
+	}
+
+	// The sandbox init reports how the command ended and what it used unless
+	// it was killed first, which kills the command with it. Its own rusage
+	// would count the init too, and misses a command it never reaped.
+	status, _ := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	var report sandboxReport
+	if data, _ := io.ReadAll(statusR); len(data) > 0 {
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("invalid sandbox report %q", data)
+		}
+		status = syscall.WaitStatus(report.Status)
+	}
+
+	res := &Result{
+		ExitCode: status.ExitStatus(),
+		Stdout:   stdout.buf.Bytes(),
+		Stderr:   stderr.buf.Bytes(),
+		Usage: Usage{
+			CPUTime:     report.CPUTime,
+			WallTime:    wall,
+			MemoryBytes: report.MemoryBytes,
+		},
+	}
+	if status.Signaled() {
+		res.Signal = status.Signal().String()
+	}
+
+	// A cgroup's peak covers every process of the command and its files in
+	// /tmp. Without one, the files are added to the largest process.
+	oomKilled := false
+	if cgroup != nil {
+		if res.Usage.MemoryBytes, oomKilled, err = cgroup.usage(); err != nil {
+			return nil, err
+		}
+	} else {
+		res.Usage.MemoryBytes += report.TmpUsedBytes
+	}
+	res.Status = classify(res, limits, timedOut.Load(), stdout.exceeded || stderr.exceeded, oomKilled)
+
+	return res, nil
+}
+
+// oomMarkers are printed by language runtimes that fail to allocate memory.
+// A failed allocation under RLIMIT_DATA can leave the peak memory far below
+// the limit, so this is how such failures are told apart from other crashes.
+var oomMarkers = []string{
+	"std::bad_alloc",
+	"MemoryError",
+	"java.lang.OutOfMemoryError",
+	"out of memory",
+	"heap out of memory",
+	"Cannot allocate memory",
+}
+
+// classify decides how a finished run ended. Exceeding the output limit or a
+// time limit wins over the crash it causes.
+func classify(res *Result, limits Limits, timedOut, outputExceeded, oomKilled bool) Status {
+	switch {
+	case outputExceeded:
+		return StatusOutputLimit
+	case timedOut || res.Usage.CPUTime > limits.CPUTime || res.Signal == syscall.SIGXCPU.String():
+		return StatusTimeLimit
+	case oomKilled || res.Usage.MemoryBytes > limits.MemoryBytes:
+		return StatusMemoryLimit
+	case res.ExitCode != 0:
+		// Failing after reaching for the last of the memory is taken as
+		// running out of it
+		if res.Usage.MemoryBytes >= limits.MemoryBytes/10*9 {
+			return StatusMemoryLimit
+		}
+		for _, marker := range oomMarkers {
+			if bytes.Contains(res.Stderr, []byte(marker)) {
+				return StatusMemoryLimit
+			}
+		}
+		return StatusRuntimeError
+	}
+	return StatusOK
+}
+
+// cpuSeconds rounds a CPU limit up to the whole seconds RLIMIT_CPU takes. The
+// measured CPU time is compared with the exact limit afterwards.
+func cpuSeconds(d time.Duration) uint64 {
+	return uint64((d + time.Second - 1) / time.Second)
+}
+
+// expandPaths expands globs in paths and drops paths that do not exist
+func expandPaths(paths []string) ([]string, error) {
+	var expanded []string
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read-only path %q: %w", pattern, err)
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and calls onExceed
+// once more than that arrives. It never fails a write, so the program is
+// not sent SIGPIPE before it is killed.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+	onExceed func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - int64(b.buf.Len()); int64(len(p)) > room {
+		b.buf.Write(p[:max(room, 0)])
+		if !b.exceeded {
+			b.exceeded = true
+			b.onExceed()
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
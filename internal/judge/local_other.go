@@ -0,0 +1,24 @@
+//go:build !linux
+
+package judge
+
+import (
+	"context"
+	"errors"
+)
+
+// errUnsupported is returned outside Linux, which provides the isolation Local relies on
+var errUnsupported = errors.New("the local judge requires Linux")
+
+// Local is unavailable outside Linux
+type Local struct{}
+
+// NewLocal always fails outside Linux
+func NewLocal(dir, cgroup string) (*Local, error) {
+	return nil, errUnsupported
+}
+
+// Compile always fails outside Linux
+func (l *Local) Compile(ctx context.Context, language Language, files map[string]string, limits Limits) (Program, *Result, error) {
+	return nil, nil, errUnsupported
+}
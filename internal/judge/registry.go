@@ -0,0 +1,142 @@
+package judge
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultLanguages is the built-in language config, for the toolchains of
+// the server image
+//
+//go:embed languages.json
+var defaultLanguages []byte
+
+// languageName is the form of language names, which submissions refer to
+var languageName = regexp.MustCompile(`^[a-z][a-z0-9+#_-]*$`)
+
+// Registry is the set of languages the judge can run, loaded from a JSON
+// config file shaped like languages.json:
+//
+//	{"languages": [{"name": "cpp", "version": "GCC 12, C++17", "sourceFile": "main.cpp",
+//	  "compile": ["g++", ...], "run": ["./main"], "env": [],
+//	  "timeMultiplier": 1, "memoryMultiplier": 1, "enabled": true}]}
+//
+// Multipliers default to 1. Languages with function signature harnesses
+// must use the names of signature.Languages.
+type Registry struct {
+	// languages is sorted by name
+	languages []Language
+	byName    map[string]Language
+}
+
+type registryConfig struct {
+	Languages []Language `json:"languages"`
+}
+
+// LoadRegistry reads the registry from a config file, or returns the
+// built-in registry when path is empty
+func LoadRegistry(path string) (*Registry, error) {
+	if path == "" {
+		return DefaultRegistry(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read language config: %w", err)
+	}
+	r, err := ParseRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language config %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// DefaultRegistry returns the built-in languages
+func DefaultRegistry() *Registry {
+	r, err := ParseRegistry(defaultLanguages)
+	if err != nil {
+		panic("invalid built-in language config: " + err.Error())
+	}
+	return r
+}
+
+// ParseRegistry decodes and validates a language config
+func ParseRegistry(data []byte) (*Registry, error) {
+	var cfg registryConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	r := &Registry{byName: make(map[string]Language)}
+	for _, l := range cfg.Languages {
+		if err := validateLanguage(&l); err != nil {
+			return nil, err
+		}
+		if _, ok := r.byName[l.Name]; ok {
+			return nil, fmt.Errorf("language %s is defined twice", l.Name)
+		}
+		r.byName[l.Name] = l
+		r.languages = append(r.languages, l)
+	}
+	if len(r.languages) == 0 {
+		return nil, errors.New("no languages defined")
+	}
+	sort.Slice(r.languages, func(i, j int) bool { return r.languages[i].Name < r.languages[j].Name })
+
+	return r, nil
+}
+
+// validateLanguage checks a configured language and fills in default multipliers
+func validateLanguage(l *Language) error {
+	if !languageName.MatchString(l.Name) {
+		return fmt.Errorf("invalid language name %q", l.Name)
+	}
+	if l.SourceFile == "" || l.SourceFile != filepath.Base(l.SourceFile) || strings.HasPrefix(l.SourceFile, ".") {
+		return fmt.Errorf("language %s: invalid source file %q", l.Name, l.SourceFile)
+	}
+	if len(l.Run) == 0 {
+		return fmt.Errorf("language %s: run command is required", l.Name)
+	}
+	for _, kv := range l.Env {
+		if !strings.Contains(kv, "=") {
+			return fmt.Errorf("language %s: invalid environment variable %q", l.Name, kv)
+		}
+	}
+	if l.TimeMultiplier < 0 || l.MemoryMultiplier < 0 {
+		return fmt.Errorf("language %s: multipliers must be positive", l.Name)
+	}
+	if l.TimeMultiplier == 0 {
+		l.TimeMultiplier = 1
+	}
+	if l.MemoryMultiplier == 0 {
+		l.MemoryMultiplier = 1
+	}
+	return nil
+}
+
+// Language returns a language by name, enabled or not. Disabled languages
+// still judge submissions made while they were enabled.
+func (r *Registry) Language(name string) (Language, bool) {
+	l, ok := r.byName[name]
+	return l, ok
+}
+
+// Enabled returns the languages new submissions may use, sorted by name
+func (r *Registry) Enabled() []Language {
+	var enabled []Language
+	for _, l := range r.languages {
+		if l.Enabled {
+			enabled = append(enabled, l)
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,434 @@
+//go:build linux
+
+package judge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// sandboxInitArg is argv[0] of the re-executed binary that sets up a sandbox
+// and runs the sandboxed command as its child. The command cannot be the
+// init process itself: init ignores signals it does not handle, so SIGXCPU
+// or SIGABRT would not end it.
+const sandboxInitArg = "codestandoff-sandbox-init"
+
+// File descriptors the sandbox reads its configuration from, reports setup
+// errors on, reports how the command ended on, and joins the run's cgroup
+// with, if it has one
+const (
+	configFD = 3
+	errorFD  = 4
+	statusFD = 5
+	cgroupFD = 6
+)
+
+const (
+	// rlimitNPROC is missing from package syscall
+	rlimitNPROC = 0x6
+
+	maxFileBytes = 64 << 20
+	maxOpenFiles = 512
+
+	cloneNewCgroup = 0x02000000
+)
+
+const sandboxCloneFlags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+	syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | cloneNewCgroup
+
+// Flags shared by mount(2) and statfs(2)
+const (
+	mountLockedFlags = syscall.MS_RDONLY | syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC
+	stRelatime       = 0x1000
+)
+
+// prctl options and secure bits used to drop privileges
+const (
+	prSetSecurebits     = 28
+	prCapAmbient        = 47
+	prCapAmbientClear   = 4
+	prSetNoNewPrivs     = 38
+	prSetDumpable       = 4
+	secbitNoRoot        = 1 << 0
+	secbitNoRootLocked  = 1 << 1
+	secbitNoFixup       = 1 << 2
+	secbitNoFixupLocked = 1 << 3
+	secbitKeepCapsLock  = 1 << 5
+)
+
+// sandboxConfig is what the sandbox init process needs to build the sandbox
+type sandboxConfig struct {
+	// Root is an empty directory the sandbox root is mounted on
+	Root          string
+	Work          string
+	WorkWritable  bool
+	ReadOnlyPaths []string
+	TmpBytes      int64
+	Args          []string
+	Env           []string
+	Limits        []rlimit
+	// Cgroup says whether cgroupFD is open
+	Cgroup bool
+}
+
+type rlimit struct {
+	Resource int
+	Value    uint64
+}
+
+// sandboxReport is how the command ended and what it used. The rusage of the
+// sandbox init covers the init too, so the init reports the command's own.
+type sandboxReport struct {
+	// Status is the command's wait status
+	Status  uint32
+	CPUTime time.Duration
+	// MemoryBytes is the command's peak resident set size
+	MemoryBytes int64
+	// TmpUsedBytes is what the files left in /tmp take up
+	TmpUsedBytes int64
+}
+
+// devices are bound into the sandbox's /dev
+var devices = []string{"null", "zero", "random", "urandom"}
+
+func init() {
+	if len(os.Args) == 0 || os.Args[0] != sandboxInitArg {
+		return
+	}
+
+	// Privileges are dropped and the seccomp filter installed per thread, so
+	// everything up to starting the command has to happen on this one
+	runtime.LockOSThread()
+	report, err := sandboxInit()
+	if err != nil {
+		fmt.Fprint(os.NewFile(errorFD, "errors"), err)
+		os.Exit(1)
+	}
+	json.NewEncoder(os.NewFile(statusFD, "status")).Encode(report)
+	os.Exit(0)
+}
+
+// sandboxInit runs inside the new namespaces as their root. It builds the
+// sandbox filesystem, applies limits, drops privileges, then runs the
+// command and waits for it.
+func sandboxInit() (*sandboxReport, error) {
+	syscall.CloseOnExec(errorFD)
+	syscall.CloseOnExec(statusFD)
+	syscall.CloseOnExec(cgroupFD)
+	data, err := io.ReadAll(os.NewFile(configFD, "config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	syscall.Close(configFD)
+	var cfg sandboxConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err := buildRoot(&cfg); err != nil {
+		return nil, err
+	}
+	path, err := lookPath(cfg.Args[0], cfg.Env)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Sethostname([]byte("sandbox")); err != nil {
+		return nil, fmt.Errorf("failed to set hostname: %w", err)
+	}
+
+	for _, l := range cfg.Limits {
+		// A hard CPU limit above the soft one makes the kernel send SIGXCPU
+		// before SIGKILL, so the program's death says why
+		hard := l.Value
+		if l.Resource == syscall.RLIMIT_CPU {
+			hard++
+		}
+		if err := syscall.Setrlimit(l.Resource, &syscall.Rlimit{Cur: l.Value, Max: hard}); err != nil {
+			return nil, fmt.Errorf("failed to set rlimit %d: %w", l.Resource, err)
+		}
+	}
+	if cfg.Cgroup {
+		if err := joinCgroup(); err != nil {
+			return nil, err
+		}
+	}
+	if err := dropPrivileges(); err != nil {
+		return nil, err
+	}
+	if err := installSeccomp(); err != nil {
+		return nil, err
+	}
+
+	// The judge stops a run by sending SIGTERM to this process, which then
+	// kills the command and still reports on it
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+
+	// The command inherits limits, privileges and the filter from this thread
+	pid, err := syscall.ForkExec(path, cfg.Args, &syscall.ProcAttr{Env: cfg.Env, Files: []uintptr{0, 1, 2}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", cfg.Args[0], err)
+	}
+	go func() {
+		<-stop
+		syscall.Kill(pid, syscall.SIGKILL)
+	}()
+	var status syscall.WaitStatus
+	var usage syscall.Rusage
+	for {
+		_, err = syscall.Wait4(pid, &status, 0, &usage)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for %s: %w", cfg.Args[0], err)
+	}
+	report := &sandboxReport{
+		Status:      uint32(status),
+		CPUTime:     time.Duration(usage.Utime.Nano() + usage.Stime.Nano()),
+		MemoryBytes: usage.Maxrss * 1024,
+	}
+	var tmp syscall.Statfs_t
+	if err := syscall.Statfs("/tmp", &tmp); err == nil {
+		report.TmpUsedBytes = int64(tmp.Blocks-tmp.Bfree) * int64(tmp.Bsize)
+	}
+	return report, nil
+}
+
+// joinCgroup moves the sandbox init into the run's cgroup, so the command
+// starts in it, and gives the sandbox a cgroup namespace rooted there. What
+// the init allocated before stays charged to the cgroup it came from.
+func joinCgroup() error {
+	procs := os.NewFile(cgroupFD, "cgroup.procs")
+	defer procs.Close()
+	if _, err := procs.WriteString("0"); err != nil {
+		return fmt.Errorf("failed to join cgroup: %w", err)
+	}
+	if err := syscall.Unshare(cloneNewCgroup); err != nil {
+		return fmt.Errorf("failed to create cgroup namespace: %w", err)
+	}
+	return nil
+}
+
+// buildRoot assembles the sandbox filesystem on cfg.Root and makes it the root
+func buildRoot(cfg *sandboxConfig) error {
+	root := cfg.Root
+
+	// Keep every mount below private to this namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	if err := syscall.Mount("tmpfs", root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size=1m,mode=755"); err != nil {
+		return fmt.Errorf("failed to mount root: %w", err)
+	}
+
+	for _, p := range cfg.ReadOnlyPaths {
+		if err := bindHostPath(root, p); err != nil {
+			return err
+		}
+	}
+
+	work := filepath.Join(root, "work")
+	workFlags := uintptr(syscall.MS_NOSUID | syscall.MS_NODEV)
+	if !cfg.WorkWritable {
+		workFlags |= syscall.MS_RDONLY
+	}
+	if err := bind(cfg.Work, work, workFlags, true); err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(root, "tmp")
+	if err := os.Mkdir(tmp, 0o777); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", tmp, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, fmt.Sprintf("size=%d,mode=1777", max(cfg.TmpBytes, 1<<20))); err != nil {
+		return fmt.Errorf("failed to mount /tmp: %w", err)
+	}
+
+	if err := buildDev(root); err != nil {
+		return err
+	}
+
+	// A fresh /proc shows only the sandbox's processes. Some container
+	// runtimes forbid mounting it; runtimes that need it then fail visibly.
+	proc := filepath.Join(root, "proc")
+	if err := os.Mkdir(proc, 0o555); err != nil {
+		return err
+	}
+	syscall.Mount("proc", proc, "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+
+	old := filepath.Join(root, ".old")
+	if err := os.Mkdir(old, 0o700); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(root, old); err != nil {
+		return fmt.Errorf("failed to pivot root: %w", err)
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		return err
+	}
+	if err := syscall.Unmount("/.old", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to detach host root: %w", err)
+	}
+	if err := os.Remove("/.old"); err != nil {
+		return err
+	}
+	if err := syscall.Mount("", "/", "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("failed to make root read-only: %w", err)
+	}
+
+	return syscall.Chdir("/work")
+}
+
+// bindHostPath makes the host path p visible read-only at the same path under root.
+// Symlinks are recreated rather than followed, keeping merged /usr layouts intact.
+func bindHostPath(root, p string) error {
+	info, err := os.Lstat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	target := filepath.Join(root, p)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	}
+	return bind(p, target, syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, info.IsDir())
+}
+
+// bind mounts source at target, creating target as a directory or file, and
+// applies flags. Bind mounts only take flags on a remount, which has to keep
+// the flags the source is locked to when its mount belongs to another namespace.
+func bind(source, target string, flags uintptr, isDir bool) error {
+	if isDir {
+		if err := os.Mkdir(target, 0o755); err != nil && !os.IsExist(err) {
+			return err
+		}
+	} else {
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_RDONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		f.Close()
+	}
+
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("failed to bind %s: %w", source, err)
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(source, &st); err != nil {
+		return err
+	}
+	flags |= uintptr(st.Flags)&mountLockedFlags | syscall.MS_BIND | syscall.MS_REMOUNT
+	if st.Flags&stRelatime != 0 {
+		flags |= syscall.MS_RELATIME
+	}
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("failed to remount %s: %w", source, err)
+	}
+	return nil
+}
+
+// buildDev creates a minimal /dev holding only harmless devices
+func buildDev(root string) error {
+	dev := filepath.Join(root, "dev")
+	if err := os.Mkdir(dev, 0o755); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", dev, "tmpfs", syscall.MS_NOSUID|syscall.MS_NOEXEC, "size=64k,mode=755"); err != nil {
+		return fmt.Errorf("failed to mount /dev: %w", err)
+	}
+	for _, name := range devices {
+		if err := bind(filepath.Join("/dev", name), filepath.Join(dev, name), syscall.MS_NOSUID|syscall.MS_NOEXEC, false); err != nil {
+			return err
+		}
+	}
+	for name, target := range map[string]string{
+		"fd":     "/proc/self/fd",
+		"stdin":  "/proc/self/fd/0",
+		"stdout": "/proc/self/fd/1",
+		"stderr": "/proc/self/fd/2",
+	} {
+		if err := os.Symlink(target, filepath.Join(dev, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropPrivileges leaves the sandbox root without capabilities, now and after
+// exec. It stays user 0 inside its namespace, which maps to an unprivileged
+// host user. The init process also becomes undumpable, so the command cannot
+// reach into it through /proc.
+func dropPrivileges() error {
+	if err := prctl(prSetDumpable, 0, 0); err != nil {
+		return fmt.Errorf("failed to make init undumpable: %w", err)
+	}
+	bits := secbitNoRoot | secbitNoRootLocked | secbitNoFixup | secbitNoFixupLocked | secbitKeepCapsLock
+	if err := prctl(prSetSecurebits, uintptr(bits), 0); err != nil {
+		return fmt.Errorf("failed to set securebits: %w", err)
+	}
+	if err := prctl(prCapAmbient, prCapAmbientClear, 0); err != nil && err != syscall.EINVAL {
+		return fmt.Errorf("failed to clear ambient capabilities: %w", err)
+	}
+	for c := uintptr(0); ; c++ {
+		err := prctl(syscall.PR_CAPBSET_DROP, c, 0)
+		if err == syscall.EINVAL {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to drop capability %d: %w", c, err)
+		}
+	}
+	if err := prctl(prSetNoNewPrivs, 1, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+	return nil
+}
+
+func prctl(option, arg2, arg3 uintptr) error {
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, option, arg2, arg3, 0, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// lookPath finds name in the PATH of env, as the shell would
+func lookPath(name string, env []string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	for _, kv := range env {
+		dirs, ok := strings.CutPrefix(kv, "PATH=")
+		if !ok {
+			continue
+		}
+		for _, dir := range filepath.SplitList(dirs) {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+				return path, nil
+			}
+		}
+	}
+	return "", errors.New(name + ": command not found")
+}
@@ -0,0 +1,91 @@
+//go:build linux
+
+package judge
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// seccomp and BPF constants missing from package syscall
+const (
+	prSetSeccomp       = 22
+	seccompModeFilter  = 2
+	seccompRetKill     = 0x80000000 // SECCOMP_RET_KILL_PROCESS
+	seccompRetErrno    = 0x00050000
+	seccompRetAllow    = 0x7fff0000
+	seccompDataNr      = 0
+	seccompDataArch    = 4
+	seccompDataArgs    = 16
+	afUnix             = 1
+	namespaceCloneMask = syscall.CLONE_NEWNS | cloneNewCgroup | syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC |
+		syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET
+)
+
+// seccompFilter builds the filter for sandboxed programs. Other architectures
+// and ABIs kill the process; deniedSyscalls, sockets other than Unix sockets
+// and clones into new namespaces fail with EPERM; clone3, whose flags live
+// in memory a filter cannot read, fails with ENOSYS so that libc falls back
+// to clone. Everything else is allowed.
+func seccompFilter() []syscall.SockFilter {
+	ret := func(k uint32) syscall.SockFilter {
+		return syscall.SockFilter{Code: syscall.BPF_RET | syscall.BPF_K, K: k}
+	}
+	load := func(offset uint32) syscall.SockFilter {
+		return syscall.SockFilter{Code: syscall.BPF_LD | syscall.BPF_W | syscall.BPF_ABS, K: offset}
+	}
+	jump := func(op uint16, k uint32, jt, jf uint8) syscall.SockFilter {
+		return syscall.SockFilter{Code: syscall.BPF_JMP | op | syscall.BPF_K, K: k, Jt: jt, Jf: jf}
+	}
+	deny := ret(seccompRetErrno | uint32(syscall.EPERM))
+	allow := ret(seccompRetAllow)
+
+	filter := []syscall.SockFilter{
+		load(seccompDataArch),
+		jump(syscall.BPF_JEQ, seccompArch, 1, 0),
+		ret(seccompRetKill),
+		load(seccompDataNr),
+	}
+	if x32SyscallBit != 0 {
+		filter = append(filter,
+			jump(syscall.BPF_JGE, x32SyscallBit, 0, 1),
+			ret(seccompRetKill),
+		)
+	}
+	for _, nr := range deniedSyscalls {
+		filter = append(filter, jump(syscall.BPF_JEQ, nr, 0, 1), deny)
+	}
+	// Arguments are 64 bits wide; the low half comes first on little-endian machines
+	filter = append(filter,
+		jump(syscall.BPF_JEQ, sysSocket, 0, 4),
+		load(seccompDataArgs),
+		jump(syscall.BPF_JEQ, afUnix, 0, 1),
+		allow,
+		deny,
+
+		jump(syscall.BPF_JEQ, sysClone, 0, 4),
+		load(seccompDataArgs),
+		jump(syscall.BPF_JSET, namespaceCloneMask, 0, 1),
+		deny,
+		allow,
+
+		jump(syscall.BPF_JEQ, sysClone3, 0, 1),
+		ret(seccompRetErrno|uint32(syscall.ENOSYS)),
+
+		allow,
+	)
+	return filter
+}
+
+// installSeccomp installs the filter on the calling thread, which keeps it
+// across exec. no_new_privs must already be set.
+func installSeccomp() error {
+	filter := seccompFilter()
+	prog := syscall.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(&prog)))
+	if errno != 0 {
+		return fmt.Errorf("failed to install seccomp filter: %w", errno)
+	}
+	return nil
+}
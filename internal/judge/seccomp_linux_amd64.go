@@ -0,0 +1,77 @@
+//go:build linux && amd64
+
+package judge
+
+const (
+	seccompArch   = 0xc000003e // AUDIT_ARCH_X86_64
+	x32SyscallBit = 0x40000000
+
+	sysSocket = 41
+	sysClone  = 56
+	sysClone3 = 435
+)
+
+// deniedSyscalls reach outside the sandbox, change the machine or the
+// sandbox itself, or expose kernel attack surface programs do not need
+var deniedSyscalls = []uint32{
+	101, // ptrace
+	103, // syslog
+	133, // mknod
+	134, // uselib
+	135, // personality
+	153, // vhangup
+	154, // modify_ldt
+	155, // pivot_root
+	156, // _sysctl
+	159, // adjtimex
+	161, // chroot
+	163, // acct
+	164, // settimeofday
+	165, // mount
+	166, // umount2
+	167, // swapon
+	168, // swapoff
+	169, // reboot
+	170, // sethostname
+	171, // setdomainname
+	172, // iopl
+	173, // ioperm
+	175, // init_module
+	176, // delete_module
+	179, // quotactl
+	180, // nfsservctl
+	212, // lookup_dcookie
+	227, // clock_settime
+	246, // kexec_load
+	248, // add_key
+	249, // request_key
+	250, // keyctl
+	256, // migrate_pages
+	259, // mknodat
+	272, // unshare
+	279, // move_pages
+	298, // perf_event_open
+	300, // fanotify_init
+	303, // name_to_handle_at
+	304, // open_by_handle_at
+	305, // clock_adjtime
+	308, // setns
+	310, // process_vm_readv
+	311, // process_vm_writev
+	312, // kcmp
+	313, // finit_module
+	320, // kexec_file_load
+	321, // bpf
+	323, // userfaultfd
+	425, // io_uring_setup
+	426, // io_uring_enter
+	427, // io_uring_register
+	428, // open_tree
+	429, // move_mount
+	430, // fsopen
+	431, // fsconfig
+	432, // fsmount
+	433, // fspick
+	438, // pidfd_getfd
+	442, // mount_setattr
+}
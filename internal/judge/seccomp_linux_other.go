@@ -0,0 +1,15 @@
+//go:build linux && !amd64 && !arm64
+
+package judge
+
+// The local judge has no seccomp filter for this architecture; NewLocal refuses to start
+const (
+	seccompArch   = 0
+	x32SyscallBit = 0
+
+	sysSocket = 0
+	sysClone  = 0
+	sysClone3 = 0
+)
+
+var deniedSyscalls []uint32
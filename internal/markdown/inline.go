@@ -0,0 +1,447 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Bounds on inline content
+const (
+	// maxInlineNesting is how deeply emphasis and links nest
+	maxInlineNesting = 32
+	// maxDestination is the longest link destination or title
+	maxDestination = 2048
+)
+
+var (
+	entityRef = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	autolink  = regexp.MustCompile(`^<((?i:https?|mailto):[^\s<>]+)>`)
+	scheme    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+)
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+func writeEscaped(b *strings.Builder, s string) {
+	escaper.WriteString(b, s)
+}
+
+// inline renders the inline content of a block
+func (r *renderer) inline(s string) {
+	r.spans(s, 0, false)
+}
+
+// spanState remembers what scanning one run of inline text found, so that
+// unclosed delimiters cannot make rendering quadratic
+type spanState struct {
+	// noCloser maps a delimiter run to the earliest position with no closer after it
+	noCloser map[string]int
+	// brackets maps the position of each '[' to its matching ']'
+	brackets map[int]int
+}
+
+func newSpanState(s string) *spanState {
+	st := &spanState{noCloser: map[string]int{}, brackets: map[int]int{}}
+	var open []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			open = append(open, i)
+		case ']':
+			if len(open) > 0 {
+				st.brackets[open[len(open)-1]] = i
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return st
+}
+
+func (r *renderer) spans(s string, depth int, inLink bool) {
+	st := newSpanState(s)
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				r.b.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && isPunct(s[i+1]) {
+				writeEscaped(&r.b, s[i+1:i+2])
+				i += 2
+				continue
+			}
+		case '`':
+			i += r.codeSpan(s, i)
+			continue
+		case '$':
+			if n := r.inlineMath(s, i); n > 0 {
+				i += n
+				continue
+			}
+		case '*', '_', '~':
+			if depth < maxInlineNesting {
+				i += r.emphasis(s, i, st, depth, inLink)
+				continue
+			}
+		case '!':
+			if !inLink && depth < maxInlineNesting && i+1 < len(s) && s[i+1] == '[' {
+				if n := r.link(s, i+1, st, depth, true); n > 0 {
+					i += n + 1
+					continue
+				}
+			}
+		case '[':
+			if !inLink && depth < maxInlineNesting {
+				if n := r.link(s, i, st, depth, false); n > 0 {
+					i += n
+					continue
+				}
+			}
+		case '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil && !inLink {
+				if url, ok := safeURL(m[1], false); ok {
+					r.b.WriteString(`<a href="`)
+					writeEscaped(&r.b, url)
+					r.b.WriteString(`" rel="nofollow noopener noreferrer">`)
+					writeEscaped(&r.b, m[1])
+					r.b.WriteString("</a>")
+					i += len(m[0])
+					continue
+				}
+			}
+		case '&':
+			// Character references are text once parsed, so they can pass through
+			if m := entityRef.FindString(s[i:]); m != "" {
+				r.b.WriteString(m)
+				i += len(m)
+				continue
+			}
+		case ' ':
+			// Two or more spaces at the end of a line make a hard line break
+			j := i
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j < len(s) && s[j] == '\n' {
+				if j-i >= 2 {
+					r.b.WriteString("<br>")
+				}
+				i = j
+				continue
+			}
+		}
+		writeEscaped(&r.b, s[i:i+1])
+		i++
+	}
+}
+
+// codeSpan renders the code span opening at s[i], or the backticks as text if
+// it is not closed, and returns the number of bytes consumed
+func (r *renderer) codeSpan(s string, i int) int {
+	n := runLength(s, i)
+	for j := i + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(s, j)
+		if m == n {
+			code := strings.ReplaceAll(s[i+n:j], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			r.b.WriteString("<code>")
+			writeEscaped(&r.b, code)
+			r.b.WriteString("</code>")
+			return j + m - i
+		}
+		j += m
+	}
+
+	r.b.WriteString(s[i : i+n])
+	return n
+}
+
+// inlineMath renders $...$ or $$...$$ at s[i] and returns the number of bytes
+// consumed, or 0 if s[i] is a literal dollar sign. Like pandoc, the opening $
+// must be followed and the closing $ preceded by a non-space, and the closing
+// $ must not be followed by a digit, so "$5 and $10" stays text.
+func (r *renderer) inlineMath(s string, i int) int {
+	if strings.HasPrefix(s[i:], "$$") {
+		end := strings.Index(s[i+2:], "$$")
+		if end <= 0 {
+			r.b.WriteString("$$")
+			return 2
+		}
+		r.math(s[i+2:i+2+end], true)
+		return end + 4
+	}
+
+	if i+1 >= len(s) || isSpace(s[i+1]) {
+		return 0
+	}
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '$':
+			if isSpace(s[j-1]) || j+1 < len(s) && isDigit(s[j+1]) {
+				continue
+			}
+			r.math(s[i+1:j], false)
+			return j + 1 - i
+		}
+	}
+	return 0
+}
+
+// emphasis renders the emphasis, strong emphasis or strikethrough opening at
+// s[i], or the delimiters as text if it is not closed, and returns the number
+// of bytes consumed
+func (r *renderer) emphasis(s string, i int, st *spanState, depth int, inLink bool) int {
+	c := s[i]
+	n := runLength(s, i)
+
+	literal := func() int {
+		writeEscaped(&r.b, s[i:i+n])
+		return n
+	}
+	if n > 3 || c == '~' && n != 2 {
+		return literal()
+	}
+	// The opening run must be followed by text, and underscores cannot open inside a word
+	if i+n >= len(s) || isSpace(s[i+n]) || c == '_' && i > 0 && isAlnum(s[i-1]) {
+		return literal()
+	}
+
+	run := s[i : i+n]
+	if from, ok := st.noCloser[run]; ok && i+n >= from {
+		return literal()
+	}
+	end := closingRun(s, i+n, c, n)
+	if end < 0 {
+		st.noCloser[run] = i + n
+		return literal()
+	}
+
+	var open, close string
+	switch {
+	case c == '~':
+		open, close = "<del>", "</del>"
+	case n == 1:
+		open, close = "<em>", "</em>"
+	case n == 2:
+		open, close = "<strong>", "</strong>"
+	default:
+		open, close = "<em><strong>", "</strong></em>"
+	}
+	r.b.WriteString(open)
+	r.spans(s[i+n:end], depth+1, inLink)
+	r.b.WriteString(close)
+	return end + n - i
+}
+
+// closingRun finds a run of exactly n c's from s[from] that can close emphasis,
+// skipping escapes and code spans, or returns -1
+func closingRun(s string, from int, c byte, n int) int {
+	for j := from; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			k := runLength(s, j)
+			if end := strings.Index(s[j+k:], s[j:j+k]); end >= 0 {
+				j += k + end + k
+			} else {
+				j += k
+			}
+			continue
+		case c:
+			m := runLength(s, j)
+			if m == n && j > from && !isSpace(s[j-1]) && (c != '_' || j+m >= len(s) || !isAlnum(s[j+m])) {
+				return j
+			}
+			j += m
+			continue
+		}
+		j++
+	}
+	return -1
+}
+
+// link renders the link or image whose label opens at s[i] and returns the
+// number of bytes consumed, or 0 if there is no link there. Links whose URL is
+// not allowed are rendered as their text.
+func (r *renderer) link(s string, i int, st *spanState, depth int, image bool) int {
+	// The label may contain balanced brackets
+	labelEnd, ok := st.brackets[i]
+	if !ok || labelEnd+1 >= len(s) || s[labelEnd+1] != '(' {
+		return 0
+	}
+	label := s[i+1 : labelEnd]
+
+	// Destination, optionally in angle brackets, then an optional quoted title
+	j := skipSpaces(s, labelEnd+2)
+	var dest string
+	if j < len(s) && s[j] == '<' {
+		end := strings.IndexAny(s[j+1:min(len(s), j+1+maxDestination)], ">\n")
+		if end < 0 || s[j+1+end] != '>' {
+			return 0
+		}
+		dest = s[j+1 : j+1+end]
+		j += end + 2
+	} else {
+		start, parens := j, 0
+		for ; j < len(s) && j-start <= maxDestination && !isSpace(s[j]); j++ {
+			if s[j] == '\\' {
+				j++
+				continue
+			}
+			if s[j] == '(' {
+				parens++
+			} else if s[j] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		if j > len(s) || j-start > maxDestination {
+			return 0
+		}
+		dest = s[start:j]
+	}
+
+	j = skipSpaces(s, j)
+	var title string
+	hasTitle := false
+	if j < len(s) && (s[j] == '"' || s[j] == '\'') {
+		quote, end := s[j], j+1
+		for end < len(s) && end-j <= maxDestination && s[end] != quote {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) || s[end] != quote {
+			return 0
+		}
+		title, hasTitle = s[j+1:end], true
+		j = skipSpaces(s, end+1)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return 0
+	}
+	consumed := j + 1 - i
+
+	url, ok := safeURL(unescape(dest), image)
+	switch {
+	case !ok && image:
+		writeEscaped(&r.b, label)
+	case !ok:
+		r.spans(label, depth+1, true)
+	case image:
+		r.b.WriteString(`<img src="`)
+		writeEscaped(&r.b, url)
+		r.b.WriteString(`" alt="`)
+		writeEscaped(&r.b, unescape(label))
+		r.b.WriteString(`"`)
+		if hasTitle {
+			r.b.WriteString(` title="`)
+			writeEscaped(&r.b, unescape(title))
+			r.b.WriteString(`"`)
+		}
+		r.b.WriteString(` loading="lazy">`)
+	default:
+		r.b.WriteString(`<a href="`)
+		writeEscaped(&r.b, url)
+		r.b.WriteString(`"`)
+		if hasTitle {
+			r.b.WriteString(` title="`)
+			writeEscaped(&r.b, unescape(title))
+			r.b.WriteString(`"`)
+		}
+		r.b.WriteString(` rel="nofollow noopener noreferrer">`)
+		r.spans(label, depth+1, true)
+		r.b.WriteString("</a>")
+	}
+	return consumed
+}
+
+// safeURL returns url if it is relative or uses an allowed scheme. Images
+// may only use http and https, so they cannot embed data or scripts.
+func safeURL(url string, image bool) (string, bool) {
+	url = strings.TrimSpace(url)
+	for _, c := range url {
+		if c < 0x20 || c == 0x7f {
+			return "", false
+		}
+	}
+
+	m := scheme.FindStringSubmatch(url)
+	if m == nil {
+		// Without a scheme before any '/', '?' or '#' the URL is relative,
+		// but a colon there would make browsers read a scheme anyway
+		if i := strings.IndexAny(url, ":/?#"); i >= 0 && url[i] == ':' {
+			return "", false
+		}
+		return url, true
+	}
+	switch strings.ToLower(m[1]) {
+	case "http", "https":
+		return url, true
+	case "mailto":
+		return url, !image
+	}
+	return "", false
+}
+
+// unescape removes backslash escapes from link destinations and titles
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
@@ -0,0 +1,485 @@
+// Package markdown renders problem statements, written in markdown with TeX
+// math, to HTML that is safe to insert into any page.
+//
+// The supported syntax is CommonMark's headings, paragraphs, emphasis, code
+// spans, fenced and indented code blocks, block quotes, lists, thematic breaks,
+// links and images, plus GitHub-style tables and strikethrough. Math between
+// $...$ is rendered inline and between $$...$$ as a block, as MathML.
+//
+// Output is sanitized by construction: raw HTML in the source is escaped
+// rather than passed through, every attribute value is escaped, and link and
+// image URLs are restricted to http, https, mailto and relative ones.
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version identifies the renderer's output. Bump it whenever the output for
+// the same source changes, so cached HTML is rendered again.
+const Version = 1
+
+// maxNesting bounds how deeply block quotes and lists nest; deeper content is
+// rendered as text
+const maxNesting = 16
+
+var (
+	headingLine   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceLine     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	listItemLine  = regexp.MustCompile(`^( {0,3})([-*+]|[0-9]{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	setextLine    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	tableDelim    = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	languageName  = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,32}$`)
+)
+
+// Render converts markdown to sanitized HTML
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	src = strings.ToValidUTF8(strings.ReplaceAll(src, "\x00", "\uFFFD"), "\uFFFD")
+
+	r := &renderer{}
+	r.blocks(strings.Split(src, "\n"), 0, false)
+	return r.b.String()
+}
+
+type renderer struct {
+	b strings.Builder
+}
+
+// blocks renders a sequence of lines as block elements. Paragraphs in tight
+// list items are written without <p> tags.
+func (r *renderer) blocks(lines []string, depth int, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		switch {
+		case indentOf(line) >= 4:
+			i = r.indentedCode(lines, i)
+		case fenceLine.MatchString(line):
+			i = r.fencedCode(lines, i)
+		case strings.HasPrefix(strings.TrimSpace(line), "$$"):
+			i = r.displayMath(lines, i)
+		case headingLine.MatchString(line):
+			m := headingLine.FindStringSubmatch(line)
+			level := len(m[1])
+			fmt.Fprintf(&r.b, "<h%d>", level)
+			r.inline(strings.TrimSpace(m[2]))
+			fmt.Fprintf(&r.b, "</h%d>\n", level)
+			i++
+		case thematicBreak.MatchString(line):
+			r.b.WriteString("<hr>\n")
+			i++
+		case depth < maxNesting && isBlockQuote(line):
+			i = r.blockQuote(lines, i, depth)
+		case depth < maxNesting && listItemLine.MatchString(line):
+			i = r.list(lines, i, depth)
+		case isTableStart(lines, i):
+			i = r.table(lines, i)
+		default:
+			i = r.paragraph(lines, i, tight)
+		}
+	}
+}
+
+func (r *renderer) indentedCode(lines []string, i int) int {
+	var code []string
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+	}
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+
+	r.b.WriteString("<pre><code>")
+	writeEscaped(&r.b, strings.Join(code, "\n")+"\n")
+	r.b.WriteString("</code></pre>\n")
+	return i
+}
+
+func (r *renderer) fencedCode(lines []string, i int) int {
+	m := fenceLine.FindStringSubmatch(lines[i])
+	indent, fence, info := len(m[1]), m[2], m[3]
+	if fence[0] == '~' {
+		// Unlike backtick fences, tilde fences may have backticks in their info string
+		info = strings.TrimSpace(strings.TrimLeft(lines[i], " ~"))
+	}
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" && indentOf(lines[i]) < 4 {
+			i++
+			break
+		}
+		code = append(code, stripIndent(lines[i], indent))
+	}
+
+	r.b.WriteString("<pre><code")
+	if fields := strings.Fields(info); len(fields) > 0 && languageName.MatchString(fields[0]) {
+		r.b.WriteString(` class="language-`)
+		writeEscaped(&r.b, fields[0])
+		r.b.WriteString(`"`)
+	}
+	r.b.WriteString(">")
+	if len(code) > 0 {
+		writeEscaped(&r.b, strings.Join(code, "\n")+"\n")
+	}
+	r.b.WriteString("</code></pre>\n")
+	return i
+}
+
+// displayMath renders a $$...$$ block, which may span several lines
+func (r *renderer) displayMath(lines []string, i int) int {
+	first := strings.TrimSpace(lines[i])
+	tex := strings.TrimPrefix(first, "$$")
+	if end := strings.Index(tex, "$$"); end >= 0 {
+		if strings.TrimSpace(tex[end+2:]) != "" {
+			// Text after the closing $$ makes this a paragraph with math in it
+			return r.paragraph(lines, i, false)
+		}
+		r.math(tex[:end], true)
+		r.b.WriteString("\n")
+		return i + 1
+	}
+
+	body := []string{tex}
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if end := strings.Index(trimmed, "$$"); end >= 0 {
+			if strings.TrimSpace(trimmed[end+2:]) != "" {
+				break
+			}
+			body = append(body, trimmed[:end])
+			r.math(strings.Join(body, "\n"), true)
+			r.b.WriteString("\n")
+			return j + 1
+		}
+		body = append(body, lines[j])
+	}
+
+	// Unterminated; the dollars are text
+	return r.paragraph(lines, i, false)
+}
+
+func isBlockQuote(line string) bool {
+	return indentOf(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func (r *renderer) blockQuote(lines []string, i, depth int) int {
+	var inner []string
+	for ; i < len(lines) && isBlockQuote(lines[i]); i++ {
+		line := strings.TrimPrefix(strings.TrimLeft(lines[i], " "), ">")
+		inner = append(inner, strings.TrimPrefix(line, " "))
+	}
+
+	r.b.WriteString("<blockquote>\n")
+	r.blocks(inner, depth+1, false)
+	r.b.WriteString("</blockquote>\n")
+	return i
+}
+
+// listItem is the content lines of a list item, with the marker and indentation removed
+type listItem struct {
+	lines []string
+}
+
+func (r *renderer) list(lines []string, i, depth int) int {
+	m := listItemLine.FindStringSubmatch(lines[i])
+	marker := m[2]
+	ordered := marker[0] >= '0' && marker[0] <= '9'
+	delimiter := marker[len(marker)-1]
+
+	var items []listItem
+	loose := false
+	for i < len(lines) {
+		m := listItemLine.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		if !sameList(lines[i], ordered, delimiter) {
+			break
+		}
+		itemMarker := m[2]
+
+		// Content lines of the item are indented past its marker
+		width := len(m[1]) + len(itemMarker) + 1
+		if len(m[3]) > 1 && len(m[3]) <= 4 {
+			width = len(m[1]) + len(itemMarker) + len(m[3])
+		}
+		item := listItem{lines: []string{m[4]}}
+		if len(m[3]) > 4 {
+			// Content starting with more spaces is an indented code block
+			item.lines[0] = strings.Repeat(" ", len(m[3])-1) + m[4]
+		}
+
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line continues the item only if indented content follows
+				j := i
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j == len(lines) || indentOf(lines[j]) < width {
+					break
+				}
+				item.lines = append(item.lines, "")
+				continue
+			}
+			if indentOf(line) >= width {
+				item.lines = append(item.lines, line[width:])
+				continue
+			}
+			// Lazy continuation of the item's paragraph
+			if !startsBlock(line) && strings.TrimSpace(item.lines[len(item.lines)-1]) != "" {
+				item.lines = append(item.lines, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+		if containsBlank(item.lines) {
+			loose = true
+		}
+		items = append(items, item)
+
+		// Blank lines between items make the list loose
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		if j > i {
+			if j == len(lines) || !sameList(lines[j], ordered, delimiter) {
+				break
+			}
+			loose = true
+		}
+		i = j
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		start := strings.TrimLeft(marker[:len(marker)-1], "0")
+		if start != "1" {
+			if start == "" {
+				start = "0"
+			}
+			fmt.Fprintf(&r.b, "<ol start=\"%s\">\n", start)
+		} else {
+			r.b.WriteString("<ol>\n")
+		}
+	} else {
+		r.b.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		r.b.WriteString("<li>")
+		if loose {
+			r.b.WriteString("\n")
+		}
+		r.blocks(item.lines, depth+1, !loose)
+		r.b.WriteString("</li>\n")
+	}
+	fmt.Fprintf(&r.b, "</%s>\n", tag)
+	return i
+}
+
+// sameList reports whether line is an item of a list of the given kind
+func sameList(line string, ordered bool, delimiter byte) bool {
+	m := listItemLine.FindStringSubmatch(line)
+	if m == nil || thematicBreak.MatchString(line) {
+		return false
+	}
+	marker := m[2]
+	return (marker[0] >= '0' && marker[0] <= '9') == ordered && marker[len(marker)-1] == delimiter
+}
+
+func (r *renderer) table(lines []string, i int) int {
+	header := splitRow(lines[i])
+	var align []string
+	for _, cell := range splitRow(lines[i+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			align = append(align, "center")
+		case right:
+			align = append(align, "right")
+		case left:
+			align = append(align, "left")
+		default:
+			align = append(align, "")
+		}
+	}
+
+	row := func(cells []string, tag string) {
+		r.b.WriteString("<tr>")
+		for c := range header {
+			r.b.WriteString("<" + tag)
+			if c < len(align) && align[c] != "" {
+				r.b.WriteString(` align="` + align[c] + `"`)
+			}
+			r.b.WriteString(">")
+			if c < len(cells) {
+				r.inline(cells[c])
+			}
+			r.b.WriteString("</" + tag + ">")
+		}
+		r.b.WriteString("</tr>\n")
+	}
+
+	r.b.WriteString("<table>\n<thead>\n")
+	row(header, "th")
+	r.b.WriteString("</thead>\n")
+
+	i += 2
+	if i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]) {
+		r.b.WriteString("<tbody>\n")
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]); i++ {
+			row(splitRow(lines[i]), "td")
+		}
+		r.b.WriteString("</tbody>\n")
+	}
+	r.b.WriteString("</table>\n")
+	return i
+}
+
+// isTableStart reports whether lines[i] is a table header followed by a delimiter row with as many cells
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		tableDelim.MatchString(lines[i+1]) &&
+		len(splitRow(lines[i])) == len(splitRow(lines[i+1]))
+}
+
+// splitRow splits a table row into its trimmed cells. Pipes escaped with a
+// backslash or inside code spans do not separate cells.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+			continue
+		case c == '`':
+			inCode = !inCode
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(c)
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func (r *renderer) paragraph(lines []string, i int, tight bool) int {
+	text := []string{strings.TrimLeft(lines[i], " ")}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		if m := setextLine.FindStringSubmatch(line); m != nil {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			fmt.Fprintf(&r.b, "<h%d>", level)
+			r.inline(strings.TrimSpace(strings.Join(text, "\n")))
+			fmt.Fprintf(&r.b, "</h%d>\n", level)
+			return i + 1
+		}
+		if startsBlock(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+
+	content := strings.TrimRight(strings.Join(text, "\n"), " ")
+	if tight {
+		r.inline(content)
+		if hasContent(lines[i:]) {
+			r.b.WriteString("\n")
+		}
+		return i
+	}
+	r.b.WriteString("<p>")
+	r.inline(content)
+	r.b.WriteString("</p>\n")
+	return i
+}
+
+// startsBlock reports whether line interrupts a paragraph
+func startsBlock(line string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	return fenceLine.MatchString(line) ||
+		headingLine.MatchString(line) ||
+		thematicBreak.MatchString(line) ||
+		isBlockQuote(line) ||
+		listItemLine.MatchString(line) && strings.TrimSpace(line) != "" && !emptyListItem(line) ||
+		strings.HasPrefix(strings.TrimSpace(line), "$$")
+}
+
+// emptyListItem reports whether line is a bare list marker, which cannot interrupt a paragraph
+func emptyListItem(line string) bool {
+	m := listItemLine.FindStringSubmatch(line)
+	return m != nil && strings.TrimSpace(m[4]) == ""
+}
+
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBlank(lines []string) bool {
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" && i < len(lines)-1 {
+			return true
+		}
+	}
+	return false
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// stripIndent removes up to n leading spaces
+func stripIndent(line string, n int) string {
+	for n > 0 && strings.HasPrefix(line, " ") {
+		line = line[1:]
+		n--
+	}
+	return line
+}
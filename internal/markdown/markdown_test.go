@@ -0,0 +1,227 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Tags and attributes the renderer may write. Anything else in its output
+// came from the source.
+var (
+	allowedTags = map[string]bool{
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"p": true, "hr": true, "br": true, "pre": true, "code": true, "blockquote": true,
+		"ul": true, "ol": true, "li": true, "table": true, "thead": true, "tbody": true,
+		"tr": true, "th": true, "td": true, "em": true, "strong": true, "del": true, "a": true, "img": true,
+		"math": true, "semantics": true, "annotation": true, "mrow": true, "mi": true, "mn": true, "mo": true,
+		"mtext": true, "mspace": true, "msub": true, "msup": true, "msubsup": true, "mfrac": true,
+		"msqrt": true, "mroot": true, "mover": true, "mtable": true, "mtr": true, "mtd": true,
+	}
+	allowedAttributes = map[string]bool{
+		"class": true, "start": true, "align": true, "href": true, "title": true, "rel": true,
+		"src": true, "alt": true, "loading": true, "display": true, "encoding": true, "width": true,
+		"linethickness": true, "accent": true, "mathvariant": true, "columnalign": true,
+	}
+	voidTags = map[string]bool{"hr": true, "br": true, "img": true}
+
+	tagPattern       = regexp.MustCompile(`^<(/?)([a-z][a-z0-9]*)((?:[ \n]+[a-z-]+="[^"<>]*")*)>`)
+	attributePattern = regexp.MustCompile(`([a-z-]+)="([^"]*)"`)
+)
+
+// checkSafe fails the test unless out is well-formed HTML made of allowed
+// tags and attributes, with only safe link and image URLs, and returns how
+// deeply its elements nest
+func checkSafe(t *testing.T, out string) int {
+	t.Helper()
+	var open []string
+	depth := 0
+	for i := 0; i < len(out); i++ {
+		if out[i] == '>' {
+			t.Fatalf("unescaped '>' at %d in %q", i, out)
+		}
+		if out[i] != '<' {
+			continue
+		}
+		m := tagPattern.FindStringSubmatch(out[i:])
+		if m == nil {
+			t.Fatalf("unescaped '<' at %d in %q", i, out)
+		}
+		closing, name, attrs := m[1] == "/", m[2], m[3]
+		if !allowedTags[name] {
+			t.Fatalf("tag <%s> in %q", name, out)
+		}
+		switch {
+		case closing:
+			if len(open) == 0 || open[len(open)-1] != name {
+				t.Fatalf("unbalanced </%s> in %q", name, out)
+			}
+			open = open[:len(open)-1]
+		case !voidTags[name]:
+			open = append(open, name)
+			depth = max(depth, len(open))
+		}
+		for _, a := range attributePattern.FindAllStringSubmatch(attrs, -1) {
+			if !allowedAttributes[a[1]] {
+				t.Fatalf("attribute %s in %q", a[1], out)
+			}
+			if a[1] == "href" || a[1] == "src" {
+				if _, ok := safeURL(html.UnescapeString(a[2]), a[1] == "src"); !ok {
+					t.Fatalf("unsafe %s %q in %q", a[1], a[2], out)
+				}
+			}
+		}
+		i += len(m[0]) - 1
+	}
+	if len(open) > 0 {
+		t.Fatalf("unclosed <%s> in %q", open[len(open)-1], out)
+	}
+	return depth
+}
+
+func TestRenderLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want is a substring of the output; wantNot must not appear in it
+		want, wantNot string
+	}{
+		{"javascript link", "[x](javascript:alert(1))", "<p>x</p>", "<a"},
+		{"mixed-case scheme", "[x](JaVaScRiPt:alert(1))", "<p>x</p>", "<a"},
+		{"scheme after spaces", "[x](   javascript:alert(1))", "<p>x</p>", "<a"},
+		{"vbscript link", "[x](vbscript:msgbox(1))", "<p>x</p>", "<a"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>", "<a"},
+		{"percent-encoded scheme", "[x](%6Aavascript:alert(1))", "<p>x</p>", "<a"},
+		{"control character in scheme", "[x](<java\x01script:alert(1)>)", "<p>x</p>", "<a"},
+		{"angle bracket destination", "[x](<javascript:alert(1)>)", "<p>x</p>", "<a"},
+		{"javascript autolink", "<javascript:alert(1)>", "&lt;javascript:alert(1)&gt;", "<a"},
+		{"javascript image", "![x](javascript:alert(1))", "<p>x</p>", "<img"},
+		{"data image", "![x](data:image/svg+xml;base64,PHN2Zz4=)", "<p>x</p>", "<img"},
+		{"mixed-case data image", "![x](DaTa:image/png;base64,AAAA)", "<p>x</p>", "<img"},
+		{"mailto image", "![x](mailto:a@example.com)", "<p>x</p>", "<img"},
+		{"https link", "[x](https://example.com/a?b=1)", `<a href="https://example.com/a?b=1" rel="nofollow noopener noreferrer">x</a>`, ""},
+		{"mixed-case https link", "[x](HTTPS://example.com)", `href="HTTPS://example.com"`, ""},
+		{"relative link", "[x](/problems/two-sum)", `href="/problems/two-sum"`, ""},
+		{"mailto link", "[x](mailto:a@example.com)", `href="mailto:a@example.com"`, ""},
+		{"https image", "![a \"b\"](https://example.com/i.png)", `<img src="https://example.com/i.png" alt="a &quot;b&quot;" loading="lazy">`, ""},
+		{"quote in destination", `[x](https://example.com/"onmouseover="alert(1))`, `href="https://example.com/&quot;onmouseover=&quot;alert(1)"`, ""},
+		{"entity in scheme", "[x](java&#115;cript:alert(1))", `href="java&amp;#115;cript:alert(1)"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Render(tt.src)
+			checkSafe(t, out)
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("Render(%q) = %q, want it to contain %q", tt.src, out, tt.want)
+			}
+			if tt.wantNot != "" && strings.Contains(out, tt.wantNot) {
+				t.Errorf("Render(%q) = %q, want no %q", tt.src, out, tt.wantNot)
+			}
+		})
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"attribute injection", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&quot;alert(1)&quot;&gt;</p>\n"},
+		{"html block", "<div>\n<script>alert(1)</script>\n</div>", "<p>&lt;div&gt;\n&lt;script&gt;alert(1)&lt;/script&gt;\n&lt;/div&gt;</p>\n"},
+		{"code span", "`<script>alert(1)</script>`", "<p><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></p>\n"},
+		{"code span quotes", "`\" onmouseover=\"alert(1)`", "<p><code>&quot; onmouseover=&quot;alert(1)</code></p>\n"},
+		{"fenced code", "```\n<script>alert(1)</script>\n```", "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;\n</code></pre>\n"},
+		{"fence info injection", "```js\" onmouseover=\"alert(1)\nx\n```", "<pre><code>x\n</code></pre>\n"},
+		{"fence language", "```c++\nx\n```", "<pre><code class=\"language-c++\">x\n</code></pre>\n"},
+		{"emphasis", "*<b>x</b>*", "<p><em>&lt;b&gt;x&lt;/b&gt;</em></p>\n"},
+		{"heading", "# <script>", "<h1>&lt;script&gt;</h1>\n"},
+		{"table cell", "| a |\n| - |\n| <script> |", "<td>&lt;script&gt;</td>"},
+		{"escaped bracket", `\<script>`, "<p>&lt;script&gt;</p>\n"},
+		{"link title", `[x](https://example.com "a\"><script>alert(1)</script>")`, `title="a&quot;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`},
+		{"unescaped quote ends title", `[x](https://example.com "a"><script>alert(1)</script>")`, "<p>[x](https://example.com &quot;a&quot;&gt;&lt;script&gt;"},
+		{"link title attribute injection", `[x](https://example.com 'a" onmouseover="alert(1)')`, `title="a&quot; onmouseover=&quot;alert(1)"`},
+		{"image title", `![x](https://example.com/i.png "\" onerror=\"alert(1)")`, `title="&quot; onerror=&quot;alert(1)"`},
+		{"link label", "[<script>](https://example.com)", `rel="nofollow noopener noreferrer">&lt;script&gt;</a>`},
+		{"character reference", "&lt;script&gt; &#60;b&#62;", "<p>&lt;script&gt; &#60;b&#62;</p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Render(tt.src)
+			checkSafe(t, out)
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("Render(%q) = %q, want it to contain %q", tt.src, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMath(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"text", `$\text{<img src=x onerror=alert(1)>}$`, "<mtext>&lt;img src=x onerror=alert(1)&gt;</mtext>"},
+		{"text quotes", `$\text{"><script>alert(1)</script>}$`, "<mtext>&quot;&gt;&lt;script&gt;alert(1)&lt;/script&gt;</mtext>"},
+		{"mathrm", `$\mathrm{<script>alert(1)</script>}$`, `<mi mathvariant="normal">&lt;script&gt;alert(1)&lt;/script&gt;</mi>`},
+		{"operatorname", `$\operatorname{" onmouseover="alert(1)}$`, `<mi mathvariant="normal">&quot; onmouseover=&quot;alert(1)</mi>`},
+		{"mathbb", `$\mathbb{<R}$`, "<mrow><mi>&lt;</mi><mi>ℝ</mi></mrow>"},
+		{"operators", "$a<b>c$", "<mi>a</mi><mo>&lt;</mo><mi>b</mi><mo>&gt;</mo><mi>c</mi>"},
+		{"annotation", "$<script>$", `<annotation encoding="application/x-tex">&lt;script&gt;</annotation>`},
+		{"unknown command", `$\script$`, `<mtext>\script</mtext>`},
+		{"unbalanced sqrt index", `$\sqrt[3 x$`, "<mroot><mrow><mrow></mrow></mrow><mrow><mn>3</mn><mi>x</mi></mrow></mroot>"},
+		{"sqrt index at end", `$x\sqrt[$`, "<mroot><mrow><mrow></mrow></mrow><mrow></mrow></mroot>"},
+		{"unclosed begin", `$\begin{pmatrix$`, "<mrow><mo>(</mo><mtable></mtable><mo>)</mo></mrow>"},
+		{"unclosed unknown begin", `$\begin{x y$`, `<mtext>\begin{x y}</mtext>`},
+		{"begin with markup", `$\begin{<script>}$`, `<mtext>\begin{&lt;script&gt;}</mtext>`},
+		{"unclosed environment", `$$\begin{pmatrix} a & b \\ c$$`, "<mrow><mo>(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd></mtr></mtable><mo>)</mo></mrow>"},
+		{"unmatched brace", "$a}b$", "<mi>a</mi><mo>}</mo><mi>b</mi>"},
+		{"unclosed group", "${a$", "<mrow><mi>a</mi></mrow>"},
+		{"display math", "$$\n\\frac{1}{2}\n$$", `<math display="block"><semantics><mrow><mfrac><mrow><mn>1</mn></mrow><mrow><mn>2</mn></mrow></mfrac></mrow>`},
+		{"dollars stay text", "$5 and $10", "<p>$5 and $10</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Render(tt.src)
+			checkSafe(t, out)
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("Render(%q) = %q, want it to contain %q", tt.src, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBoundsNesting(t *testing.T) {
+	const n = 5000
+	tests := []struct {
+		name string
+		src  string
+		// maxDepth bounds how deeply the output's elements nest
+		maxDepth int
+	}{
+		{"math groups", "$" + strings.Repeat("{", n) + "x" + strings.Repeat("}", n) + "$", maxMathNesting + 8},
+		{"math groups unclosed", "$" + strings.Repeat("{", n) + "x$", maxMathNesting + 8},
+		{"math scripts", "$x" + strings.Repeat("^{x", n) + strings.Repeat("}", n) + "$", 2*maxMathNesting + 8},
+		{"math fractions", "$" + strings.Repeat(`\frac`, n) + "$", 2*maxMathNesting + 8},
+		{"math roots", "$" + strings.Repeat(`\sqrt`, n) + "x$", 2*maxMathNesting + 8},
+		{"math accents", "$" + strings.Repeat(`\hat`, n) + "x$", 2*maxMathNesting + 8},
+		{"math root indices", "$" + strings.Repeat(`\sqrt[`, n) + "$", 2*maxMathNesting + 8},
+		{"math environments", "$" + strings.Repeat(`\begin{matrix}`, n) + "$", 4*maxMathNesting + 8},
+		{"math arguments ending in a backslash", "$" + strings.Repeat(`\frac`, 2*maxMathNesting) + `{\$`, 2*maxMathNesting + 8},
+		{"block quotes", strings.Repeat(">", n) + " x", maxNesting + 2},
+		{"lists", strings.Repeat("- ", n) + "x", 2*maxNesting + 2},
+		{"emphasis", strings.Repeat("*a ", n) + strings.Repeat("b*", n), maxInlineNesting + 2},
+		{"links", strings.Repeat("[", n) + "x" + strings.Repeat("](/a)", n), maxInlineNesting + 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Render(tt.src)
+			if depth := checkSafe(t, out); depth > tt.maxDepth {
+				t.Errorf("output nests %d deep, want at most %d", depth, tt.maxDepth)
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxMathNesting bounds how deeply groups nest in a formula; deeper content is
+// rendered as its TeX source
+const maxMathNesting = 32
+
+// math renders a TeX formula as MathML. The subset of TeX covers what problem
+// statements use: scripts, fractions, roots, binomials, matrices, cases,
+// Greek letters and the common operators, relations and functions. Unknown
+// commands are shown as their source. The formula's source is kept as an
+// annotation so it can be copied or rendered again on the client.
+func (r *renderer) math(tex string, display bool) {
+	tex = strings.TrimSpace(tex)
+	if display {
+		r.b.WriteString(`<math display="block">`)
+	} else {
+		r.b.WriteString("<math>")
+	}
+	r.b.WriteString("<semantics><mrow>")
+	p := &texParser{src: []rune(tex)}
+	for {
+		r.b.WriteString(p.expr(0, false))
+		if p.pos >= len(p.src) {
+			break
+		}
+		// An unmatched '}'
+		p.pos++
+		r.b.WriteString("<mo>}</mo>")
+	}
+	r.b.WriteString(`</mrow><annotation encoding="application/x-tex">`)
+	writeEscaped(&r.b, tex)
+	r.b.WriteString("</annotation></semantics></math>")
+}
+
+// texParser converts TeX to MathML. Everything it writes is either a fixed
+// element name or escaped text.
+type texParser struct {
+	src []rune
+	pos int
+}
+
+var texSymbols = map[string]string{
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠", "lt": "<", "gt": ">",
+	"ll": "≪", "gg": "≫", "approx": "≈", "equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝",
+	"times": "×", "cdot": "⋅", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆", "circ": "∘", "bullet": "•",
+	"oplus": "⊕", "otimes": "⊗", "wedge": "∧", "vee": "∨", "land": "∧", "lor": "∨", "lnot": "¬", "neg": "¬",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "leftrightarrow": "↔", "Rightarrow": "⇒", "Leftarrow": "⇐",
+	"Leftrightarrow": "⇔", "implies": "⟹", "iff": "⟺", "mapsto": "↦", "uparrow": "↑", "downarrow": "↓",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃", "supseteq": "⊇",
+	"cup": "∪", "cap": "∩", "setminus": "∖", "emptyset": "∅", "varnothing": "∅", "forall": "∀", "exists": "∃",
+	"mid": "∣", "nmid": "∤", "parallel": "∥", "perp": "⊥", "angle": "∠", "triangle": "△", "infty": "∞",
+	"partial": "∂", "nabla": "∇", "prime": "′", "dots": "…", "ldots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉", "langle": "⟨", "rangle": "⟩", "vert": "|", "Vert": "‖",
+	"lbrace": "{", "rbrace": "}", "sum": "∑", "prod": "∏", "int": "∫", "bigcup": "⋃", "bigcap": "⋂",
+	"bigoplus": "⨁", "coprod": "∐", "oint": "∮", "colon": ":", "bmod": "mod", "And": "&",
+}
+
+var texIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε", "zeta": "ζ",
+	"eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν",
+	"xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ",
+	"upsilon": "υ", "phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π", "Sigma": "Σ",
+	"Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω", "ell": "ℓ", "hbar": "ℏ", "aleph": "ℵ",
+}
+
+var texFunctions = map[string]bool{
+	"log": true, "ln": true, "lg": true, "exp": true, "min": true, "max": true, "gcd": true, "lcm": true,
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true, "arcsin": true, "arccos": true,
+	"arctan": true, "sinh": true, "cosh": true, "tanh": true, "det": true, "dim": true, "deg": true, "lim": true,
+	"sup": true, "inf": true, "arg": true, "ker": true, "Pr": true, "limsup": true, "liminf": true,
+}
+
+var texSpaces = map[string]string{
+	",": "0.167em", ":": "0.222em", ";": "0.278em", " ": "0.333em", "quad": "1em", "qquad": "2em",
+	"enspace": "0.5em", "thinspace": "0.167em", "medspace": "0.222em", "thickspace": "0.278em",
+}
+
+// texAccents are commands that put a mark over their argument
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "vec": "→", "overrightarrow": "→",
+	"tilde": "~", "widetilde": "~", "dot": "˙", "ddot": "¨",
+}
+
+var texDoubleStruck = map[rune]string{'N': "ℕ", 'Z': "ℤ", 'Q': "ℚ", 'R': "ℝ", 'C': "ℂ", 'P': "ℙ"}
+
+// texFences pairs the environments that are a matrix between delimiters
+var texFences = map[string][2]string{
+	"matrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"}, "Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "array": {"", ""}, "aligned": {"", ""},
+	"align": {"", ""}, "align*": {"", ""}, "gathered": {"", ""}, "cases": {"{", ""},
+}
+
+// expr parses until the end of input or an unmatched '}'. In a table it also
+// stops before '&', '\\' and \end.
+func (p *texParser) expr(depth int, inTable bool) string {
+	var b strings.Builder
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		c := p.src[p.pos]
+		if c == '}' {
+			break
+		}
+		if inTable && (c == '&' || p.peekCommand() == `\` || p.peekCommand() == "end") {
+			break
+		}
+
+		atom := p.atom(depth)
+		b.WriteString(p.scripts(atom, depth))
+	}
+	return b.String()
+}
+
+// scripts attaches any ^ and _ that follow base
+func (p *texParser) scripts(base string, depth int) string {
+	var sub, sup string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		switch p.src[p.pos] {
+		case '_':
+			p.pos++
+			sub = p.argument(depth)
+			continue
+		case '^':
+			p.pos++
+			sup = p.argument(depth)
+			continue
+		case '\'':
+			p.pos++
+			sup += "<mo>′</mo>"
+			continue
+		}
+		break
+	}
+
+	switch {
+	case sub != "" && sup != "":
+		return "<msubsup>" + base + sub + sup + "</msubsup>"
+	case sub != "":
+		return "<msub>" + base + sub + "</msub>"
+	case sup != "":
+		return "<msup>" + base + sup + "</msup>"
+	}
+	return base
+}
+
+// argument parses a command or script argument: a group or a single atom.
+// Arguments nest like groups, so commands taking commands as arguments cannot
+// nest deeper than maxMathNesting either.
+func (p *texParser) argument(depth int) string {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return "<mrow></mrow>"
+	}
+	if depth >= maxMathNesting {
+		start := p.pos
+		switch p.src[p.pos] {
+		case '{':
+			p.pos++
+			p.rawGroupRest()
+		case '\\':
+			p.readCommand()
+		default:
+			p.pos++
+		}
+		return "<mtext>" + escapeText(string(p.src[start:min(p.pos, len(p.src))])) + "</mtext>"
+	}
+	if p.src[p.pos] == '{' {
+		return p.group(depth)
+	}
+	return p.atom(depth + 1)
+}
+
+// group parses {...} as an mrow
+func (p *texParser) group(depth int) string {
+	p.pos++
+	if depth >= maxMathNesting {
+		return "<mtext>" + escapeText(p.rawGroupRest()) + "</mtext>"
+	}
+	inner := p.expr(depth+1, false)
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+	}
+	return "<mrow>" + inner + "</mrow>"
+}
+
+// rawArgument returns the source of a {...} argument without parsing it
+func (p *texParser) rawArgument() string {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if p.src[p.pos] != '{' {
+		p.pos++
+		return string(p.src[p.pos-1])
+	}
+	p.pos++
+	return p.rawGroupRest()
+}
+
+// rawGroupRest returns the source up to the '}' closing the current group and skips past it
+func (p *texParser) rawGroupRest() string {
+	start, nesting := p.pos, 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			nesting++
+		case '}':
+			if nesting == 0 {
+				s := string(p.src[start:p.pos])
+				p.pos++
+				return s
+			}
+			nesting--
+		}
+	}
+	return string(p.src[start:])
+}
+
+func (p *texParser) atom(depth int) string {
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		return p.group(depth)
+	case c == '\\':
+		return p.command(depth)
+	case c >= '0' && c <= '9' || c == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]):
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1])) {
+			p.pos++
+		}
+		return "<mn>" + string(p.src[start:p.pos]) + "</mn>"
+	case unicode.IsLetter(c):
+		p.pos++
+		return "<mi>" + escapeText(string(c)) + "</mi>"
+	case c == '^' || c == '_':
+		// A script with no base
+		return "<mrow></mrow>"
+	case c == '&':
+		p.pos++
+		return ""
+	case c == '~':
+		p.pos++
+		return `<mspace width="0.333em"></mspace>`
+	case c == '-':
+		p.pos++
+		return "<mo>−</mo>"
+	case c == '*':
+		p.pos++
+		return "<mo>∗</mo>"
+	default:
+		p.pos++
+		return "<mo>" + escapeText(string(c)) + "</mo>"
+	}
+}
+
+func (p *texParser) command(depth int) string {
+	name := p.readCommand()
+
+	if s, ok := texSymbols[name]; ok {
+		return "<mo>" + escapeText(s) + "</mo>"
+	}
+	if s, ok := texIdentifiers[name]; ok {
+		return "<mi>" + s + "</mi>"
+	}
+	if texFunctions[name] {
+		return "<mi>" + name + "</mi>"
+	}
+	if width, ok := texSpaces[name]; ok {
+		return `<mspace width="` + width + `"></mspace>`
+	}
+	if accent, ok := texAccents[name]; ok {
+		return `<mover accent="true"><mrow>` + p.argument(depth) + "</mrow><mo>" + escapeText(accent) + "</mo></mover>"
+	}
+
+	switch name {
+	case "":
+		return ""
+	case "{", "}", "%", "$", "#", "|":
+		return "<mo>" + escapeText(name) + "</mo>"
+	case "&", "_":
+		return "<mi>" + escapeText(name) + "</mi>"
+	case "!", `\`:
+		return ""
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num := p.argument(depth)
+		den := p.argument(depth)
+		return "<mfrac>" + num + den + "</mfrac>"
+	case "binom", "dbinom", "tbinom", "choose":
+		top := p.argument(depth)
+		bottom := p.argument(depth)
+		return `<mrow><mo>(</mo><mfrac linethickness="0">` + top + bottom + "</mfrac><mo>)</mo></mrow>"
+	case "sqrt":
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '[' {
+			end := p.pos
+			for end < len(p.src) && p.src[end] != ']' {
+				end++
+			}
+			source := p.src[p.pos+1 : end]
+			p.pos = end + 1
+			index := "<mtext>" + escapeText(string(source)) + "</mtext>"
+			if depth < maxMathNesting {
+				index = (&texParser{src: source}).expr(depth+1, false)
+			}
+			return "<mroot><mrow>" + p.argument(depth) + "</mrow><mrow>" + index + "</mrow></mroot>"
+		}
+		return "<msqrt>" + p.argument(depth) + "</msqrt>"
+	case "text", "textrm", "textnormal", "mbox", "textit", "textbf", "texttt":
+		return "<mtext>" + escapeText(p.rawArgument()) + "</mtext>"
+	case "mathrm", "operatorname", "rm":
+		return `<mi mathvariant="normal">` + escapeText(p.rawArgument()) + "</mi>"
+	case "mathbb":
+		var b strings.Builder
+		for _, c := range p.rawArgument() {
+			if s, ok := texDoubleStruck[c]; ok {
+				b.WriteString("<mi>" + s + "</mi>")
+			} else {
+				b.WriteString("<mi>" + escapeText(string(c)) + "</mi>")
+			}
+		}
+		return "<mrow>" + b.String() + "</mrow>"
+	case "mathbf", "boldsymbol", "mathit", "mathcal", "mathsf", "mathtt", "displaystyle", "textstyle", "scriptstyle":
+		if name == "displaystyle" || name == "textstyle" || name == "scriptstyle" {
+			return ""
+		}
+		return p.argument(depth)
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr", "biggl", "biggr", "middle":
+		// The delimiter that follows is rendered as is; "." means none
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+		}
+		return ""
+	case "pmod":
+		return "<mrow><mo>(</mo><mi>mod</mi>" + p.argument(depth) + "<mo>)</mo></mrow>"
+	case "not":
+		return "<mo>¬</mo>"
+	case "begin":
+		return p.environment(depth)
+	case "end":
+		p.rawArgument()
+		return ""
+	}
+
+	return "<mtext>" + escapeText(`\`+name) + "</mtext>"
+}
+
+// environment parses \begin{name}...\end{name} as a table
+func (p *texParser) environment(depth int) string {
+	name := p.rawArgument()
+	if name == "array" {
+		// Skip the column specification
+		p.rawArgument()
+	}
+	fences, ok := texFences[name]
+	if !ok || depth >= maxMathNesting {
+		return "<mtext>" + escapeText(`\begin{`+name+`}`) + "</mtext>"
+	}
+
+	var rows []string
+	var cells []string
+	for {
+		cells = append(cells, "<mtd>"+p.expr(depth+1, true)+"</mtd>")
+		if p.pos >= len(p.src) || p.src[p.pos] == '}' {
+			break
+		}
+		if p.src[p.pos] == '&' {
+			p.pos++
+			continue
+		}
+		command := p.readCommand()
+		if command == `\` {
+			rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+			cells = nil
+			continue
+		}
+		// \end{name}
+		p.rawArgument()
+		break
+	}
+	if len(cells) > 1 || cells[0] != "<mtd></mtd>" {
+		rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+	}
+
+	table := "<mtable>" + strings.Join(rows, "") + "</mtable>"
+	if name == "cases" {
+		table = `<mtable columnalign="left left">` + strings.Join(rows, "") + "</mtable>"
+	}
+	var b strings.Builder
+	b.WriteString("<mrow>")
+	if fences[0] != "" {
+		b.WriteString("<mo>" + escapeText(fences[0]) + "</mo>")
+	}
+	b.WriteString(table)
+	if fences[1] != "" {
+		b.WriteString("<mo>" + escapeText(fences[1]) + "</mo>")
+	}
+	b.WriteString("</mrow>")
+	return b.String()
+}
+
+// readCommand reads the command at a backslash: a run of letters or one other character
+func (p *texParser) readCommand() string {
+	p.pos++
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	start := p.pos
+	if !isASCIILetter(p.src[p.pos]) {
+		p.pos++
+		return string(p.src[start:p.pos])
+	}
+	for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '*' {
+		// Starred forms render like the plain ones
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// peekCommand returns the command at the current position without consuming it
+func (p *texParser) peekCommand() string {
+	if p.pos >= len(p.src) || p.src[p.pos] != '\\' {
+		return ""
+	}
+	pos := p.pos
+	name := p.readCommand()
+	p.pos = pos
+	return name
+}
+
+func (p *texParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isASCIILetter(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func escapeText(s string) string {
+	return escaper.Replace(s)
+}
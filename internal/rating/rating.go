@@ -0,0 +1,115 @@
+// Package rating estimates problem ratings from solve outcomes, in the style
+// of Codeforces problem ratings: a problem's rating is the user rating at
+// which a user is expected to solve it half of the time. Solve chances follow
+// the Elo curve, so problem ratings share the scale of user ratings.
+package rating
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+
+	"codestandoff/backend/internal/database"
+)
+
+const (
+	// Scale is the Elo scale: a user rated Scale points above a problem solves it ten times as often as they fail it
+	Scale = 400.0
+
+	// Bounds of an estimated rating
+	MinRating = 0
+	MaxRating = 4000
+
+	// PriorWeight is how many outcomes the difficulty prior counts for, so a
+	// handful of solves cannot swing a rating to the bounds
+	PriorWeight = 4
+)
+
+// DifficultyPriors are the ratings assumed for each difficulty before any outcomes are known
+var DifficultyPriors = map[string]int{
+	"Easy":   800,
+	"Medium": 1400,
+	"Hard":   2000,
+}
+
+// defaultPrior is used for difficulties missing from DifficultyPriors
+const defaultPrior = 1400
+
+// Outcome is one user's result on a problem
+type Outcome struct {
+	UserRating float64
+	Solved     bool
+}
+
+// SolveProbability is the chance that a user with userRating solves a problem with problemRating
+func SolveProbability(userRating, problemRating float64) float64 {
+	return 1 / (1 + math.Pow(10, (problemRating-userRating)/Scale))
+}
+
+// Prior returns the rating assumed for a difficulty
+func Prior(difficulty string) int {
+	if r, ok := DifficultyPriors[difficulty]; ok {
+		return r
+	}
+	return defaultPrior
+}
+
+// Estimate returns the maximum-likelihood rating for a problem given its
+// outcomes. The prior enters as PriorWeight outcomes from users rated at the
+// prior, half of them solved, so it dominates until real outcomes outnumber it.
+func Estimate(prior int, outcomes []Outcome) int {
+	// The log-likelihood is concave in the problem rating and its derivative
+	// is proportional to sum(p - solved), which decreases as the rating grows,
+	// so the maximum is where that sum crosses zero.
+	excess := func(r float64) float64 {
+		sum := PriorWeight * (SolveProbability(float64(prior), r) - 0.5)
+		for _, o := range outcomes {
+			sum += SolveProbability(o.UserRating, r)
+			if o.Solved {
+				sum--
+			}
+		}
+		return sum
+	}
+
+	lo, hi := float64(MinRating), float64(MaxRating)
+	for hi-lo > 0.5 {
+		mid := (lo + hi) / 2
+		if excess(mid) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return int(math.Round((lo + hi) / 2))
+}
+
+// RecomputeProblemRatings re-estimates the rating of every question from its
+// outcomes and stores the results. It returns the number of questions updated.
+func RecomputeProblemRatings(db *sql.DB) (int, error) {
+	samples, err := database.GetRatingSamples(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load outcomes: %w", err)
+	}
+
+	ratings := make([]database.ProblemRating, 0, len(samples))
+	for _, s := range samples {
+		outcomes := make([]Outcome, len(s.Outcomes))
+		for i, o := range s.Outcomes {
+			outcomes[i] = Outcome{UserRating: float64(o.UserRating), Solved: o.Solved}
+		}
+		ratings = append(ratings, database.ProblemRating{
+			QuestionID: s.QuestionID,
+			Rating:     Estimate(Prior(s.Difficulty), outcomes),
+			Samples:    len(outcomes),
+		})
+	}
+
+	updated, err := database.SetProblemRatings(db, ratings)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store ratings: %w", err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,84 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// outcomes returns solved outcomes followed by failed ones, all by users rated userRating
+func outcomes(userRating float64, solved, failed int) []Outcome {
+	var out []Outcome
+	for i := 0; i < solved+failed; i++ {
+		out = append(out, Outcome{UserRating: userRating, Solved: i < solved})
+	}
+	return out
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		prior    int
+		outcomes []Outcome
+		want     int
+	}{
+		{name: "prior only", prior: 1400, want: 1400},
+		{name: "easy prior only", prior: 800, want: 800},
+		{name: "half solved at the prior", prior: 2000, outcomes: outcomes(2000, 10, 10), want: 2000},
+		{name: "all solved clamps to the minimum", prior: 800, outcomes: outcomes(MinRating, 1000, 0), want: MinRating},
+		{name: "all failed clamps to the maximum", prior: 2000, outcomes: outcomes(MaxRating, 0, 1000), want: MaxRating},
+		// With every user at the prior, the estimate solves (4+n)p = 2+solved:
+		// p = 3/10, so the rating is 1400 + 400*log10(7/3)
+		{name: "one of six solved", prior: 1400, outcomes: outcomes(1400, 1, 5), want: 1547},
+		{name: "five of six solved", prior: 1400, outcomes: outcomes(1400, 5, 1), want: 1253},
+		{
+			name:  "outcomes symmetric around the prior",
+			prior: 1400,
+			outcomes: []Outcome{
+				{UserRating: 1200, Solved: true},
+				{UserRating: 1600, Solved: false},
+				{UserRating: 1000, Solved: true},
+				{UserRating: 1800, Solved: false},
+			},
+			want: 1400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Estimate(tt.prior, tt.outcomes); got != tt.want {
+				t.Errorf("Estimate(%d, %d outcomes) = %d, want %d", tt.prior, len(tt.outcomes), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimatePriorWeight(t *testing.T) {
+	// More failures move the rating further up, and the prior keeps a couple of them from reaching the bound
+	few := Estimate(800, outcomes(800, 0, 2))
+	many := Estimate(800, outcomes(800, 0, 20))
+	if !(800 < few && few < many && many < MaxRating) {
+		t.Errorf("Estimate with 2 and 20 failures = %d and %d, want 800 < %[1]d < %[2]d < %d", few, many, MaxRating)
+	}
+}
+
+func TestSolveProbability(t *testing.T) {
+	tests := []struct {
+		user, problem, want float64
+	}{
+		{user: 1500, problem: 1500, want: 0.5},
+		{user: 1900, problem: 1500, want: 10.0 / 11},
+		{user: 1500, problem: 1900, want: 1.0 / 11},
+	}
+	for _, tt := range tests {
+		if got := SolveProbability(tt.user, tt.problem); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("SolveProbability(%v, %v) = %v, want %v", tt.user, tt.problem, got, tt.want)
+		}
+	}
+}
+
+func TestPrior(t *testing.T) {
+	for difficulty, want := range map[string]int{"Easy": 800, "Medium": 1400, "Hard": 2000, "": defaultPrior} {
+		if got := Prior(difficulty); got != want {
+			t.Errorf("Prior(%q) = %d, want %d", difficulty, got, want)
+		}
+	}
+}
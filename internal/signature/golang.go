@@ -0,0 +1,267 @@
+package signature
+
+import (
+	"fmt"
+	"strings"
+)
+
+type goGenerator struct{}
+
+func (goGenerator) typeName(t *Type) string {
+	switch t.Kind {
+	case Int:
+		return "int"
+	case Long:
+		return "int64"
+	case Double:
+		return "float64"
+	case Bool:
+		return "bool"
+	case String:
+		return "string"
+	case ListNode:
+		return "*ListNode"
+	case TreeNode:
+		return "*TreeNode"
+	default:
+		return "[]" + goGenerator{}.typeName(t.Elem)
+	}
+}
+
+// Go solutions live in their own file so they can have their own imports
+func (g goGenerator) starter(p *parsed) string {
+	var b strings.Builder
+	b.WriteString("package main\n\n")
+	if p.uses(ListNode) {
+		b.WriteString(`/**
+ * Definition for singly-linked list.
+ * type ListNode struct {
+ *     Val int
+ *     Next *ListNode
+ * }
+ */
+`)
+	}
+	if p.uses(TreeNode) {
+		b.WriteString(`/**
+ * Definition for a binary tree node.
+ * type TreeNode struct {
+ *     Val int
+ *     Left *TreeNode
+ *     Right *TreeNode
+ * }
+ */
+`)
+	}
+
+	params := make([]string, len(p.Params))
+	for i, param := range p.Params {
+		params[i] = param.Name + " " + g.typeName(param.Type)
+	}
+	fmt.Fprintf(&b, "func %s(%s) %s {\n\t\n}\n", p.Function, strings.Join(params, ", "), g.typeName(p.Returns))
+	return b.String()
+}
+
+func (g goGenerator) harness(p *parsed) *Harness {
+	var b strings.Builder
+	b.WriteString(goRuntime)
+	b.WriteString("\nfunc main() {\n\tlines := harnessReadLines()\n")
+	fmt.Fprintf(&b, "\tif len(lines) < %d {\n\t\tpanic(fmt.Sprintf(\"expected %d input lines, got %%d\", len(lines)))\n\t}\n", len(p.Params), len(p.Params))
+
+	args := make([]string, len(p.Params))
+	for i, param := range p.Params {
+		// Prefix the locals so they cannot shadow the solution's own declarations
+		args[i] = "arg" + param.Name
+		fmt.Fprintf(&b, "\tvar %s %s\n\tharnessDecode(lines[%d], &%s)\n", args[i], g.typeName(param.Type), i, args[i])
+	}
+	fmt.Fprintf(&b, "\tvar result %s = %s(%s)\n", g.typeName(p.Returns), p.Function, strings.Join(args, ", "))
+	b.WriteString("\tfmt.Println(harnessEncode(result))\n}\n")
+
+	return &Harness{MainFile: "main.go", SolutionFile: "solution.go", suffix: b.String()}
+}
+
+const goRuntime = `package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+)
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+
+var (
+	harnessListNodeType = reflect.TypeOf((*ListNode)(nil))
+	harnessTreeNodeType = reflect.TypeOf((*TreeNode)(nil))
+)
+
+func harnessReadLines() []string {
+	data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+	if err != nil {
+		panic(err)
+	}
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
+
+// harnessDecode parses one JSON input line into dst
+func harnessDecode(line string, dst interface{}) {
+	d := json.NewDecoder(strings.NewReader(line))
+	d.UseNumber()
+	var raw interface{}
+	if err := d.Decode(&raw); err != nil {
+		panic(err)
+	}
+	harnessAssign(reflect.ValueOf(dst).Elem(), raw)
+}
+
+func harnessAssign(v reflect.Value, raw interface{}) {
+	switch v.Type() {
+	case harnessListNodeType:
+		head := &ListNode{}
+		tail := head
+		for _, item := range raw.([]interface{}) {
+			tail.Next = &ListNode{Val: harnessInt(item)}
+			tail = tail.Next
+		}
+		v.Set(reflect.ValueOf(head.Next))
+		return
+	case harnessTreeNodeType:
+		v.Set(reflect.ValueOf(harnessBuildTree(raw.([]interface{}))))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		items := raw.([]interface{})
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			harnessAssign(s.Index(i), item)
+		}
+		v.Set(s)
+	case reflect.Int, reflect.Int64:
+		n, err := raw.(json.Number).Int64()
+		if err != nil {
+			panic(err)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := raw.(json.Number).Float64()
+		if err != nil {
+			panic(err)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		v.SetBool(raw.(bool))
+	case reflect.String:
+		v.SetString(raw.(string))
+	default:
+		panic("unsupported type " + v.Type().String())
+	}
+}
+
+func harnessInt(raw interface{}) int {
+	n, err := raw.(json.Number).Int64()
+	if err != nil {
+		panic(err)
+	}
+	return int(n)
+}
+
+func harnessBuildTree(values []interface{}) *TreeNode {
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	root := &TreeNode{Val: harnessInt(values[0])}
+	queue := []*TreeNode{root}
+	i := 1
+	for q := 0; q < len(queue) && i < len(values); q++ {
+		node := queue[q]
+		if values[i] != nil {
+			node.Left = &TreeNode{Val: harnessInt(values[i])}
+			queue = append(queue, node.Left)
+		}
+		i++
+		if i < len(values) && values[i] != nil {
+			node.Right = &TreeNode{Val: harnessInt(values[i])}
+			queue = append(queue, node.Right)
+		}
+		i++
+	}
+	return root
+}
+
+// harnessEncode returns the compact JSON form of a result
+func harnessEncode(result interface{}) string {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(harnessPlain(reflect.ValueOf(result))); err != nil {
+		panic(err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// harnessPlain converts linked lists and trees to arrays, nil slices to empty
+// ones and floats JSON cannot hold to null
+func harnessPlain(v reflect.Value) interface{} {
+	switch v.Type() {
+	case harnessListNodeType:
+		out := []int{}
+		for node := v.Interface().(*ListNode); node != nil; node = node.Next {
+			out = append(out, node.Val)
+		}
+		return out
+	case harnessTreeNodeType:
+		out := []interface{}{}
+		queue := []*TreeNode{v.Interface().(*TreeNode)}
+		for q := 0; q < len(queue); q++ {
+			node := queue[q]
+			if node == nil {
+				out = append(out, nil)
+				continue
+			}
+			out = append(out, node.Val)
+			queue = append(queue, node.Left, node.Right)
+		}
+		for len(out) > 0 && out[len(out)-1] == nil {
+			out = out[:len(out)-1]
+		}
+		return out
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = harnessPlain(v.Index(i))
+		}
+		return out
+	case reflect.Float64:
+		// encoding/json writes floats as JavaScript does, except that it rejects
+		// NaN and infinities and keeps the sign of -0
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		if f == 0 {
+			return 0
+		}
+	}
+	return v.Interface()
+}
+`
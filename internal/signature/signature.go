@@ -0,0 +1,330 @@
+// Package signature describes a problem's function signature once, independent
+// of language, and generates starter code and judge harnesses from it.
+//
+// A signature is stored as JSON:
+//
+//	{"function": "twoSum", "params": [{"name": "nums", "type": "int[]"}, {"name": "target", "type": "int"}], "returns": "int[]"}
+//
+// Types are int, long, double, bool, string, ListNode (a singly linked list of
+// ints), TreeNode (a binary tree of ints) and arrays of any of them, written
+// with a [] suffix per dimension. In JavaScript longs are BigInts, as numbers
+// cannot hold every long exactly.
+//
+// Harnesses read one JSON value per line from stdin, one line per parameter,
+// call the solution and print the result as compact JSON on one line. Linked
+// lists are written as arrays and trees as level-order arrays with null for
+// missing children, e.g. [1,null,2,3]. Doubles are written the way JavaScript
+// writes numbers, with the shortest digits that read back as the same value,
+// e.g. 3, 0.1 and 1e+21, so every language prints the same result.
+package signature
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits on a signature
+const (
+	maxParams     = 10
+	maxArrayDepth = 3
+)
+
+// Kind is the kind of a value type
+type Kind int
+
+const (
+	Int Kind = iota
+	Long
+	Double
+	Bool
+	String
+	ListNode
+	TreeNode
+	Array
+)
+
+var kindNames = map[string]Kind{
+	"int":      Int,
+	"long":     Long,
+	"double":   Double,
+	"bool":     Bool,
+	"string":   String,
+	"ListNode": ListNode,
+	"TreeNode": TreeNode,
+}
+
+// Type is a parameter or return type
+type Type struct {
+	Kind Kind
+	// Elem is the element type of an array
+	Elem *Type
+}
+
+// ParseType parses a type such as "int", "string[]" or "TreeNode"
+func ParseType(s string) (*Type, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "[]") {
+		elem, err := ParseType(strings.TrimSuffix(s, "[]"))
+		if err != nil {
+			return nil, err
+		}
+		t := &Type{Kind: Array, Elem: elem}
+		if t.depth() > maxArrayDepth {
+			return nil, fmt.Errorf("type %q has more than %d array dimensions", s, maxArrayDepth)
+		}
+		return t, nil
+	}
+
+	kind, ok := kindNames[s]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", s)
+	}
+	return &Type{Kind: kind}, nil
+}
+
+// String returns the type in signature syntax
+func (t *Type) String() string {
+	if t.Kind == Array {
+		return t.Elem.String() + "[]"
+	}
+	for name, kind := range kindNames {
+		if kind == t.Kind {
+			return name
+		}
+	}
+	return "?"
+}
+
+func (t *Type) depth() int {
+	if t.Kind != Array {
+		return 0
+	}
+	return 1 + t.Elem.depth()
+}
+
+// base returns the innermost element type
+func (t *Type) base() *Type {
+	if t.Kind == Array {
+		return t.Elem.base()
+	}
+	return t
+}
+
+// Spec is a language-agnostic function signature
+type Spec struct {
+	Function string  `json:"function"`
+	Params   []Param `json:"params"`
+	Returns  string  `json:"returns"`
+}
+
+// Param is a named function parameter
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// parsed is a validated spec with its types resolved
+type parsed struct {
+	Function string
+	Params   []parsedParam
+	Returns  *Type
+}
+
+type parsedParam struct {
+	Name string
+	Type *Type
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reserved are names that are keywords, or clash with harness names, in a supported language
+var reserved = map[string]bool{}
+
+func init() {
+	for _, words := range []string{
+		// Python
+		"False None True and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield self",
+		// JavaScript
+		"case catch const debugger default delete do export extends function instanceof let new super switch this throw typeof var void arguments eval",
+		// Go
+		"chan defer fallthrough func go goto interface map package range select struct type",
+		// Java and C++
+		"abstract boolean byte char double enum final float int long native private protected public short static strictfp synchronized throws transient volatile auto bool delete explicit friend inline mutable namespace operator register signed sizeof template typedef typename union unsigned using virtual",
+		// Harness names
+		"main Main Solution ListNode TreeNode",
+	} {
+		for _, w := range strings.Fields(words) {
+			reserved[w] = true
+		}
+	}
+}
+
+// Parse decodes and validates a JSON signature
+func Parse(data []byte) (*Spec, error) {
+	s := &Spec{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks the names and types of the signature
+func (s *Spec) Validate() error {
+	_, err := s.parse()
+	return err
+}
+
+func (s *Spec) parse() (*parsed, error) {
+	var errs []error
+	p := &parsed{Function: s.Function}
+
+	if err := checkName(s.Function); err != nil {
+		errs = append(errs, fmt.Errorf("function: %w", err))
+	}
+	if len(s.Params) > maxParams {
+		errs = append(errs, fmt.Errorf("a function can have at most %d parameters", maxParams))
+	}
+
+	seen := map[string]bool{}
+	for i, param := range s.Params {
+		if err := checkName(param.Name); err != nil {
+			errs = append(errs, fmt.Errorf("parameter %d: %w", i+1, err))
+		} else if seen[param.Name] || param.Name == s.Function {
+			errs = append(errs, fmt.Errorf("parameter %d: duplicate name %q", i+1, param.Name))
+		}
+		seen[param.Name] = true
+
+		t, err := ParseType(param.Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter %q: %w", param.Name, err))
+		}
+		p.Params = append(p.Params, parsedParam{Name: param.Name, Type: t})
+	}
+
+	returns, err := ParseType(s.Returns)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("returns: %w", err))
+	}
+	p.Returns = returns
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// String returns the signature on one line, e.g. "twoSum(nums: int[], target: int): int[]"
+func (s *Spec) String() string {
+	params := make([]string, len(s.Params))
+	for i, param := range s.Params {
+		params[i] = param.Name + ": " + param.Type
+	}
+	return fmt.Sprintf("%s(%s): %s", s.Function, strings.Join(params, ", "), s.Returns)
+}
+
+func checkName(name string) error {
+	if !identifier.MatchString(name) {
+		return fmt.Errorf("%q is not a valid identifier", name)
+	}
+	if reserved[name] {
+		return fmt.Errorf("%q is a reserved word", name)
+	}
+	return nil
+}
+
+// uses reports whether any parameter or the return value involves kind
+func (p *parsed) uses(kind Kind) bool {
+	if p.Returns.base().Kind == kind {
+		return true
+	}
+	for _, param := range p.Params {
+		if param.Type.base().Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// generator produces the code for one language
+type generator interface {
+	starter(p *parsed) string
+	harness(p *parsed) *Harness
+}
+
+var generators = map[string]generator{
+	"cpp":        cppGenerator{},
+	"go":         goGenerator{},
+	"java":       javaGenerator{},
+	"javascript": javascriptGenerator{},
+	"python":     pythonGenerator{},
+}
+
+// Languages returns the languages code can be generated for, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(generators))
+	for language := range generators {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Starter returns the starter code for a language
+func (s *Spec) Starter(language string) (string, error) {
+	g, p, err := s.generator(language)
+	if err != nil {
+		return "", err
+	}
+	return g.starter(p), nil
+}
+
+// Harness returns the judge harness for a language
+func (s *Spec) Harness(language string) (*Harness, error) {
+	g, p, err := s.generator(language)
+	if err != nil {
+		return nil, err
+	}
+	return g.harness(p), nil
+}
+
+func (s *Spec) generator(language string) (generator, *parsed, error) {
+	g, ok := generators[language]
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot generate code for language %q", language)
+	}
+	p, err := s.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, p, nil
+}
+
+// Harness is a program that reads a test input, calls the solution and
+// prints its result. The solution is either wrapped into MainFile between
+// the harness's own code, or, where the language needs it in a file of its
+// own, written to SolutionFile next to MainFile.
+type Harness struct {
+	// MainFile is the file to compile or run
+	MainFile     string
+	SolutionFile string
+	prefix       string
+	suffix       string
+}
+
+// Files returns the source files of the harness wrapped around solution, by name
+func (h *Harness) Files(solution string) map[string]string {
+	if h.SolutionFile != "" {
+		return map[string]string{
+			h.MainFile:     h.prefix + h.suffix,
+			h.SolutionFile: solution,
+		}
+	}
+	return map[string]string{h.MainFile: h.prefix + solution + "\n" + h.suffix}
+}
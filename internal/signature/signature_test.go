@@ -0,0 +1,204 @@
+package signature
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenSpecs are the signatures whose generated code is kept in testdata/<name>/<language>
+var goldenSpecs = map[string]Spec{
+	"two_sum": {
+		Function: "twoSum",
+		Params:   []Param{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+		Returns:  "int[]",
+	},
+	"add_two_numbers": {
+		Function: "addTwoNumbers",
+		Params:   []Param{{Name: "l1", Type: "ListNode"}, {Name: "l2", Type: "ListNode"}},
+		Returns:  "ListNode",
+	},
+	"level_order": {
+		Function: "levelOrder",
+		Params:   []Param{{Name: "root", Type: "TreeNode"}},
+		Returns:  "int[][]",
+	},
+	"scalars": {
+		Function: "describe",
+		Params: []Param{
+			{Name: "count", Type: "long"},
+			{Name: "ratio", Type: "double"},
+			{Name: "flag", Type: "bool"},
+			{Name: "name", Type: "string"},
+			{Name: "grid", Type: "string[][]"},
+		},
+		Returns: "double[]",
+	},
+}
+
+func TestGolden(t *testing.T) {
+	for name, spec := range goldenSpecs {
+		for _, language := range Languages() {
+			t.Run(name+"/"+language, func(t *testing.T) {
+				h, err := spec.Harness(language)
+				if err != nil {
+					t.Fatal(err)
+				}
+				starter, err := spec.Starter(language)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				files := map[string]string{"starter" + path.Ext(h.MainFile): starter}
+				for file, src := range h.Files("") {
+					if src != "" {
+						files[file] = src
+					}
+				}
+				for file, got := range files {
+					golden := filepath.Join("testdata", name, language, file)
+					if *update {
+						if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+							t.Fatal(err)
+						}
+						if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+							t.Fatal(err)
+						}
+						continue
+					}
+					want, err := os.ReadFile(golden)
+					if err != nil {
+						t.Fatalf("%v (run go test -update to create it)", err)
+					}
+					if got != string(want) {
+						t.Errorf("%s differs from %s; run go test -update and review the diff", file, golden)
+					}
+				}
+			})
+		}
+	}
+}
+
+// toolchains mirror the commands of internal/judge/languages.json
+var toolchains = map[string]struct {
+	compile, run []string
+	env          []string
+}{
+	"cpp":        {compile: []string{"g++", "-std=gnu++17", "-O2", "-pipe", "-o", "main", "main.cpp"}, run: []string{"./main"}},
+	"go":         {compile: []string{"go", "build", "-o", "main", "."}, run: []string{"./main"}, env: []string{"GOTOOLCHAIN=local", "GO111MODULE=off", "CGO_ENABLED=0"}},
+	"java":       {compile: []string{"javac", "-encoding", "UTF-8", "Main.java"}, run: []string{"java", "-cp", ".", "Main"}},
+	"javascript": {run: []string{"node", "main.js"}},
+	"python":     {run: []string{"python3", "-B", "main.py"}},
+}
+
+// echoSolution returns a solution to echo(value: t): t that returns its argument
+func echoSolution(language string, t *Type) string {
+	switch language {
+	case "cpp":
+		g := cppGenerator{}
+		return fmt.Sprintf("class Solution {\npublic:\n    %s echo(%s value) {\n        return value;\n    }\n};", g.typeName(t), g.paramType(t))
+	case "go":
+		g := goGenerator{}
+		return fmt.Sprintf("package main\n\nfunc echo(value %s) %[1]s {\n\treturn value\n}\n", g.typeName(t))
+	case "java":
+		g := javaGenerator{}
+		return fmt.Sprintf("class Solution {\n    public %s echo(%[1]s value) {\n        return value;\n    }\n}", g.typeName(t))
+	case "javascript":
+		return "var echo = function(value) {\n    return value;\n};"
+	default:
+		return "class Solution:\n    def echo(self, value):\n        return value"
+	}
+}
+
+// TestRoundTrip feeds the same inputs through every harness whose toolchain is
+// installed, with a solution returning its argument, and expects the same output
+func TestRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs harnesses")
+	}
+	tests := []struct {
+		typ, input, want string
+	}{
+		{
+			typ:   "double[]",
+			input: "[0.1, 1e21, 0.30000000000000004, 3.0, -0.0, 1e-7, 0.000001, 123456789012345680000, 5e-324, 1.7976931348623157e308, -2.5]",
+			want:  "[0.1,1e+21,0.30000000000000004,3,0,1e-7,0.000001,123456789012345680000,5e-324,1.7976931348623157e+308,-2.5]",
+		},
+		{typ: "double", input: "2.50", want: "2.5"},
+		{typ: "long[]", input: "[9007199254740993, -9223372036854775808, 9223372036854775807]", want: "[9007199254740993,-9223372036854775808,9223372036854775807]"},
+		{typ: "long", input: "-9007199254740993", want: "-9007199254740993"},
+		{typ: "int", input: " -7 ", want: "-7"},
+		{typ: "bool[]", input: "[true, false]", want: "[true,false]"},
+		{typ: "int[][]", input: "[[1, 2], [], [-3]]", want: "[[1,2],[],[-3]]"},
+		{
+			typ:   "string[][]",
+			input: `[["a\"b", "tab\there", "é😀", "<&>", "\u0001", "\/"], []]`,
+			want:  `[["a\"b","tab\there","é😀","<&>","\u0001","/"],[]]`,
+		},
+		{typ: "ListNode", input: "[1, 2, 3]", want: "[1,2,3]"},
+		{typ: "TreeNode", input: "[5,4,8,11,null,13,4,7,2,null,null,null,1]", want: "[5,4,8,11,null,13,4,7,2,null,null,null,1]"},
+		{typ: "TreeNode", input: "[]", want: "[]"},
+	}
+
+	for _, language := range Languages() {
+		tc := toolchains[language]
+		if _, err := exec.LookPath(append(tc.compile, tc.run...)[0]); err != nil {
+			t.Logf("skipping %s: %v", language, err)
+			continue
+		}
+		if language == "java" {
+			if _, err := exec.LookPath("java"); err != nil {
+				t.Logf("skipping %s: %v", language, err)
+				continue
+			}
+		}
+		for _, tt := range tests {
+			t.Run(language+"/"+tt.typ+"/"+tt.input, func(t *testing.T) {
+				t.Parallel()
+				typ, err := ParseType(tt.typ)
+				if err != nil {
+					t.Fatal(err)
+				}
+				spec := Spec{Function: "echo", Params: []Param{{Name: "value", Type: tt.typ}}, Returns: tt.typ}
+				h, err := spec.Harness(language)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				dir := t.TempDir()
+				for file, src := range h.Files(echoSolution(language, typ)) {
+					if err := os.WriteFile(filepath.Join(dir, file), []byte(src), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				command := func(args []string, stdin string) string {
+					cmd := exec.Command(args[0], args[1:]...)
+					cmd.Dir = dir
+					cmd.Env = append(os.Environ(), tc.env...)
+					cmd.Stdin = strings.NewReader(stdin)
+					var stderr bytes.Buffer
+					cmd.Stderr = &stderr
+					out, err := cmd.Output()
+					if err != nil {
+						t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+					}
+					return string(out)
+				}
+				if tc.compile != nil {
+					command(tc.compile, "")
+				}
+				if got := command(tc.run, tt.input+"\n"); got != tt.want+"\n" {
+					t.Errorf("echo(%s) printed %q, want %q", tt.input, got, tt.want+"\n")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,366 @@
+import java.io.*;
+import java.lang.reflect.Array;
+import java.math.BigDecimal;
+import java.math.MathContext;
+import java.math.RoundingMode;
+import java.nio.charset.StandardCharsets;
+import java.util.*;
+
+
+
+class ListNode {
+    int val;
+    ListNode next;
+    ListNode() {}
+    ListNode(int val) { this.val = val; }
+    ListNode(int val, ListNode next) { this.val = val; this.next = next; }
+}
+
+class TreeNode {
+    int val;
+    TreeNode left;
+    TreeNode right;
+    TreeNode() {}
+    TreeNode(int val) { this.val = val; }
+    TreeNode(int val, TreeNode left, TreeNode right) {
+        this.val = val;
+        this.left = left;
+        this.right = right;
+    }
+}
+
+public class Main {
+    // Json parses one JSON value into BigDecimal, String, Boolean, List<Object> or null
+    static class Json {
+        private final String s;
+        private int i;
+
+        Json(String s) {
+            this.s = s;
+        }
+
+        Object parse() {
+            Object value = value();
+            skipSpace();
+            if (i != s.length()) {
+                throw error();
+            }
+            return value;
+        }
+
+        private Object value() {
+            skipSpace();
+            if (i >= s.length()) {
+                throw error();
+            }
+            char c = s.charAt(i);
+            if (c == '[') {
+                i++;
+                List<Object> items = new ArrayList<>();
+                skipSpace();
+                if (i < s.length() && s.charAt(i) == ']') {
+                    i++;
+                    return items;
+                }
+                while (true) {
+                    items.add(value());
+                    skipSpace();
+                    if (i >= s.length()) {
+                        throw error();
+                    }
+                    char next = s.charAt(i++);
+                    if (next == ']') {
+                        return items;
+                    }
+                    if (next != ',') {
+                        throw error();
+                    }
+                }
+            }
+            if (c == '"') {
+                return string();
+            }
+            if (s.startsWith("true", i)) {
+                i += 4;
+                return Boolean.TRUE;
+            }
+            if (s.startsWith("false", i)) {
+                i += 5;
+                return Boolean.FALSE;
+            }
+            if (s.startsWith("null", i)) {
+                i += 4;
+                return null;
+            }
+            int start = i;
+            while (i < s.length() && "+-.eE0123456789".indexOf(s.charAt(i)) >= 0) {
+                i++;
+            }
+            if (start == i) {
+                throw error();
+            }
+            return new BigDecimal(s.substring(start, i));
+        }
+
+        private String string() {
+            StringBuilder out = new StringBuilder();
+            i++;
+            while (true) {
+                if (i >= s.length()) {
+                    throw error();
+                }
+                char c = s.charAt(i++);
+                if (c == '"') {
+                    return out.toString();
+                }
+                if (c != '\\') {
+                    out.append(c);
+                    continue;
+                }
+                char e = s.charAt(i++);
+                switch (e) {
+                    case 'n': out.append('\n'); break;
+                    case 't': out.append('\t'); break;
+                    case 'r': out.append('\r'); break;
+                    case 'b': out.append('\b'); break;
+                    case 'f': out.append('\f'); break;
+                    case 'u':
+                        out.append((char) Integer.parseInt(s.substring(i, i + 4), 16));
+                        i += 4;
+                        break;
+                    default: out.append(e);
+                }
+            }
+        }
+
+        private void skipSpace() {
+            while (i < s.length() && Character.isWhitespace(s.charAt(i))) {
+                i++;
+            }
+        }
+
+        private IllegalArgumentException error() {
+            return new IllegalArgumentException("invalid JSON input: " + s);
+        }
+    }
+
+    static Class<?> classFor(String kind) {
+        if (kind.endsWith("[]")) {
+            return Array.newInstance(classFor(kind.substring(0, kind.length() - 2)), 0).getClass();
+        }
+        switch (kind) {
+            case "int": return int.class;
+            case "long": return long.class;
+            case "double": return double.class;
+            case "bool": return boolean.class;
+            case "string": return String.class;
+            case "ListNode": return ListNode.class;
+            case "TreeNode": return TreeNode.class;
+            default: throw new IllegalArgumentException("unsupported type " + kind);
+        }
+    }
+
+    @SuppressWarnings("unchecked")
+    static Object convert(Object value, String kind) {
+        if (kind.endsWith("[]")) {
+            String elem = kind.substring(0, kind.length() - 2);
+            List<Object> items = (List<Object>) value;
+            Object array = Array.newInstance(classFor(elem), items.size());
+            for (int i = 0; i < items.size(); i++) {
+                Array.set(array, i, convert(items.get(i), elem));
+            }
+            return array;
+        }
+        switch (kind) {
+            case "int": return ((BigDecimal) value).intValueExact();
+            case "long": return ((BigDecimal) value).longValueExact();
+            case "double": return ((BigDecimal) value).doubleValue();
+            case "bool": return (Boolean) value;
+            case "string": return (String) value;
+            case "ListNode": {
+                ListNode head = new ListNode();
+                ListNode tail = head;
+                for (Object item : (List<Object>) value) {
+                    tail.next = new ListNode(((BigDecimal) item).intValueExact());
+                    tail = tail.next;
+                }
+                return head.next;
+            }
+            case "TreeNode": {
+                List<Object> items = (List<Object>) value;
+                if (items.isEmpty() || items.get(0) == null) {
+                    return null;
+                }
+                TreeNode root = new TreeNode(((BigDecimal) items.get(0)).intValueExact());
+                List<TreeNode> queue = new ArrayList<>();
+                queue.add(root);
+                int i = 1;
+                for (int q = 0; q < queue.size() && i < items.size(); q++) {
+                    TreeNode node = queue.get(q);
+                    if (items.get(i) != null) {
+                        node.left = new TreeNode(((BigDecimal) items.get(i)).intValueExact());
+                        queue.add(node.left);
+                    }
+                    i++;
+                    if (i < items.size() && items.get(i) != null) {
+                        node.right = new TreeNode(((BigDecimal) items.get(i)).intValueExact());
+                        queue.add(node.right);
+                    }
+                    i++;
+                }
+                return root;
+            }
+            default: throw new IllegalArgumentException("unsupported type " + kind);
+        }
+    }
+
+    static void serialize(Object value, String kind, StringBuilder out) {
+        if (kind.endsWith("[]")) {
+            String elem = kind.substring(0, kind.length() - 2);
+            out.append('[');
+            int n = value == null ? 0 : Array.getLength(value);
+            for (int i = 0; i < n; i++) {
+                if (i > 0) {
+                    out.append(',');
+                }
+                serialize(Array.get(value, i), elem, out);
+            }
+            out.append(']');
+            return;
+        }
+        switch (kind) {
+            case "string":
+                if (value == null) {
+                    out.append("null");
+                } else {
+                    quote((String) value, out);
+                }
+                return;
+            case "ListNode": {
+                out.append('[');
+                for (ListNode node = (ListNode) value; node != null; node = node.next) {
+                    if (node != value) {
+                        out.append(',');
+                    }
+                    out.append(node.val);
+                }
+                out.append(']');
+                return;
+            }
+            case "TreeNode": {
+                List<String> values = new ArrayList<>();
+                List<TreeNode> queue = new ArrayList<>();
+                queue.add((TreeNode) value);
+                for (int q = 0; q < queue.size(); q++) {
+                    TreeNode node = queue.get(q);
+                    if (node == null) {
+                        values.add("null");
+                        continue;
+                    }
+                    values.add(String.valueOf(node.val));
+                    queue.add(node.left);
+                    queue.add(node.right);
+                }
+                while (!values.isEmpty() && values.get(values.size() - 1).equals("null")) {
+                    values.remove(values.size() - 1);
+                }
+                out.append('[').append(String.join(",", values)).append(']');
+                return;
+            }
+            case "double":
+                formatDouble((Double) value, out);
+                return;
+            default:
+                out.append(value);
+        }
+    }
+
+    // formatDouble writes the shortest digits that read back as v, laid out as
+    // JavaScript's Number.prototype.toString does, so every harness agrees
+    static void formatDouble(double v, StringBuilder out) {
+        if (Double.isNaN(v) || Double.isInfinite(v)) {
+            out.append("null");
+            return;
+        }
+        if (v == 0) {
+            out.append('0');
+            return;
+        }
+        if (v < 0) {
+            out.append('-');
+            v = -v;
+        }
+        // The closest decimal of each length is tried, as Double.toString is
+        // not always the shortest before Java 19
+        BigDecimal exact = new BigDecimal(v);
+        BigDecimal d = exact;
+        for (int precision = 1; precision <= 17; precision++) {
+            d = exact.round(new MathContext(precision, RoundingMode.HALF_EVEN));
+            if (d.doubleValue() == v) {
+                break;
+            }
+        }
+        d = d.stripTrailingZeros();
+        String digits = d.unscaledValue().toString();
+        int k = digits.length();
+        int n = k - d.scale();
+        if (k <= n && n <= 21) {
+            out.append(digits);
+            for (int i = k; i < n; i++) {
+                out.append('0');
+            }
+        } else if (0 < n && n <= 21) {
+            out.append(digits, 0, n).append('.').append(digits, n, k);
+        } else if (-6 < n && n <= 0) {
+            out.append("0.");
+            for (int i = n; i < 0; i++) {
+                out.append('0');
+            }
+            out.append(digits);
+        } else {
+            out.append(digits.charAt(0));
+            if (k > 1) {
+                out.append('.').append(digits, 1, k);
+            }
+            out.append('e').append(n > 0 ? '+' : '-').append(Math.abs(n - 1));
+        }
+    }
+
+    static void quote(String s, StringBuilder out) {
+        out.append('"');
+        for (int i = 0; i < s.length(); i++) {
+            char c = s.charAt(i);
+            switch (c) {
+                case '"': out.append("\\\""); break;
+                case '\\': out.append("\\\\"); break;
+                case '\n': out.append("\\n"); break;
+                case '\r': out.append("\\r"); break;
+                case '\t': out.append("\\t"); break;
+                default:
+                    if (c < 0x20) {
+                        out.append(String.format("\\u%04x", (int) c));
+                    } else {
+                        out.append(c);
+                    }
+            }
+        }
+        out.append('"');
+    }
+
+    public static void main(String[] args) throws Exception {
+        BufferedReader in = new BufferedReader(new InputStreamReader(System.in, StandardCharsets.UTF_8));
+        List<String> lines = new ArrayList<>();
+        for (String line = in.readLine(); line != null; line = in.readLine()) {
+            lines.add(line);
+        }
+        if (lines.size() < 1) {
+            throw new IllegalArgumentException("expected 1 input lines, got " + lines.size());
+        }
+        TreeNode argroot = (TreeNode) convert(new Json(lines.get(0)).parse(), "TreeNode");
+        int[][] result = new Solution().levelOrder(argroot);
+        StringBuilder out = new StringBuilder();
+        serialize(result, "int[][]", out);
+        PrintStream stdout = new PrintStream(new FileOutputStream(FileDescriptor.out), true, "UTF-8");
+        stdout.println(out);
+    }
+}
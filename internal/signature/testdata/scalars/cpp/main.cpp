@@ -0,0 +1,385 @@
+#include <bits/stdc++.h>
+using namespace std;
+
+struct ListNode {
+    int val;
+    ListNode *next;
+    ListNode() : val(0), next(nullptr) {}
+    ListNode(int x) : val(x), next(nullptr) {}
+    ListNode(int x, ListNode *next) : val(x), next(next) {}
+};
+
+struct TreeNode {
+    int val;
+    TreeNode *left;
+    TreeNode *right;
+    TreeNode() : val(0), left(nullptr), right(nullptr) {}
+    TreeNode(int x) : val(x), left(nullptr), right(nullptr) {}
+    TreeNode(int x, TreeNode *left, TreeNode *right) : val(x), left(left), right(right) {}
+};
+
+
+
+namespace judge_harness {
+
+struct Json {
+    enum Kind { Null, Bool, Number, String, Array } kind = Null;
+    bool boolean = false;
+    // text is the literal of a number or the value of a string
+    string text;
+    vector<Json> items;
+};
+
+struct Parser {
+    const string& s;
+    size_t i = 0;
+
+    explicit Parser(const string& s) : s(s) {}
+
+    [[noreturn]] void fail() { throw runtime_error("invalid JSON input: " + s); }
+
+    void skipSpace() {
+        while (i < s.size() && isspace((unsigned char)s[i])) {
+            i++;
+        }
+    }
+
+    Json value() {
+        skipSpace();
+        if (i >= s.size()) {
+            fail();
+        }
+        Json j;
+        char c = s[i];
+        if (c == '[') {
+            j.kind = Json::Array;
+            i++;
+            skipSpace();
+            if (i < s.size() && s[i] == ']') {
+                i++;
+                return j;
+            }
+            while (true) {
+                j.items.push_back(value());
+                skipSpace();
+                if (i >= s.size()) {
+                    fail();
+                }
+                char next = s[i++];
+                if (next == ']') {
+                    return j;
+                }
+                if (next != ',') {
+                    fail();
+                }
+            }
+        }
+        if (c == '"') {
+            j.kind = Json::String;
+            j.text = str();
+            return j;
+        }
+        if (s.compare(i, 4, "true") == 0) {
+            i += 4;
+            j.kind = Json::Bool;
+            j.boolean = true;
+            return j;
+        }
+        if (s.compare(i, 5, "false") == 0) {
+            i += 5;
+            j.kind = Json::Bool;
+            return j;
+        }
+        if (s.compare(i, 4, "null") == 0) {
+            i += 4;
+            return j;
+        }
+        size_t start = i;
+        while (i < s.size() && string("+-.eE0123456789").find(s[i]) != string::npos) {
+            i++;
+        }
+        if (start == i) {
+            fail();
+        }
+        j.kind = Json::Number;
+        j.text = s.substr(start, i - start);
+        return j;
+    }
+
+    string str() {
+        string out;
+        i++;
+        while (true) {
+            if (i >= s.size()) {
+                fail();
+            }
+            char c = s[i++];
+            if (c == '"') {
+                return out;
+            }
+            if (c != '\\') {
+                out += c;
+                continue;
+            }
+            if (i >= s.size()) {
+                fail();
+            }
+            char e = s[i++];
+            switch (e) {
+            case 'n': out += '\n'; break;
+            case 't': out += '\t'; break;
+            case 'r': out += '\r'; break;
+            case 'b': out += '\b'; break;
+            case 'f': out += '\f'; break;
+            case 'u': {
+                unsigned cp = hex4();
+                if (cp >= 0xD800 && cp < 0xDC00 && s.compare(i, 2, "\\u") == 0) {
+                    i += 2;
+                    cp = 0x10000 + ((cp - 0xD800) << 10) + (hex4() - 0xDC00);
+                }
+                utf8(out, cp);
+                break;
+            }
+            default: out += e;
+            }
+        }
+    }
+
+    unsigned hex4() {
+        if (i + 4 > s.size()) {
+            fail();
+        }
+        unsigned cp = stoul(s.substr(i, 4), nullptr, 16);
+        i += 4;
+        return cp;
+    }
+
+    static void utf8(string& out, unsigned cp) {
+        if (cp < 0x80) {
+            out += (char)cp;
+        } else if (cp < 0x800) {
+            out += (char)(0xC0 | (cp >> 6));
+            out += (char)(0x80 | (cp & 0x3F));
+        } else if (cp < 0x10000) {
+            out += (char)(0xE0 | (cp >> 12));
+            out += (char)(0x80 | ((cp >> 6) & 0x3F));
+            out += (char)(0x80 | (cp & 0x3F));
+        } else {
+            out += (char)(0xF0 | (cp >> 18));
+            out += (char)(0x80 | ((cp >> 12) & 0x3F));
+            out += (char)(0x80 | ((cp >> 6) & 0x3F));
+            out += (char)(0x80 | (cp & 0x3F));
+        }
+    }
+};
+
+Json parse(const string& line) {
+    Parser p(line);
+    Json j = p.value();
+    p.skipSpace();
+    if (p.i != line.size()) {
+        p.fail();
+    }
+    return j;
+}
+
+void convert(const Json& j, int& v) { v = stoi(j.text); }
+void convert(const Json& j, long long& v) { v = stoll(j.text); }
+// strtod rather than stod, which rejects subnormal values as out of range
+void convert(const Json& j, double& v) { v = strtod(j.text.c_str(), nullptr); }
+void convert(const Json& j, bool& v) { v = j.boolean; }
+void convert(const Json& j, string& v) { v = j.text; }
+
+void convert(const Json& j, ListNode*& v) {
+    ListNode head;
+    ListNode* tail = &head;
+    for (const Json& item : j.items) {
+        tail->next = new ListNode(stoi(item.text));
+        tail = tail->next;
+    }
+    v = head.next;
+}
+
+void convert(const Json& j, TreeNode*& v) {
+    v = nullptr;
+    if (j.items.empty() || j.items[0].kind == Json::Null) {
+        return;
+    }
+    v = new TreeNode(stoi(j.items[0].text));
+    vector<TreeNode*> queue{v};
+    size_t i = 1;
+    for (size_t q = 0; q < queue.size() && i < j.items.size(); q++) {
+        TreeNode* node = queue[q];
+        if (j.items[i].kind != Json::Null) {
+            node->left = new TreeNode(stoi(j.items[i].text));
+            queue.push_back(node->left);
+        }
+        i++;
+        if (i < j.items.size() && j.items[i].kind != Json::Null) {
+            node->right = new TreeNode(stoi(j.items[i].text));
+            queue.push_back(node->right);
+        }
+        i++;
+    }
+}
+
+template <typename T>
+void convert(const Json& j, vector<T>& v) {
+    v.clear();
+    for (const Json& item : j.items) {
+        // Goes through a T so that vector<bool> works too
+        T elem{};
+        convert(item, elem);
+        v.push_back(elem);
+    }
+}
+
+void serialize(ostream& out, int v) { out << v; }
+void serialize(ostream& out, long long v) { out << v; }
+void serialize(ostream& out, bool v) { out << (v ? "true" : "false"); }
+
+// Writes the shortest digits that read back as v, laid out as JavaScript's
+// Number.prototype.toString does, so every harness agrees
+void serialize(ostream& out, double v) {
+    if (!isfinite(v)) {
+        out << "null";
+        return;
+    }
+    if (v == 0) {
+        out << '0';
+        return;
+    }
+    if (v < 0) {
+        out << '-';
+        v = -v;
+    }
+    char buf[32];
+    // Scientific notation gives the shortest digits and the exponent
+    string s(buf, to_chars(buf, buf + sizeof buf, v, chars_format::scientific).ptr);
+    size_t e = s.find('e');
+    int n = stoi(s.substr(e + 1)) + 1;
+    string digits;
+    for (char c : s.substr(0, e)) {
+        if (c != '.') {
+            digits += c;
+        }
+    }
+    int k = digits.size();
+    if (k <= n && n <= 21) {
+        out << digits << string(n - k, '0');
+    } else if (0 < n && n <= 21) {
+        out << digits.substr(0, n) << '.' << digits.substr(n);
+    } else if (-6 < n && n <= 0) {
+        out << "0." << string(-n, '0') << digits;
+    } else {
+        out << digits[0];
+        if (k > 1) {
+            out << '.' << digits.substr(1);
+        }
+        out << 'e' << (n > 0 ? '+' : '-') << abs(n - 1);
+    }
+}
+
+void serialize(ostream& out, const string& v) {
+    out << '"';
+    for (unsigned char c : v) {
+        switch (c) {
+        case '"': out << "\\\""; break;
+        case '\\': out << "\\\\"; break;
+        case '\n': out << "\\n"; break;
+        case '\r': out << "\\r"; break;
+        case '\t': out << "\\t"; break;
+        default:
+            if (c < 0x20) {
+                char buf[8];
+                snprintf(buf, sizeof buf, "\\u%04x", c);
+                out << buf;
+            } else {
+                out << (char)c;
+            }
+        }
+    }
+    out << '"';
+}
+
+void serialize(ostream& out, ListNode* v) {
+    out << '[';
+    for (ListNode* node = v; node; node = node->next) {
+        if (node != v) {
+            out << ',';
+        }
+        out << node->val;
+    }
+    out << ']';
+}
+
+void serialize(ostream& out, TreeNode* v) {
+    vector<string> values;
+    vector<TreeNode*> queue{v};
+    for (size_t q = 0; q < queue.size(); q++) {
+        TreeNode* node = queue[q];
+        if (!node) {
+            values.push_back("null");
+            continue;
+        }
+        values.push_back(to_string(node->val));
+        queue.push_back(node->left);
+        queue.push_back(node->right);
+    }
+    while (!values.empty() && values.back() == "null") {
+        values.pop_back();
+    }
+    out << '[';
+    for (size_t i = 0; i < values.size(); i++) {
+        if (i > 0) {
+            out << ',';
+        }
+        out << values[i];
+    }
+    out << ']';
+}
+
+template <typename T>
+void serialize(ostream& out, const vector<T>& v) {
+    out << '[';
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i > 0) {
+            out << ',';
+        }
+        const T& elem = v[i];
+        serialize(out, elem);
+    }
+    out << ']';
+}
+
+}  // namespace judge_harness
+
+int main() {
+    ios::sync_with_stdio(false);
+    vector<string> lines;
+    for (string line; getline(cin, line);) {
+        if (!line.empty() && line.back() == '\r') {
+            line.pop_back();
+        }
+        lines.push_back(line);
+    }
+    if (lines.size() < 5) {
+        cerr << "expected 5 input lines, got " << lines.size() << endl;
+        return 1;
+    }
+    long long arg_count{};
+    judge_harness::convert(judge_harness::parse(lines[0]), arg_count);
+    double arg_ratio{};
+    judge_harness::convert(judge_harness::parse(lines[1]), arg_ratio);
+    bool arg_flag{};
+    judge_harness::convert(judge_harness::parse(lines[2]), arg_flag);
+    string arg_name{};
+    judge_harness::convert(judge_harness::parse(lines[3]), arg_name);
+    vector<vector<string>> arg_grid{};
+    judge_harness::convert(judge_harness::parse(lines[4]), arg_grid);
+    Solution solution;
+    vector<double> result = solution.describe(arg_count, arg_ratio, arg_flag, arg_name, arg_grid);
+    judge_harness::serialize(cout, result);
+    cout << '\n';
+    return 0;
+}
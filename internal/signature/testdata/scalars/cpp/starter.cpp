@@ -0,0 +1,6 @@
+class Solution {
+public:
+    vector<double> describe(long long count, double ratio, bool flag, string name, vector<vector<string>>& grid) {
+        
+    }
+};
@@ -0,0 +1,5 @@
+package main
+
+func describe(count int64, ratio float64, flag bool, name string, grid [][]string) []float64 {
+	
+}
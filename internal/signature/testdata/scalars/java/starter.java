@@ -0,0 +1,5 @@
+class Solution {
+    public double[] describe(long count, double ratio, boolean flag, String name, String[][] grid) {
+        
+    }
+}
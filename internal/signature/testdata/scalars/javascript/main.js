@@ -0,0 +1,117 @@
+"use strict";
+
+function ListNode(val, next) {
+    this.val = (val === undefined ? 0 : val);
+    this.next = (next === undefined ? null : next);
+}
+
+function TreeNode(val, left, right) {
+    this.val = (val === undefined ? 0 : val);
+    this.left = (left === undefined ? null : left);
+    this.right = (right === undefined ? null : right);
+}
+
+
+
+// Longs are BigInts, as numbers lose precision past 2^53, so their digits are
+// kept as strings until __decode converts them
+function __parse(line, kind) {
+    if (kind.replace(/(\[\])+$/, "") === "long") {
+        line = line.replace(/-?\d[\d.eE+-]*/g, '"$&"');
+    }
+    return JSON.parse(line);
+}
+
+function __decode(value, kind) {
+    if (kind.endsWith("[]")) {
+        return value.map((v) => __decode(v, kind.slice(0, -2)));
+    }
+    if (kind === "long") {
+        return BigInt(value);
+    }
+    if (kind === "ListNode") {
+        const head = new ListNode();
+        let tail = head;
+        for (const v of value) {
+            tail.next = new ListNode(v);
+            tail = tail.next;
+        }
+        return head.next;
+    }
+    if (kind === "TreeNode") {
+        if (value.length === 0 || value[0] === null) {
+            return null;
+        }
+        const root = new TreeNode(value[0]);
+        const queue = [root];
+        let i = 1;
+        for (let q = 0; q < queue.length && i < value.length; q++) {
+            const node = queue[q];
+            if (value[i] !== null) {
+                node.left = new TreeNode(value[i]);
+                queue.push(node.left);
+            }
+            i++;
+            if (i < value.length && value[i] !== null) {
+                node.right = new TreeNode(value[i]);
+                queue.push(node.right);
+            }
+            i++;
+        }
+        return root;
+    }
+    return value;
+}
+
+function __encode(value, kind) {
+    if (kind.endsWith("[]")) {
+        return (value || []).map((v) => __encode(v, kind.slice(0, -2)));
+    }
+    if (kind === "ListNode") {
+        const out = [];
+        for (let node = value; node; node = node.next) {
+            out.push(node.val);
+        }
+        return out;
+    }
+    if (kind === "TreeNode") {
+        const out = [];
+        const queue = [value];
+        for (let q = 0; q < queue.length; q++) {
+            const node = queue[q];
+            if (!node) {
+                out.push(null);
+                continue;
+            }
+            out.push(node.val);
+            queue.push(node.left, node.right);
+        }
+        while (out.length > 0 && out[out.length - 1] === null) {
+            out.pop();
+        }
+        return out;
+    }
+    return value;
+}
+
+// __stringify writes a result as JSON, which JSON.stringify cannot do for BigInts
+function __stringify(value, kind) {
+    if (kind.endsWith("[]")) {
+        return "[" + (value || []).map((v) => __stringify(v, kind.slice(0, -2))).join(",") + "]";
+    }
+    if (kind === "long") {
+        return BigInt(value).toString();
+    }
+    return JSON.stringify(__encode(value, kind));
+}
+
+(function main() {
+    const lines = require("fs").readFileSync(0, "utf8").split(/\r?\n/);
+    const kinds = ["long", "double", "bool", "string", "string[][]"];
+    if (lines.length < kinds.length) {
+        throw new Error("expected " + kinds.length + " input lines, got " + lines.length);
+    }
+    const args = kinds.map((kind, i) => __decode(__parse(lines[i], kind), kind));
+    const result = describe(...args);
+    process.stdout.write(__stringify(result, "double[]") + "\n");
+})();
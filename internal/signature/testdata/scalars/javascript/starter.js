@@ -0,0 +1,11 @@
+/**
+ * @param {bigint} count
+ * @param {number} ratio
+ * @param {boolean} flag
+ * @param {string} name
+ * @param {string[][]} grid
+ * @return {number[]}
+ */
+var describe = function(count, ratio, flag, name, grid) {
+    
+};
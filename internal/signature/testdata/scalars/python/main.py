@@ -0,0 +1,121 @@
+import json
+import sys
+from typing import *
+
+
+class ListNode:
+    def __init__(self, val=0, next=None):
+        self.val = val
+        self.next = next
+
+
+class TreeNode:
+    def __init__(self, val=0, left=None, right=None):
+        self.val = val
+        self.left = left
+        self.right = right
+
+
+
+
+import decimal as _decimal
+
+def _decode(value, kind):
+    if kind.endswith("[]"):
+        return [_decode(v, kind[:-2]) for v in value]
+    if kind == "ListNode":
+        head = tail = ListNode()
+        for v in value:
+            tail.next = ListNode(v)
+            tail = tail.next
+        return head.next
+    if kind == "TreeNode":
+        if not value or value[0] is None:
+            return None
+        root = TreeNode(value[0])
+        queue, i = [root], 1
+        for node in queue:
+            if i >= len(value):
+                break
+            if value[i] is not None:
+                node.left = TreeNode(value[i])
+                queue.append(node.left)
+            i += 1
+            if i < len(value) and value[i] is not None:
+                node.right = TreeNode(value[i])
+                queue.append(node.right)
+            i += 1
+        return root
+    if kind == "double":
+        return float(value)
+    return value
+
+
+def _encode(value, kind):
+    if kind.endswith("[]"):
+        return [_encode(v, kind[:-2]) for v in (value or [])]
+    if kind == "ListNode":
+        out = []
+        while value is not None:
+            out.append(value.val)
+            value = value.next
+        return out
+    if kind == "TreeNode":
+        out, queue = [], [value]
+        for node in queue:
+            if node is None:
+                out.append(None)
+            else:
+                out.append(node.val)
+                queue.append(node.left)
+                queue.append(node.right)
+        while out and out[-1] is None:
+            out.pop()
+        return out
+    return value
+
+
+def _dumps(value, kind):
+    if kind.endswith("[]"):
+        return "[" + ",".join(_dumps(v, kind[:-2]) for v in (value or [])) + "]"
+    if kind == "double":
+        return _format_double(value)
+    return json.dumps(value, separators=(",", ":"), ensure_ascii=False)
+
+
+# _format_double writes the shortest digits that read back as x, laid out as
+# JavaScript's Number.prototype.toString does, so every harness agrees
+def _format_double(x):
+    x = float(x)
+    if x != x or x in (float("inf"), float("-inf")):
+        return "null"
+    if x == 0:
+        return "0"
+    sign, digits, exponent = _decimal.Decimal(repr(x)).normalize().as_tuple()
+    digits = "".join(map(str, digits))
+    k, n = len(digits), len(digits) + exponent
+    if k <= n <= 21:
+        out = digits + "0" * (n - k)
+    elif 0 < n <= 21:
+        out = digits[:n] + "." + digits[n:]
+    elif -6 < n <= 0:
+        out = "0." + "0" * -n + digits
+    else:
+        out = digits[0] + ("." + digits[1:] if k > 1 else "") + "e%+d" % (n - 1)
+    return ("-" if sign else "") + out
+
+
+def _main():
+    lines = sys.stdin.buffer.read().decode("utf-8").splitlines()
+    kinds = ["long", "double", "bool", "string", "string[][]"]
+    if len(lines) < len(kinds):
+        raise ValueError("expected %d input lines, got %d" % (len(kinds), len(lines)))
+    args = [_decode(json.loads(lines[i]), kind) for i, kind in enumerate(kinds)]
+    result = Solution().describe(*args)
+    kind = "double[]"
+    out = _dumps(_encode(result, kind), kind)
+    sys.stdout.buffer.write((out + "\n").encode("utf-8"))
+
+
+if __name__ == "__main__":
+    _main()
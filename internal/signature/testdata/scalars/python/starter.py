@@ -0,0 +1,3 @@
+class Solution:
+    def describe(self, count: int, ratio: float, flag: bool, name: str, grid: List[List[str]]) -> List[float]:
+        pass
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+)
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+
+var (
+	harnessListNodeType = reflect.TypeOf((*ListNode)(nil))
+	harnessTreeNodeType = reflect.TypeOf((*TreeNode)(nil))
+)
+
+func harnessReadLines() []string {
+	data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+	if err != nil {
+		panic(err)
+	}
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
+
+// harnessDecode parses one JSON input line into dst
+func harnessDecode(line string, dst interface{}) {
+	d := json.NewDecoder(strings.NewReader(line))
+	d.UseNumber()
+	var raw interface{}
+	if err := d.Decode(&raw); err != nil {
+		panic(err)
+	}
+	harnessAssign(reflect.ValueOf(dst).Elem(), raw)
+}
+
+func harnessAssign(v reflect.Value, raw interface{}) {
+	switch v.Type() {
+	case harnessListNodeType:
+		head := &ListNode{}
+		tail := head
+		for _, item := range raw.([]interface{}) {
+			tail.Next = &ListNode{Val: harnessInt(item)}
+			tail = tail.Next
+		}
+		v.Set(reflect.ValueOf(head.Next))
+		return
+	case harnessTreeNodeType:
+		v.Set(reflect.ValueOf(harnessBuildTree(raw.([]interface{}))))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		items := raw.([]interface{})
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			harnessAssign(s.Index(i), item)
+		}
+		v.Set(s)
+	case reflect.Int, reflect.Int64:
+		n, err := raw.(json.Number).Int64()
+		if err != nil {
+			panic(err)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := raw.(json.Number).Float64()
+		if err != nil {
+			panic(err)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		v.SetBool(raw.(bool))
+	case reflect.String:
+		v.SetString(raw.(string))
+	default:
+		panic("unsupported type " + v.Type().String())
+	}
+}
+
+func harnessInt(raw interface{}) int {
+	n, err := raw.(json.Number).Int64()
+	if err != nil {
+		panic(err)
+	}
+	return int(n)
+}
+
+func harnessBuildTree(values []interface{}) *TreeNode {
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	root := &TreeNode{Val: harnessInt(values[0])}
+	queue := []*TreeNode{root}
+	i := 1
+	for q := 0; q < len(queue) && i < len(values); q++ {
+		node := queue[q]
+		if values[i] != nil {
+			node.Left = &TreeNode{Val: harnessInt(values[i])}
+			queue = append(queue, node.Left)
+		}
+		i++
+		if i < len(values) && values[i] != nil {
+			node.Right = &TreeNode{Val: harnessInt(values[i])}
+			queue = append(queue, node.Right)
+		}
+		i++
+	}
+	return root
+}
+
+// harnessEncode returns the compact JSON form of a result
+func harnessEncode(result interface{}) string {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(harnessPlain(reflect.ValueOf(result))); err != nil {
+		panic(err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// harnessPlain converts linked lists and trees to arrays, nil slices to empty
+// ones and floats JSON cannot hold to null
+func harnessPlain(v reflect.Value) interface{} {
+	switch v.Type() {
+	case harnessListNodeType:
+		out := []int{}
+		for node := v.Interface().(*ListNode); node != nil; node = node.Next {
+			out = append(out, node.Val)
+		}
+		return out
+	case harnessTreeNodeType:
+		out := []interface{}{}
+		queue := []*TreeNode{v.Interface().(*TreeNode)}
+		for q := 0; q < len(queue); q++ {
+			node := queue[q]
+			if node == nil {
+				out = append(out, nil)
+				continue
+			}
+			out = append(out, node.Val)
+			queue = append(queue, node.Left, node.Right)
+		}
+		for len(out) > 0 && out[len(out)-1] == nil {
+			out = out[:len(out)-1]
+		}
+		return out
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = harnessPlain(v.Index(i))
+		}
+		return out
+	case reflect.Float64:
+		// encoding/json writes floats as JavaScript does, except that it rejects
+		// NaN and infinities and keeps the sign of -0
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		if f == 0 {
+			return 0
+		}
+	}
+	return v.Interface()
+}
+
+func main() {
+	lines := harnessReadLines()
+	if len(lines) < 2 {
+		panic(fmt.Sprintf("expected 2 input lines, got %d", len(lines)))
+	}
+	var argnums []int
+	harnessDecode(lines[0], &argnums)
+	var argtarget int
+	harnessDecode(lines[1], &argtarget)
+	var result []int = twoSum(argnums, argtarget)
+	fmt.Println(harnessEncode(result))
+}
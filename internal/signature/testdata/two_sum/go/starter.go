@@ -0,0 +1,5 @@
+package main
+
+func twoSum(nums []int, target int) []int {
+	
+}
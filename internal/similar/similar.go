@@ -0,0 +1,208 @@
+// Package similar precomputes "similar questions" recommendations. Two
+// questions are similar when they share topics, have close ratings and are
+// solved by the same users; each question keeps its MaxSimilar best matches.
+package similar
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codestandoff/backend/internal/database"
+)
+
+const (
+	// MaxSimilar is how many similar questions are stored per question
+	MaxSimilar = 20
+
+	// Weights of the score components, which are each between 0 and 1
+	TopicWeight    = 0.5
+	CoSolveWeight  = 0.3
+	DistanceWeight = 0.2
+
+	// RatingScale is the rating gap at which rating proximity falls to 1/e
+	RatingScale = 400.0
+
+	// CoSolveShrink damps co-solve similarity between rarely solved questions,
+	// where a single shared user would otherwise count for a lot
+	CoSolveShrink = 5.0
+
+	// ancestorWeight is how much a parent topic counts for compared to the topic itself
+	ancestorWeight = 0.5
+)
+
+// Question is a question as seen by the scorer. Topics maps topic features to
+// their weight; see TopicFeatures.
+type Question struct {
+	ID         int
+	Rating     int
+	Topics     map[string]float64
+	SolveCount int
+}
+
+// Match is a question similar to another, with its score
+type Match struct {
+	ID    int
+	Score float64
+}
+
+// TopicFeatures turns a question's topics into weighted features. Topics in
+// the catalog count under their ID, with their ancestors at a lower weight so
+// sibling topics overlap; unknown topics count under their lower-case name.
+func TopicFeatures(catalog *database.TopicCatalog, topics []string) map[string]float64 {
+	features := map[string]float64{}
+	add := func(key string, weight float64) {
+		if weight > features[key] {
+			features[key] = weight
+		}
+	}
+
+	for _, name := range topics {
+		t, ok := catalog.Lookup(name)
+		if !ok {
+			add("name:"+strings.ToLower(strings.TrimSpace(name)), 1)
+			continue
+		}
+		weight := 1.0
+		for _, a := range catalog.Ancestors(t) {
+			add("topic:"+strconv.Itoa(a.ID), weight)
+			weight *= ancestorWeight
+		}
+	}
+
+	return features
+}
+
+// TopicSimilarity is the weighted Jaccard similarity of two topic feature sets
+func TopicSimilarity(a, b map[string]float64) float64 {
+	var shared, total float64
+	for key, wa := range a {
+		wb := b[key]
+		shared += math.Min(wa, wb)
+		total += math.Max(wa, wb)
+	}
+	for key, wb := range b {
+		if _, ok := a[key]; !ok {
+			total += wb
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return shared / total
+}
+
+// CoSolveSimilarity is the cosine similarity of the sets of users who solved
+// two questions, shrunk towards 0 when few users solved them
+func CoSolveSimilarity(users, solvesA, solvesB int) float64 {
+	if users == 0 {
+		return 0
+	}
+	return float64(users) / (math.Sqrt(float64(solvesA)*float64(solvesB)) + CoSolveShrink)
+}
+
+// RatingProximity is 1 for equal ratings and decays with the rating gap
+func RatingProximity(a, b int) float64 {
+	return math.Exp(-math.Abs(float64(a-b)) / RatingScale)
+}
+
+// Score rates how similar two questions are, given how many users solved both.
+// Questions with neither topics nor solvers in common score 0, however close
+// their ratings.
+func Score(a, b *Question, coSolvers int) float64 {
+	topics := TopicSimilarity(a.Topics, b.Topics)
+	coSolves := CoSolveSimilarity(coSolvers, a.SolveCount, b.SolveCount)
+	if topics == 0 && coSolves == 0 {
+		return 0
+	}
+	return TopicWeight*topics + CoSolveWeight*coSolves + DistanceWeight*RatingProximity(a.Rating, b.Rating)
+}
+
+// Rank returns up to limit matches for every question, best first. Ties go to
+// the lower ID. coSolves is keyed by question ID pair, lower ID first.
+func Rank(questions []*Question, coSolves map[[2]int]int, limit int) map[int][]Match {
+	matches := make(map[int][]Match, len(questions))
+	for i, a := range questions {
+		for _, b := range questions[i+1:] {
+			key := [2]int{a.ID, b.ID}
+			if b.ID < a.ID {
+				key = [2]int{b.ID, a.ID}
+			}
+			score := Score(a, b, coSolves[key])
+			if score <= 0 {
+				continue
+			}
+			matches[a.ID] = append(matches[a.ID], Match{ID: b.ID, Score: score})
+			matches[b.ID] = append(matches[b.ID], Match{ID: a.ID, Score: score})
+		}
+	}
+
+	for id, list := range matches {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].ID < list[j].ID
+		})
+		if len(list) > limit {
+			list = list[:limit]
+		}
+		matches[id] = list
+	}
+
+	return matches
+}
+
+// RecomputeSimilarQuestions scores every pair of published questions and
+// replaces the stored recommendations. It returns the number of questions
+// with at least one similar question.
+func RecomputeSimilarQuestions(db *sql.DB) (int, error) {
+	rows, err := database.GetSimilarityQuestions(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load questions: %w", err)
+	}
+	catalog, err := database.LoadTopicCatalog(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load topics: %w", err)
+	}
+	coSolveRows, err := database.GetCoSolves(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load co-solves: %w", err)
+	}
+
+	questions := make([]*Question, len(rows))
+	for i, q := range rows {
+		questions[i] = &Question{
+			ID:         q.ID,
+			Rating:     q.Rating,
+			Topics:     TopicFeatures(catalog, q.Topics),
+			SolveCount: q.SolveCount,
+		}
+	}
+	coSolves := make(map[[2]int]int, len(coSolveRows))
+	for _, c := range coSolveRows {
+		coSolves[[2]int{c.QuestionA, c.QuestionB}] = c.Users
+	}
+
+	ranked := Rank(questions, coSolves, MaxSimilar)
+	var similarities []database.QuestionSimilarity
+	for id, list := range ranked {
+		for i, m := range list {
+			similarities = append(similarities, database.QuestionSimilarity{
+				QuestionID: id,
+				SimilarID:  m.ID,
+				Rank:       i + 1,
+				Score:      m.Score,
+			})
+		}
+	}
+
+	if err := database.ReplaceQuestionSimilarities(db, similarities); err != nil {
+		return 0, fmt.Errorf("failed to store similar questions: %w", err)
+	}
+
+	return len(ranked), nil
+}
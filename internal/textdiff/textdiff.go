@@ -0,0 +1,186 @@
+// Package textdiff computes line-based diffs between two texts.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCells bounds the size of the LCS table. Larger inputs are diffed as a
+// whole-text replacement rather than line by line.
+const maxCells = 4 << 20
+
+// Op is the kind of a diff line
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+// Line is one line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines returns the line-by-line edit script turning a into b
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	// Trim the common prefix and suffix; edits are usually local
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for _, s := range x[:prefix] {
+		lines = append(lines, Line{Equal, s})
+	}
+	lines = append(lines, middle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, s := range x[len(x)-suffix:] {
+		lines = append(lines, Line{Equal, s})
+	}
+	return lines
+}
+
+// middle diffs the differing part of two texts using a longest common subsequence table
+func middle(x, y []string) []Line {
+	var lines []Line
+	if (len(x)+1)*(len(y)+1) > maxCells {
+		for _, s := range x {
+			lines = append(lines, Line{Delete, s})
+		}
+		for _, s := range y {
+			lines = append(lines, Line{Insert, s})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Delete, x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Insert, y[j]})
+	}
+	return lines
+}
+
+// Unified returns a unified diff of a and b with the given number of context
+// lines around each change, or "" when they are equal
+func Unified(a, b string, context int) string {
+	lines := Lines(a, b)
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		// Find the next change
+		first := start
+		for first < len(lines) && lines[first].Op == Equal {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		last := first
+		for k := first; k < len(lines); k++ {
+			if lines[k].Op != Equal {
+				last = k
+			} else if k-last > 2*context {
+				break
+			}
+		}
+
+		from := max(first-context, start)
+		to := min(last+context+1, len(lines))
+		writeHunk(&out, lines, from, to)
+		start = to
+	}
+	return out.String()
+}
+
+// writeHunk writes lines[from:to] with a header giving their 1-based line ranges
+func writeHunk(out *strings.Builder, lines []Line, from, to int) {
+	aLine, bLine := 1, 1
+	for _, l := range lines[:from] {
+		if l.Op != Insert {
+			aLine++
+		}
+		if l.Op != Delete {
+			bLine++
+		}
+	}
+	aCount, bCount := 0, 0
+	for _, l := range lines[from:to] {
+		if l.Op != Insert {
+			aCount++
+		}
+		if l.Op != Delete {
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, l := range lines[from:to] {
+		out.WriteByte(byte(l.Op))
+		out.WriteString(l.Text)
+		out.WriteByte('\n')
+	}
+}
+
+// Stats returns the number of inserted and deleted lines between a and b
+func Stats(a, b string) (inserted, deleted int) {
+	for _, l := range Lines(a, b) {
+		switch l.Op {
+		case Insert:
+			inserted++
+		case Delete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
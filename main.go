@@ -5,13 +5,20 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"codestandoff/backend/app/controllers"
 	"codestandoff/backend/app/workflow"
 	"codestandoff/backend/graph"
 	"codestandoff/backend/internal/auth"
 	"codestandoff/backend/internal/database"
+	"codestandoff/backend/internal/grader"
+	"codestandoff/backend/internal/jobs"
+	"codestandoff/backend/internal/judge"
 	"codestandoff/backend/internal/oauth"
+	"codestandoff/backend/internal/rating"
+	"codestandoff/backend/internal/similar"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
@@ -34,6 +41,52 @@ func main() {
 	defer db.Close()
 	log.Println("Database connection established")
 
+	// Start background jobs
+	go jobs.Every(context.Background(), "problem ratings", jobs.Interval("PROBLEM_RATING_INTERVAL", time.Hour), func(ctx context.Context) error {
+		n, err := rating.RecomputeProblemRatings(db)
+		if err == nil && n > 0 {
+			log.Printf("Updated %d problem ratings", n)
+		}
+		return err
+	})
+	go jobs.Every(context.Background(), "similar questions", jobs.Interval("SIMILAR_QUESTIONS_INTERVAL", time.Hour), func(ctx context.Context) error {
+		n, err := similar.RecomputeSimilarQuestions(db)
+		if err == nil {
+			log.Printf("Computed similar questions for %d questions", n)
+		}
+		return err
+	})
+
+	// Load the languages submissions are judged in
+	languages, err := judge.LoadRegistry(os.Getenv("LANGUAGES_CONFIG"))
+	if err != nil {
+		log.Fatalf("Failed to load languages: %v", err)
+	}
+
+	// Start judging submissions and running code. Servers that cannot sandbox
+	// programs, or run with JUDGE_WORKERS=0, leave submissions to other
+	// servers; with RUN_CODE_CONCURRENCY=0 they do not run code.
+	var runner *grader.Runner
+	config, runConfig := grader.ConfigFromEnv(), grader.RunConfigFromEnv()
+	if config.Workers > 0 || runConfig.Concurrency > 0 {
+		judgeDir := os.Getenv("JUDGE_DIR")
+		if judgeDir == "" {
+			judgeDir = filepath.Join(os.TempDir(), "codestandoff-judge")
+		}
+		localJudge, err := judge.NewLocal(judgeDir, os.Getenv("JUDGE_CGROUP"))
+		if err != nil {
+			log.Printf("Judge unavailable, submissions stay queued: %v", err)
+		} else {
+			if config.Workers > 0 {
+				g := &grader.Grader{DB: db, Judge: localJudge, Languages: languages}
+				go grader.NewPool(g, config).Run(context.Background())
+			}
+			if runConfig.Concurrency > 0 {
+				runner = grader.NewRunner(localJudge, languages, runConfig)
+			}
+		}
+	}
+
 	// Initialize OAuth providers
 	auth.InitGoogleOAuth()
 	auth.InitGitHubOAuth()
@@ -43,7 +96,9 @@ func main() {
 
 	// Initialize controller
 	controller := controllers.NewPCDGraphQLController(controllers.PCDGraphQLControllerDeps{
-		DB: db,
+		DB:        db,
+		Languages: languages,
+		Runner:    runner,
 	})
 
 	// Initialize workflow
@@ -0,0 +1,22 @@
+-- Competitive (1v1) problems share the public.questions catalog with training.
+-- A problems row marks a question as playable in matches and holds the
+-- settings only matches need; title, description and difficulty stay on the
+-- question so both sides always show the same content.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS author_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_questions_slug ON public.questions(slug);
+
+CREATE TABLE IF NOT EXISTS public.problems (
+    question_id     INTEGER PRIMARY KEY REFERENCES public.questions(id) ON DELETE CASCADE,
+    time_limit_ms   INTEGER NOT NULL DEFAULT 2000 CHECK (time_limit_ms > 0),
+    memory_limit_mb INTEGER NOT NULL DEFAULT 256 CHECK (memory_limit_mb > 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Every existing training question is playable in 1v1 by default.
+INSERT INTO public.problems (question_id)
+SELECT id FROM public.questions
+ON CONFLICT (question_id) DO NOTHING;
@@ -0,0 +1,18 @@
+-- Test cases for questions. questions.test_case_count is kept in sync by the
+-- backend whenever cases are added or removed.
+
+CREATE TABLE IF NOT EXISTS public.test_cases (
+    id              BIGSERIAL PRIMARY KEY,
+    question_id     INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL CHECK (position > 0),
+    input           TEXT NOT NULL,
+    expected_output TEXT NOT NULL,
+    is_sample       BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Per-case overrides; NULL falls back to the problem's limits.
+    time_limit_ms   INTEGER CHECK (time_limit_ms > 0),
+    memory_limit_mb INTEGER CHECK (memory_limit_mb > 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Deferred so a reorder can shuffle positions inside one transaction.
+    CONSTRAINT test_cases_question_position_key UNIQUE (question_id, position) DEFERRABLE INITIALLY DEFERRED
+);
@@ -0,0 +1,16 @@
+-- Detail fields for the question page: constraints, hints, example
+-- explanations and per-language starter code.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS constraints TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS hints TEXT[] NOT NULL DEFAULT '{}';
+
+-- Sample test cases double as the examples shown in the statement.
+ALTER TABLE public.test_cases ADD COLUMN IF NOT EXISTS explanation TEXT;
+
+CREATE TABLE IF NOT EXISTS public.question_starter_code (
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    language    TEXT NOT NULL,
+    code        TEXT NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (question_id, language)
+);
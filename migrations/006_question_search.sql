@@ -0,0 +1,25 @@
+-- Full-text search over questions. The search vector weights title matches
+-- above topic matches above description matches, and is maintained by a
+-- trigger so every writer keeps it current.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION public.questions_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', array_to_string(COALESCE(NEW.topics, '{}'), ' ')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS questions_search_vector_trigger ON public.questions;
+CREATE TRIGGER questions_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description, topics ON public.questions
+    FOR EACH ROW EXECUTE FUNCTION public.questions_search_vector_update();
+
+-- Backfill existing rows through the trigger.
+UPDATE public.questions SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_questions_search_vector ON public.questions USING GIN (search_vector);
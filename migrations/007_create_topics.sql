@@ -0,0 +1,37 @@
+-- Managed topic catalog. questions.topics keeps its spellings; filters resolve
+-- them through topic slugs, names and aliases (all compared lower-case).
+
+CREATE TABLE IF NOT EXISTS public.topics (
+    id         SERIAL PRIMARY KEY,
+    slug       TEXT NOT NULL UNIQUE,
+    name       TEXT NOT NULL,
+    parent_id  INTEGER REFERENCES public.topics(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE TABLE IF NOT EXISTS public.topic_aliases (
+    alias    TEXT PRIMARY KEY CHECK (alias = lower(alias)),
+    topic_id INTEGER NOT NULL REFERENCES public.topics(id) ON DELETE CASCADE
+);
+
+-- Seed the catalog from the free-form topics already on questions.
+INSERT INTO public.topics (slug, name)
+SELECT DISTINCT ON (slug) slug, name
+FROM (
+    SELECT trim(both '-' FROM regexp_replace(lower(t.name), '[^a-z0-9]+', '-', 'g')) AS slug, t.name
+    FROM public.questions q
+    CROSS JOIN LATERAL unnest(q.topics) AS t(name)
+) seeded
+WHERE slug <> ''
+ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+-- Other spellings of a seeded topic become aliases.
+INSERT INTO public.topic_aliases (alias, topic_id)
+SELECT DISTINCT lower(t.name), tp.id
+FROM public.questions q
+CROSS JOIN LATERAL unnest(q.topics) AS t(name)
+JOIN public.topics tp ON tp.slug = trim(both '-' FROM regexp_replace(lower(t.name), '[^a-z0-9]+', '-', 'g'))
+WHERE lower(t.name) <> tp.slug AND lower(t.name) <> lower(tp.name)
+ON CONFLICT (alias) DO NOTHING;
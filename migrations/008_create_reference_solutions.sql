@@ -0,0 +1,9 @@
+-- Reference solutions shipped with problem bundles, one per language.
+
+CREATE TABLE IF NOT EXISTS public.reference_solutions (
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    language    TEXT NOT NULL,
+    code        TEXT NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (question_id, language)
+);
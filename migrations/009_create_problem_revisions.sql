@@ -0,0 +1,58 @@
+-- Immutable snapshots of a question's statement, limits and test cases.
+-- A new revision is recorded whenever one of them changes; matches (and
+-- later submissions) pin the revision they were played against.
+
+CREATE TABLE IF NOT EXISTS public.problem_revisions (
+    id              BIGSERIAL PRIMARY KEY,
+    question_id     INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    revision        INTEGER NOT NULL,
+    title           TEXT NOT NULL,
+    description     TEXT NOT NULL,
+    difficulty      TEXT NOT NULL,
+    constraints     TEXT[] NOT NULL DEFAULT '{}',
+    hints           TEXT[] NOT NULL DEFAULT '{}',
+    time_limit_ms   INTEGER,
+    memory_limit_mb INTEGER,
+    -- [{input, expectedOutput, isSample, timeLimitMs, memoryLimitMb, explanation}] in position order
+    test_cases      JSONB NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (question_id, revision)
+);
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS current_revision_id BIGINT REFERENCES public.problem_revisions(id);
+
+-- Every existing question starts at revision 1.
+INSERT INTO public.problem_revisions (question_id, revision, title, description, difficulty, constraints, hints, time_limit_ms, memory_limit_mb, test_cases)
+SELECT q.id, 1, q.title, q.description, q.difficulty, q.constraints, q.hints, p.time_limit_ms, p.memory_limit_mb,
+    COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'input', tc.input,
+            'expectedOutput', tc.expected_output,
+            'isSample', tc.is_sample,
+            'timeLimitMs', tc.time_limit_ms,
+            'memoryLimitMb', tc.memory_limit_mb,
+            'explanation', tc.explanation
+        ) ORDER BY tc.position)
+        FROM public.test_cases tc
+        WHERE tc.question_id = q.id
+    ), '[]')
+FROM public.questions q
+LEFT JOIN public.problems p ON p.question_id = q.id
+WHERE NOT EXISTS (SELECT 1 FROM public.problem_revisions r WHERE r.question_id = q.id);
+
+UPDATE public.questions q
+SET current_revision_id = r.id
+FROM public.problem_revisions r
+WHERE r.question_id = q.id AND r.revision = 1 AND q.current_revision_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS public.matches (
+    id          UUID PRIMARY KEY,
+    player1_id  UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    player2_id  UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    status      TEXT NOT NULL DEFAULT 'waiting',
+    question_id INTEGER NOT NULL REFERENCES public.questions(id),
+    revision_id BIGINT NOT NULL REFERENCES public.problem_revisions(id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS matches_created_at_idx ON public.matches (created_at DESC);
@@ -0,0 +1,39 @@
+-- Authoring workflow: problems move draft -> in_review -> published -> archived.
+-- Existing questions were already live, so they start out published; new ones
+-- start as drafts.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+ALTER TABLE public.questions ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+UPDATE public.questions SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS questions_status_idx ON public.questions (status);
+
+-- Roles gate who may author, review and administer problems. Grant the first
+-- admin by hand: UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'author', 'reviewer', 'admin'));
+
+CREATE TABLE IF NOT EXISTS public.problem_reviewers (
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- NULL until the reviewer decides; reset when the problem is resubmitted
+    decision    TEXT CHECK (decision IN ('approved', 'changes_requested')),
+    decided_at  TIMESTAMPTZ,
+    PRIMARY KEY (question_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS problem_reviewers_reviewer_idx ON public.problem_reviewers (reviewer_id);
+
+CREATE TABLE IF NOT EXISTS public.problem_review_comments (
+    id          BIGSERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    author_id   UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    body        TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS problem_review_comments_question_idx ON public.problem_review_comments (question_id, created_at);
@@ -0,0 +1,34 @@
+-- Editorials explain a question's solution. They stay locked for a user until
+-- the user solves the question or gives up on it, and each unlock is recorded.
+
+CREATE TABLE IF NOT EXISTS public.editorials (
+    question_id      INTEGER PRIMARY KEY REFERENCES public.questions(id) ON DELETE CASCADE,
+    body             TEXT NOT NULL,
+    time_complexity  TEXT,
+    space_complexity TEXT,
+    updated_by       UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Per-user progress on a question.
+CREATE TABLE IF NOT EXISTS public.question_attempts (
+    user_id     UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    solved_at   TIMESTAMPTZ,
+    -- Only set when the user gave up before solving the question
+    gave_up_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS question_attempts_question_idx ON public.question_attempts (question_id);
+
+CREATE TABLE IF NOT EXISTS public.editorial_unlocks (
+    user_id     UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    reason      TEXT NOT NULL CHECK (reason IN ('solved', 'gave_up')),
+    unlocked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, question_id)
+);
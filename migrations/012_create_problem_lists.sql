@@ -0,0 +1,46 @@
+-- Curated problem lists and study plans: ordered sections of questions with an
+-- owner and a public/private flag, which users can follow.
+
+CREATE TABLE IF NOT EXISTS public.problem_lists (
+    id          SERIAL PRIMARY KEY,
+    slug        TEXT NOT NULL UNIQUE,
+    title       TEXT NOT NULL,
+    description TEXT,
+    owner_id    UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    is_public   BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS problem_lists_owner_idx ON public.problem_lists (owner_id);
+
+CREATE TABLE IF NOT EXISTS public.problem_list_sections (
+    id          SERIAL PRIMARY KEY,
+    list_id     INTEGER NOT NULL REFERENCES public.problem_lists(id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT,
+    position    INTEGER NOT NULL,
+    UNIQUE (list_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS public.problem_list_items (
+    list_id     INTEGER NOT NULL REFERENCES public.problem_lists(id) ON DELETE CASCADE,
+    section_id  INTEGER NOT NULL REFERENCES public.problem_list_sections(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    PRIMARY KEY (list_id, question_id),
+    UNIQUE (section_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS problem_list_items_question_idx ON public.problem_list_items (question_id);
+
+-- completed_at is set once the follower has solved every question on the list.
+CREATE TABLE IF NOT EXISTS public.problem_list_follows (
+    user_id      UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    list_id      INTEGER NOT NULL REFERENCES public.problem_lists(id) ON DELETE CASCADE,
+    followed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, list_id)
+);
+
+CREATE INDEX IF NOT EXISTS problem_list_follows_list_idx ON public.problem_list_follows (list_id);
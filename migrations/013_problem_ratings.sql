@@ -0,0 +1,18 @@
+-- Numeric problem ratings on the same scale as user ratings, estimated from
+-- solve outcomes and recomputed periodically. Until a question has outcomes
+-- its rating comes from its difficulty.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS rating INTEGER NOT NULL DEFAULT 1400;
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS rating_samples INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS rating_updated_at TIMESTAMPTZ;
+
+UPDATE public.questions SET rating = CASE difficulty WHEN 'Easy' THEN 800 WHEN 'Hard' THEN 2000 ELSE 1400 END
+WHERE rating_samples = 0;
+
+CREATE INDEX IF NOT EXISTS questions_rating_idx ON public.questions (rating, id);
+
+-- The user's rating when they first attempted the question, so later rating
+-- changes do not skew the estimate.
+ALTER TABLE public.question_attempts ADD COLUMN IF NOT EXISTS user_rating INTEGER;
+UPDATE public.question_attempts a SET user_rating = COALESCE(u.rating, 0)
+FROM public.users u WHERE u.id = a.user_id AND a.user_rating IS NULL;
@@ -0,0 +1,12 @@
+-- A question can declare its function signature once; starter code and judge
+-- harnesses for every supported language are generated from it.
+
+-- {"function": "...", "params": [{"name": "...", "type": "..."}], "returns": "..."}
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS signature JSONB;
+
+-- Generated starter code is regenerated when the signature changes; code an
+-- author wrote by hand is never overwritten.
+ALTER TABLE public.question_starter_code ADD COLUMN IF NOT EXISTS generated BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Submissions are judged with the harness of the signature they were pinned to.
+ALTER TABLE public.problem_revisions ADD COLUMN IF NOT EXISTS signature JSONB;
@@ -0,0 +1,6 @@
+-- Statements are rendered from markdown to sanitized HTML on first read and
+-- cached on the revision they belong to. The renderer version is stored with
+-- the HTML so a renderer change re-renders old revisions lazily.
+
+ALTER TABLE public.problem_revisions ADD COLUMN IF NOT EXISTS description_html TEXT;
+ALTER TABLE public.problem_revisions ADD COLUMN IF NOT EXISTS description_html_version INTEGER;
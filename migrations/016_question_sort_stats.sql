@@ -0,0 +1,48 @@
+-- Per-question attempt and solve counts for sorting by popularity and
+-- acceptance rate. They are maintained by a trigger on question_attempts so
+-- every writer keeps them current.
+
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS solve_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS acceptance_rate DOUBLE PRECISION
+    GENERATED ALWAYS AS (CASE WHEN attempt_count = 0 THEN 0 ELSE solve_count::double precision / attempt_count END) STORED;
+
+CREATE OR REPLACE FUNCTION public.question_attempts_count_update() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE public.questions
+        SET attempt_count = attempt_count - 1,
+            solve_count = solve_count - CASE WHEN OLD.solved_at IS NULL THEN 0 ELSE 1 END
+        WHERE id = OLD.question_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE public.questions
+        SET attempt_count = attempt_count + 1,
+            solve_count = solve_count + CASE WHEN NEW.solved_at IS NULL THEN 0 ELSE 1 END
+        WHERE id = NEW.question_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS question_attempts_count_trigger ON public.question_attempts;
+CREATE TRIGGER question_attempts_count_trigger
+    AFTER INSERT OR DELETE OR UPDATE OF question_id, solved_at ON public.question_attempts
+    FOR EACH ROW EXECUTE FUNCTION public.question_attempts_count_update();
+
+-- Backfill existing counts.
+UPDATE public.questions q
+SET attempt_count = a.attempts, solve_count = a.solves
+FROM (
+    SELECT question_id, COUNT(*) AS attempts, COUNT(solved_at) AS solves
+    FROM public.question_attempts
+    GROUP BY question_id
+) a
+WHERE a.question_id = q.id;
+
+-- Difficulty sorts Easy, Medium, Hard rather than alphabetically.
+CREATE INDEX IF NOT EXISTS questions_difficulty_rank_idx ON public.questions
+    ((CASE difficulty WHEN 'Easy' THEN 1 WHEN 'Medium' THEN 2 WHEN 'Hard' THEN 3 ELSE 4 END), id);
+CREATE INDEX IF NOT EXISTS questions_acceptance_rate_idx ON public.questions (acceptance_rate, id);
+CREATE INDEX IF NOT EXISTS questions_attempt_count_idx ON public.questions (attempt_count, id);
+CREATE INDEX IF NOT EXISTS questions_newest_idx ON public.questions ((COALESCE(published_at, created_at)), id);
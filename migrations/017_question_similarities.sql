@@ -0,0 +1,14 @@
+-- Precomputed "similar questions" recommendations, scored from topic overlap,
+-- rating proximity and how often the same users solve both questions. A
+-- background job replaces the whole table on every run.
+
+CREATE TABLE IF NOT EXISTS public.question_similarities (
+    question_id INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    similar_id  INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    -- 1 is the most similar
+    rank        INTEGER NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (question_id, similar_id),
+    UNIQUE (question_id, rank)
+);
@@ -0,0 +1,31 @@
+-- Code submissions. Each submission pins the problem revision it is judged
+-- against and moves through queued -> compiling -> running -> verdict; a
+-- verdict is final. Submissions made during a match also record the match.
+
+CREATE TABLE IF NOT EXISTS public.submissions (
+    id           UUID PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    question_id  INTEGER NOT NULL REFERENCES public.questions(id) ON DELETE CASCADE,
+    revision_id  BIGINT NOT NULL REFERENCES public.problem_revisions(id),
+    match_id     UUID REFERENCES public.matches(id) ON DELETE SET NULL,
+    language     TEXT NOT NULL,
+    source       TEXT NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'queued' CHECK (status IN (
+        'queued', 'compiling', 'running',
+        'accepted', 'wrong_answer', 'time_limit_exceeded', 'memory_limit_exceeded', 'runtime_error', 'compile_error'
+    )),
+    -- Compiler output for compile errors, error output for runtime errors
+    message      TEXT,
+    tests_passed INTEGER,
+    tests_total  INTEGER,
+    -- Peak time and memory over all test cases
+    time_ms      INTEGER,
+    memory_kb    INTEGER,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at   TIMESTAMPTZ,
+    finished_at  TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS submissions_user_question_idx ON public.submissions (user_id, question_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS submissions_match_idx ON public.submissions (match_id) WHERE match_id IS NOT NULL;
@@ -0,0 +1,40 @@
+-- Durable queue of submissions waiting to be judged. Workers claim jobs with
+-- FOR UPDATE SKIP LOCKED, so any number of them, on any number of servers,
+-- can poll without blocking each other. A worker heartbeats the job it is
+-- running; a job whose heartbeat goes stale belonged to a crashed worker and
+-- is queued again.
+
+CREATE TABLE IF NOT EXISTS public.judge_jobs (
+    id            BIGSERIAL PRIMARY KEY,
+    submission_id UUID NOT NULL UNIQUE REFERENCES public.submissions(id) ON DELETE CASCADE,
+    user_id       UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    status        TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    -- A queued job is not claimed before run_at, which backs off after failed attempts
+    run_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    worker        TEXT,
+    heartbeat_at  TIMESTAMPTZ,
+    last_error    TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at    TIMESTAMPTZ,
+    finished_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS judge_jobs_queued_idx ON public.judge_jobs (run_at, id) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS judge_jobs_running_idx ON public.judge_jobs (user_id) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS judge_jobs_finished_at_idx ON public.judge_jobs (finished_at) WHERE finished_at IS NOT NULL;
+
+-- A submission whose judging keeps failing ends with an internal error
+-- instead of staying pending forever
+ALTER TABLE public.submissions DROP CONSTRAINT IF EXISTS submissions_status_check;
+ALTER TABLE public.submissions ADD CONSTRAINT submissions_status_check CHECK (status IN (
+    'queued', 'compiling', 'running',
+    'accepted', 'wrong_answer', 'time_limit_exceeded', 'memory_limit_exceeded', 'runtime_error', 'compile_error',
+    'internal_error'
+));
+
+-- Submissions made before the queue existed are judged from the start
+UPDATE public.submissions SET status = 'queued', updated_at = NOW() WHERE status IN ('compiling', 'running');
+INSERT INTO public.judge_jobs (submission_id, user_id, created_at)
+SELECT id, user_id, created_at FROM public.submissions WHERE status = 'queued'
+ON CONFLICT (submission_id) DO NOTHING;
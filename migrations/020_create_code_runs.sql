@@ -0,0 +1,12 @@
+-- Runs of code against custom input or sample tests, which are not
+-- submissions and have no verdict. Only when each user ran code is kept, to
+-- rate limit runCode across servers; rows older than the rate limit window
+-- are deleted as the user runs code again.
+
+CREATE TABLE IF NOT EXISTS public.code_runs (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS code_runs_user_created_at_idx ON public.code_runs (user_id, created_at);
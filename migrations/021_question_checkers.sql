@@ -0,0 +1,8 @@
+-- A question can pick how outputs are checked: exact lines (the default, stored
+-- as NULL), tokens, float tolerance, unordered lines or a checker program.
+
+-- {"kind": "float", "tolerance": 1e-6} or {"kind": "program", "language": "...", "source": "..."}
+ALTER TABLE public.questions ADD COLUMN IF NOT EXISTS checker JSONB;
+
+-- Submissions are judged with the checker of the revision they were pinned to.
+ALTER TABLE public.problem_revisions ADD COLUMN IF NOT EXISTS checker JSONB;
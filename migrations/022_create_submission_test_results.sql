@@ -0,0 +1,19 @@
+-- How each judged submission did on each test case it ran. Test cases after
+-- the first failing one are not run. Messages and diffs are only kept for
+-- sample test cases, whose contents users can see anyway.
+
+CREATE TABLE IF NOT EXISTS public.submission_test_results (
+    submission_id UUID NOT NULL REFERENCES public.submissions(id) ON DELETE CASCADE,
+    -- 1-based position of the test case in the submission's revision
+    position      INTEGER NOT NULL,
+    is_sample     BOOLEAN NOT NULL,
+    status        TEXT NOT NULL CHECK (status IN ('accepted', 'wrong_answer', 'time_limit_exceeded', 'memory_limit_exceeded', 'runtime_error')),
+    time_ms       INTEGER NOT NULL,
+    wall_time_ms  INTEGER NOT NULL,
+    memory_kb     INTEGER NOT NULL,
+    -- The checker's message, or error output of a runtime error
+    message       TEXT,
+    -- Unified diff from the expected output to the output of a wrong answer
+    diff          TEXT,
+    PRIMARY KEY (submission_id, position)
+);